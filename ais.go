@@ -0,0 +1,96 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jedib0t/go-pretty/table"
+
+	"github.com/notJoon/drbg/ais31"
+	stream "github.com/notJoon/drbg/bitstream"
+)
+
+// aisTests implements the ais31 subcommand: it runs the test procedures A and B of AIS 20/31 on
+// the bits of a file or a generator, procedure B reading the bits after those of procedure A.
+func aisTests(args []string) {
+	flags := flag.NewFlagSet("ais31", flag.ExitOnError)
+	in := addInputFlags(flags, 0)
+	procedure := flags.String("procedure", "ab", "Procedures to run: a, b or ab")
+	flags.Parse(args)
+
+	var procedures []func(stream.BitSource) (*ais31.ProcedureResult, error)
+	switch *procedure {
+	case "a":
+		procedures = append(procedures, ais31.ProcedureA)
+	case "b":
+		procedures = append(procedures, ais31.ProcedureB)
+	case "ab":
+		procedures = append(procedures, ais31.ProcedureA, ais31.ProcedureB)
+	default:
+		fmt.Printf("Error: unknown procedure %q (expected a, b or ab)\n", *procedure)
+		os.Exit(1)
+	}
+
+	src, source, err := in.source()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(source)
+	for _, run := range procedures {
+		res, err := run(src)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		writeProcedureResult(os.Stdout, res)
+	}
+}
+
+// writeProcedureResult renders the basic tests of every attempt of a procedure. The tests run on
+// each block of procedure A are summed up in one row, followed by the blocks that failed.
+func writeProcedureResult(w io.Writer, res *ais31.ProcedureResult) {
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.AppendHeader(table.Row{"AIS 31 Test", "Attempt", "Statistic", "Criterion", "Result"})
+
+	for i, a := range res.Attempts {
+		attempt := i + 1
+
+		// the results of each test, in the order of their first result
+		var ids []string
+		byTest := map[string][]*ais31.Result{}
+		for _, r := range a.Results {
+			if _, ok := byTest[r.Test]; !ok {
+				ids = append(ids, r.Test)
+			}
+			byTest[r.Test] = append(byTest[r.Test], r)
+		}
+
+		for _, id := range ids {
+			results := byTest[id]
+			r := results[0]
+			name := r.Test + " " + r.Name
+			if r.Block < 0 {
+				t.AppendRow(table.Row{name, attempt, fmt.Sprintf("%.6g", r.Statistic), r.Criterion, passFail(r.Pass)})
+				continue
+			}
+
+			var failed []*ais31.Result
+			for _, r := range results {
+				if !r.Pass {
+					failed = append(failed, r)
+				}
+			}
+			passed := fmt.Sprintf("%d/%d blocks", len(results)-len(failed), len(results))
+			t.AppendRow(table.Row{name, attempt, passed, "in every block", passFail(len(failed) == 0)})
+			for _, f := range failed {
+				t.AppendRow(table.Row{fmt.Sprintf("  block %d", f.Block), attempt, fmt.Sprintf("%.6g", f.Statistic), f.Criterion, passFail(false)})
+			}
+		}
+	}
+	t.AppendFooter(table.Row{res.Procedure, len(res.Attempts), fmt.Sprintf("%d bits", res.Bits()), "", passFail(res.Pass)})
+	t.Render()
+}
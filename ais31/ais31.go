@@ -0,0 +1,126 @@
+// Package ais31 implements the statistical test procedures A and B of the BSI AIS 20/31
+// evaluation methodology for physical random number generators.
+//
+// Procedure A runs the disjointness test T0 once, then the tests T1 to T5 on 257 consecutive
+// blocks of 20,000 bits. Procedure B runs the uniform distribution test T6, the homogeneity
+// test T7 and Coron's entropy test T8. Each procedure reads the bits it needs from a
+// bitstream.BitSource, and is repeated once on new bits when exactly one of its basic tests
+// fails.
+package ais31
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	b "github.com/notJoon/drbg/bitstream"
+)
+
+var ErrNotEnoughBits = b.ErrNotEnoughBits
+
+// Result is the outcome of a basic test.
+type Result struct {
+	Test      string  // the id of the test, "T0" to "T8"
+	Name      string  // e.g. "Monobit Test"
+	Block     int     // the index of the 20,000-bit block of T1 to T5, from 0; -1 for the other tests
+	Statistic float64 // the test statistic
+	Criterion string  // the condition of the statistic for the test to pass
+	Pass      bool
+}
+
+// Attempt is one run of a procedure.
+type Attempt struct {
+	Results []*Result
+	Bits    int // the number of bits consumed
+}
+
+// Failures returns the results of the basic tests that failed.
+func (a *Attempt) Failures() []*Result {
+	var failed []*Result
+	for _, r := range a.Results {
+		if !r.Pass {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
+
+// ProcedureResult is the outcome of a procedure: it passes when every basic test of the first
+// attempt passes, or when exactly one fails and every basic test of the second attempt passes.
+type ProcedureResult struct {
+	Procedure string // "Procedure A" or "Procedure B"
+	Attempts  []*Attempt
+	Pass      bool
+}
+
+// Bits returns the number of bits consumed by every attempt.
+func (r *ProcedureResult) Bits() int {
+	n := 0
+	for _, a := range r.Attempts {
+		n += a.Bits
+	}
+	return n
+}
+
+// runProcedure runs the attempts of a procedure following the one-retry rule.
+func runProcedure(name string, src b.BitSource, attempt func(*reader) ([]*Result, error)) (*ProcedureResult, error) {
+	res := &ProcedureResult{Procedure: name}
+	for i := 0; i < 2; i++ {
+		r := &reader{src: src}
+		results, err := attempt(r)
+		if err != nil {
+			if i == 1 {
+				return nil, fmt.Errorf("%s, repetition after one failed test: %w", name, err)
+			}
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		a := &Attempt{Results: results, Bits: r.n}
+		res.Attempts = append(res.Attempts, a)
+
+		failures := len(a.Failures())
+		if failures == 0 {
+			res.Pass = true
+			return res, nil
+		}
+		if failures > 1 || i == 1 {
+			return res, nil
+		}
+	}
+	return res, nil
+}
+
+// reader reads the bits of a procedure from a BitSource, and counts them.
+type reader struct {
+	src b.BitSource
+	n   int
+}
+
+// bits reads the next n bits, one per byte.
+func (r *reader) bits(n int) ([]byte, error) {
+	out := make([]byte, n)
+	for i := range out {
+		bit, err := r.src.ReadBit()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: the input ended after %d bits", ErrNotEnoughBits, r.n)
+		}
+		if err != nil {
+			return nil, err
+		}
+		out[i] = bit
+		r.n++
+	}
+	return out, nil
+}
+
+// word reads the next k bits as an integer, most significant bit first.
+func (r *reader) word(k int) (int, error) {
+	bits, err := r.bits(k)
+	if err != nil {
+		return 0, err
+	}
+	w := 0
+	for _, bit := range bits {
+		w = w<<1 | int(bit)
+	}
+	return w, nil
+}
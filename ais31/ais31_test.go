@@ -0,0 +1,159 @@
+package ais31
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	b "github.com/notJoon/drbg/bitstream"
+)
+
+func randomBits(bytes int, seed int64) *b.BitStream {
+	data := make([]byte, bytes)
+	rand.New(rand.NewSource(seed)).Read(data)
+	return b.NewBitStream(data)
+}
+
+// addLongRun writes a run of 40 ones in the given block of the attempt of procedure A starting at
+// the bit offset, which fails T4 only.
+func addLongRun(bs *b.BitStream, offset, block int) {
+	start := offset + DisjointnessWords*48 + block*BlockBits + 1000
+	for i := start; i < start+40; i++ {
+		bs.SetBit(i, 1)
+	}
+}
+
+func TestProcedureA(t *testing.T) {
+	tests := []struct {
+		name     string
+		corrupt  func(bs *b.BitStream)
+		attempts int
+		pass     bool
+	}{
+		{"Random", func(*b.BitStream) {}, 1, true},
+		{"One failure, then a pass", func(bs *b.BitStream) { addLongRun(bs, 0, 10) }, 2, true},
+		{"Two failures", func(bs *b.BitStream) { addLongRun(bs, 0, 10); addLongRun(bs, 0, 20) }, 1, false},
+		{"One failure twice", func(bs *b.BitStream) { addLongRun(bs, 0, 10); addLongRun(bs, ProcedureABits, 5) }, 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bs := randomBits(2*ProcedureABits/8, 1)
+			tt.corrupt(bs)
+			res, err := ProcedureA(b.NewBitStreamReader(bs))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Pass != tt.pass || len(res.Attempts) != tt.attempts {
+				t.Errorf("pass = %v after %d attempts, expected %v after %d", res.Pass, len(res.Attempts), tt.pass, tt.attempts)
+			}
+			if got := res.Bits(); got != tt.attempts*ProcedureABits {
+				t.Errorf("%d bits consumed, expected %d", got, tt.attempts*ProcedureABits)
+			}
+			if tt.attempts == 2 {
+				failed := res.Attempts[0].Failures()
+				if len(failed) != 1 || failed[0].Test != "T4" || failed[0].Block != 10 {
+					t.Errorf("first attempt failures %+v", failed)
+				}
+			}
+		})
+	}
+}
+
+func TestProcedureB(t *testing.T) {
+	res, err := ProcedureB(b.NewBitStreamReader(randomBits(1000000, 2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Pass || len(res.Attempts) != 1 || len(res.Attempts[0].Results) != 5 {
+		t.Errorf("random bits: pass = %v after %d attempts", res.Pass, len(res.Attempts))
+	}
+
+	// a Markov source repeating the previous bit with probability 0.6 is unbiased, and its next
+	// bit only depends on the last one: it fails T6b and the entropy test T8, but not T7
+	rng := rand.New(rand.NewSource(3))
+	bs := b.NewBitStream(nil)
+	bit := byte(0)
+	for i := 0; i < 12000000; i++ {
+		if rng.Float64() >= 0.6 {
+			bit ^= 1
+		}
+		bs.Append(bit)
+	}
+	res, err = ProcedureB(b.NewBitStreamReader(bs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Pass || len(res.Attempts) != 1 {
+		t.Errorf("Markov source: pass = %v after %d attempts", res.Pass, len(res.Attempts))
+	}
+	for _, r := range res.Attempts[0].Results {
+		if r.Pass != (r.Test == "T6a" || r.Test == "T7a" || r.Test == "T7b") {
+			t.Errorf("Markov source: %s pass = %v, statistic %v", r.Test, r.Pass, r.Statistic)
+		}
+	}
+}
+
+func TestNotEnoughBits(t *testing.T) {
+	if _, err := ProcedureA(b.NewBitStreamReader(randomBits(ProcedureABits/8-1, 4))); !errors.Is(err, ErrNotEnoughBits) {
+		t.Errorf("procedure A: error %v, expected ErrNotEnoughBits", err)
+	}
+	if _, err := ProcedureB(b.NewBitStreamReader(randomBits(100000, 4))); !errors.Is(err, ErrNotEnoughBits) {
+		t.Errorf("procedure B: error %v, expected ErrNotEnoughBits", err)
+	}
+}
+
+func TestDisjointness(t *testing.T) {
+	words := []int{5, 1, 1 << 47, 3}
+	if res := Disjointness(words); !res.Pass {
+		t.Errorf("distinct words: %+v", res)
+	}
+	words = append(words, 1<<47)
+	if res := Disjointness(words); res.Pass || res.Statistic != 1 {
+		t.Errorf("repeated word: %+v", res)
+	}
+}
+
+func TestAutocorrelation(t *testing.T) {
+	bs := randomBits(BlockBits/8, 5)
+	block := make([]byte, BlockBits)
+	for i := range block {
+		block[i], _ = bs.Bit(i)
+	}
+	if res := Autocorrelation(block); !res.Pass {
+		t.Errorf("random block: %+v", res)
+	}
+
+	// a block repeating with a period of 1234 bits
+	for i := 1234; i < BlockBits; i++ {
+		block[i] = block[i-1234]
+	}
+	res := Autocorrelation(block)
+	if res.Pass || res.Statistic != 0 || res.Criterion != "2326 < Z < 2674 (τ = 1234)" {
+		t.Errorf("periodic block: %+v", res)
+	}
+}
+
+func TestEntropy(t *testing.T) {
+	rng := rand.New(rand.NewSource(6))
+	words := make([]int, entropyQ+entropyK)
+	for i := range words {
+		words[i] = rng.Intn(256)
+	}
+	if res := Entropy(words); !res.Pass || res.Statistic < 7.98 || res.Statistic > 8.02 {
+		t.Errorf("uniform words: %+v", res)
+	}
+	// 255 values: 7.994 bits per word
+	for i := range words {
+		words[i] = rng.Intn(255)
+	}
+	if res := Entropy(words); !res.Pass {
+		t.Errorf("255 values: %+v", res)
+	}
+	// 240 values: 7.907 bits per word
+	for i := range words {
+		words[i] = rng.Intn(240)
+	}
+	if res := Entropy(words); res.Pass {
+		t.Errorf("240 values: %+v", res)
+	}
+}
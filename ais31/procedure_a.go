@@ -0,0 +1,227 @@
+package ais31
+
+import (
+	"fmt"
+	"math/bits"
+	"slices"
+
+	b "github.com/notJoon/drbg/bitstream"
+)
+
+const (
+	// DisjointnessWords is the number of 48-bit words of the disjointness test T0.
+	DisjointnessWords = 1 << 16
+	// BlockBits is the length of the blocks tested by T1 to T5.
+	BlockBits = 20000
+	// Blocks is the number of blocks of procedure A.
+	Blocks = 257
+	// ProcedureABits is the number of bits consumed by an attempt of procedure A.
+	ProcedureABits = DisjointnessWords*48 + Blocks*BlockBits
+)
+
+// ProcedureA runs procedure A on the bits of src: T0 on 2^16 48-bit words, then T1 to T5 on each
+// of 257 blocks of 20,000 bits. An attempt consumes ProcedureABits bits, and a repetition as
+// many again.
+func ProcedureA(src b.BitSource) (*ProcedureResult, error) {
+	return runProcedure("Procedure A", src, procedureA)
+}
+
+func procedureA(r *reader) ([]*Result, error) {
+	words := make([]int, DisjointnessWords)
+	for i := range words {
+		w, err := r.word(48)
+		if err != nil {
+			return nil, err
+		}
+		words[i] = w
+	}
+	results := []*Result{Disjointness(words)}
+
+	for i := 0; i < Blocks; i++ {
+		block, err := r.bits(BlockBits)
+		if err != nil {
+			return nil, err
+		}
+		for _, test := range blockTests {
+			res := test(block)
+			res.Block = i
+			results = append(results, res)
+		}
+	}
+	return results, nil
+}
+
+// blockTests are the tests run on each block of procedure A.
+var blockTests = []func(block []byte) *Result{Monobit, Poker, Runs, LongRun, Autocorrelation}
+
+// Disjointness is the test T0: it passes when the 48-bit words are pairwise distinct. The
+// statistic is the number of words equal to an earlier one.
+func Disjointness(words []int) *Result {
+	sorted := slices.Clone(words)
+	slices.Sort(sorted)
+	repeated := 0
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i] == sorted[i-1] {
+			repeated++
+		}
+	}
+	return &Result{
+		Test:      "T0",
+		Name:      "Disjointness Test",
+		Block:     -1,
+		Statistic: float64(repeated),
+		Criterion: "no repeated word",
+		Pass:      repeated == 0,
+	}
+}
+
+// Monobit is the test T1: the number of ones X of the block must satisfy 9654 < X < 10346.
+func Monobit(block []byte) *Result {
+	ones := 0
+	for _, bit := range block {
+		ones += int(bit)
+	}
+	return &Result{
+		Test:      "T1",
+		Name:      "Monobit Test",
+		Statistic: float64(ones),
+		Criterion: "9654 < X < 10346",
+		Pass:      9654 < ones && ones < 10346,
+	}
+}
+
+// Poker is the test T2: with f(i) the number of occurrences of the value i among the 5000 4-bit
+// segments of the block, X = 16/5000 Σ f(i)² - 5000 must satisfy 1.03 < X < 57.4.
+func Poker(block []byte) *Result {
+	var f [16]int
+	for i := 0; i+4 <= len(block); i += 4 {
+		f[block[i]<<3|block[i+1]<<2|block[i+2]<<1|block[i+3]]++
+	}
+	sum := 0
+	for _, c := range f {
+		sum += c * c
+	}
+	x := float64(16*sum-5000*5000) / 5000
+	return &Result{
+		Test:      "T2",
+		Name:      "Poker Test",
+		Statistic: x,
+		Criterion: "1.03 < X < 57.4",
+		Pass:      1.03 < x && x < 57.4,
+	}
+}
+
+// runBounds are the inclusive bounds of the number of runs of zeros, and of ones, of lengths
+// 1 to 5 and 6 or more of the test T3.
+var runBounds = [6][2]int{{2267, 2733}, {1079, 1421}, {502, 748}, {223, 402}, {90, 223}, {90, 223}}
+
+// Runs is the test T3: the numbers of runs of zeros and of ones of lengths 1 to 5 and 6 or more
+// of the block must lie in their intervals. The statistic is the number of counts out of their
+// interval.
+func Runs(block []byte) *Result {
+	var counts [2][6]int
+	for i := 0; i < len(block); {
+		j := i + 1
+		for j < len(block) && block[j] == block[i] {
+			j++
+		}
+		counts[block[i]][min(j-i, 6)-1]++
+		i = j
+	}
+	outside := 0
+	for _, c := range counts {
+		for l, n := range c {
+			if n < runBounds[l][0] || n > runBounds[l][1] {
+				outside++
+			}
+		}
+	}
+	return &Result{
+		Test:      "T3",
+		Name:      "Runs Test",
+		Statistic: float64(outside),
+		Criterion: "every run count in its interval",
+		Pass:      outside == 0,
+	}
+}
+
+// LongRun is the test T4: the block must not hold a run of 34 or more identical bits. The
+// statistic is the length of the longest run.
+func LongRun(block []byte) *Result {
+	longest, current := 0, 0
+	for i := range block {
+		if i > 0 && block[i] == block[i-1] {
+			current++
+		} else {
+			current = 1
+		}
+		longest = max(longest, current)
+	}
+	return &Result{
+		Test:      "T4",
+		Name:      "Long Run Test",
+		Statistic: float64(longest),
+		Criterion: "X < 34",
+		Pass:      longest < 34,
+	}
+}
+
+// Autocorrelation is the test T5. On the first 10,000 bits of the block, the shift τ in 1..5000
+// maximizing |Z_τ - 2500|, with Z_τ = Σ_{j=1}^{5000} b_j ⊕ b_{j+τ}, is selected. Z_τ computed
+// on the bits 10,001 to 20,000 must then satisfy 2326 < Z_τ < 2674.
+func Autocorrelation(block []byte) *Result {
+	packed := pack(block)
+	tau, deviation := 0, -1
+	for t := 1; t <= 5000; t++ {
+		if d := abs(xorCount(packed, 0, t, 5000) - 2500); d > deviation {
+			tau, deviation = t, d
+		}
+	}
+	z := xorCount(packed, 10000, tau, 5000)
+	return &Result{
+		Test:      "T5",
+		Name:      "Autocorrelation Test",
+		Statistic: float64(z),
+		Criterion: fmt.Sprintf("2326 < Z < 2674 (τ = %d)", tau),
+		Pass:      2326 < z && z < 2674,
+	}
+}
+
+// pack packs bits into words, the bit i at the position i mod 64 of the word i / 64. A zero word
+// is appended so that any 64 bits of the input can be read by window.
+func pack(bits []byte) []uint64 {
+	packed := make([]uint64, len(bits)/64+2)
+	for i, bit := range bits {
+		packed[i/64] |= uint64(bit) << (i % 64)
+	}
+	return packed
+}
+
+// window returns the 64 bits starting at the bit off.
+func window(packed []uint64, off int) uint64 {
+	w, s := off/64, off%64
+	if s == 0 {
+		return packed[w]
+	}
+	return packed[w]>>s | packed[w+1]<<(64-s)
+}
+
+// xorCount returns Σ_{j=start}^{start+n-1} b_j ⊕ b_{j+tau}.
+func xorCount(packed []uint64, start, tau, n int) int {
+	count := 0
+	for j := 0; j < n; j += 64 {
+		x := window(packed, start+j) ^ window(packed, start+j+tau)
+		if rem := n - j; rem < 64 {
+			x &= 1<<rem - 1
+		}
+		count += bits.OnesCount64(x)
+	}
+	return count
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,206 @@
+package ais31
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+
+	b "github.com/notJoon/drbg/bitstream"
+)
+
+const (
+	// UniformBits is the number of bits of the uniform distribution test T6a.
+	UniformBits = 100000
+	// ClassSize is the number of tuples collected in each class by the steps 2 to 4.
+	ClassSize = 100000
+
+	// the parameters of Coron's entropy test T8: Q words of L bits initialize the table of the
+	// last occurrences, and K words are tested
+	entropyL = 8
+	entropyQ = 2560
+	entropyK = 256000
+
+	// homogeneityCutoff is the 1 - 0.0001 quantile of the χ² distribution with 1 degree of
+	// freedom, the cutoff of T7.
+	homogeneityCutoff = 15.13
+)
+
+// ProcedureB runs procedure B on the bits of src:
+//
+//  1. T6a: the proportion of ones of 100,000 bits must be within 0.025 of 1/2.
+//  2. T6b: disjoint 2-bit tuples are collected until each value of the first bit has been seen
+//     100,000 times; the transition probabilities must satisfy |p(1|0) + p(0|1) - 1| < 0.02.
+//  3. T7a: the same with 3-bit tuples and classes by their first 2 bits; the distribution of the
+//     last bit must be the same in the classes 00 and 10, and in 01 and 11.
+//  4. T7b: the same with 4-bit tuples and classes by their first 3 bits, comparing the classes
+//     that differ in their first bit.
+//  5. T8: Coron's entropy test on 8-bit words must estimate more than 7.976 bits per word.
+//
+// Each step is a basic test of the one-retry rule. The number of bits consumed depends on the
+// bits, about 7,000,000 for an attempt on unbiased bits.
+func ProcedureB(src b.BitSource) (*ProcedureResult, error) {
+	return runProcedure("Procedure B", src, procedureB)
+}
+
+func procedureB(r *reader) ([]*Result, error) {
+	sample, err := r.bits(UniformBits)
+	if err != nil {
+		return nil, err
+	}
+	results := []*Result{Uniform(sample)}
+
+	for k := 2; k <= 4; k++ {
+		classes, err := collect(r, k, ClassSize)
+		if err != nil {
+			return nil, err
+		}
+		if k == 2 {
+			results = append(results, Transition(classes))
+		} else {
+			results = append(results, Homogeneity(classes))
+		}
+	}
+
+	words := make([]int, entropyQ+entropyK)
+	for i := range words {
+		if words[i], err = r.word(entropyL); err != nil {
+			return nil, err
+		}
+	}
+	results = append(results, Entropy(words))
+	return results, nil
+}
+
+// collect reads disjoint k-bit tuples and sorts them into 2^(k-1) classes by their first k-1
+// bits, until every class holds n tuples; tuples of a full class are discarded. It returns, for
+// each class, the number of tuples whose last bit is 0 and 1.
+func collect(r *reader, k, n int) ([][2]int, error) {
+	classes := make([][2]int, 1<<(k-1))
+	full := 0
+	for full < len(classes) {
+		w, err := r.word(k)
+		if err != nil {
+			return nil, err
+		}
+		c := &classes[w>>1]
+		if c[0]+c[1] == n {
+			continue
+		}
+		c[w&1]++
+		if c[0]+c[1] == n {
+			full++
+		}
+	}
+	return classes, nil
+}
+
+// Uniform is the test T6a: the proportion of ones of the bits must be within 0.025 of 1/2.
+func Uniform(bits []byte) *Result {
+	ones := 0
+	for _, bit := range bits {
+		ones += int(bit)
+	}
+	p := float64(ones) / float64(len(bits))
+	return &Result{
+		Test:      "T6a",
+		Name:      "Uniform Distribution Test",
+		Block:     -1,
+		Statistic: p,
+		Criterion: "|X - 0.5| < 0.025",
+		Pass:      math.Abs(p-0.5) < 0.025,
+	}
+}
+
+// Transition is the test T6b on the classes of 2-bit tuples collected by their first bit: the
+// probability of a 1 after a 0 and of a 0 after a 1 must add up to 1 within 0.02.
+func Transition(classes [][2]int) *Result {
+	p01 := float64(classes[0][1]) / float64(classes[0][0]+classes[0][1])
+	p10 := float64(classes[1][0]) / float64(classes[1][0]+classes[1][1])
+	x := p01 + p10 - 1
+	return &Result{
+		Test:      "T6b",
+		Name:      "Uniform Distribution Test (transitions)",
+		Block:     -1,
+		Statistic: x,
+		Criterion: "|p(1|0) + p(0|1) - 1| < 0.02",
+		Pass:      math.Abs(x) < 0.02,
+	}
+}
+
+// Homogeneity is the test T7 on the classes of k-bit tuples collected by their first k-1 bits:
+// the distribution of the last bit must be the same in the two classes that differ only in their
+// first bit. Each pair of classes is compared by a χ² homogeneity test with 1 degree of freedom
+// at the level 0.0001; the statistic is the largest of their χ² statistics.
+func Homogeneity(classes [][2]int) *Result {
+	half := len(classes) / 2
+	worst := 0.0
+	for i := 0; i < half; i++ {
+		worst = max(worst, homogeneity(classes[i], classes[i+half]))
+	}
+	k := bits.Len(uint(len(classes))) // 2^(k-1) classes
+	test := "T7a"
+	if k == 4 {
+		test = "T7b"
+	}
+	return &Result{
+		Test:      test,
+		Name:      fmt.Sprintf("Homogeneity Test (%d-bit tuples)", k),
+		Block:     -1,
+		Statistic: worst,
+		Criterion: fmt.Sprintf("X <= %.2f", homogeneityCutoff),
+		Pass:      worst <= homogeneityCutoff,
+	}
+}
+
+// homogeneity returns the χ² statistic comparing the counts of two samples.
+func homogeneity(a, c [2]int) float64 {
+	na, nc := float64(a[0]+a[1]), float64(c[0]+c[1])
+	t := 0.0
+	for x := 0; x < 2; x++ {
+		p := float64(a[x]+c[x]) / (na + nc)
+		if p == 0 {
+			continue
+		}
+		ea, ec := na*p, nc*p
+		t += (float64(a[x])-ea)*(float64(a[x])-ea)/ea + (float64(c[x])-ec)*(float64(c[x])-ec)/ec
+	}
+	return t
+}
+
+// Entropy is Coron's entropy test T8 on Q + K words of 8 bits: with A_n the distance from the
+// word n to the previous occurrence of its value (n if there is none), the statistic
+//
+//	f = 1/K Σ_{n=Q+1}^{Q+K} g(A_n), where g(i) = 1/ln(2) Σ_{k=1}^{i-1} 1/k,
+//
+// estimates the entropy per word and must exceed 7.976.
+func Entropy(words []int) *Result {
+	// harmonic[i] = Σ_{k=1}^{i-1} 1/k
+	harmonic := make([]float64, len(words)+1)
+	for i := 2; i < len(harmonic); i++ {
+		harmonic[i] = harmonic[i-1] + 1/float64(i-1)
+	}
+
+	var last [1 << entropyL]int // the position of the last occurrence of each value, from 1
+	sum := 0.0
+	for i, w := range words {
+		n := i + 1
+		if n > entropyQ {
+			a := n
+			if last[w] > 0 {
+				a = n - last[w]
+			}
+			sum += harmonic[a]
+		}
+		last[w] = n
+	}
+	k := len(words) - entropyQ
+	f := sum / float64(k) / math.Ln2
+	return &Result{
+		Test:      "T8",
+		Name:      "Entropy Test",
+		Block:     -1,
+		Statistic: f,
+		Criterion: "X > 7.976",
+		Pass:      f > 7.976,
+	}
+}
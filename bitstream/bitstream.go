@@ -1,10 +1,8 @@
 package bitstream
 
 import (
-	"bufio"
 	"errors"
 	"os"
-	"strconv"
 )
 
 const (
@@ -86,40 +84,62 @@ func (bs *BitStream) Bytes() []byte {
 	return bs.data
 }
 
-// getIndexes is a helper function that calculates the byte and bit index within the byte
-// for the given bit position in the bitsream.
-func getIndexes(index int) (byteInex, bitIndex int) {
-	return index / bitSize, index % bitSize
+// Slice returns a new BitStream holding a copy of the bits in [start, end).
+// It returns an error if the range is out of bounds.
+func (bs *BitStream) Slice(start, end int) (*BitStream, error) {
+	if start < 0 || end > bs.len || start > end {
+		return nil, ErrOutOfRange
+	}
+
+	n := end - start
+	data := make([]byte, (n+msbIndex)/bitSize)
+	if start%bitSize == 0 {
+		byteIndex, _ := getIndexes(start)
+		copy(data, bs.data[byteIndex:])
+	} else {
+		for i := 0; i < n; i++ {
+			bit, _ := bs.Bit(start + i)
+			data[i/bitSize] |= bit << uint(msbIndex-i%bitSize)
+		}
+	}
+	// clear the bits past the end of the slice
+	if rem := n % bitSize; rem != 0 {
+		data[len(data)-1] &= byte(0xff << uint(bitSize-rem))
+	}
+	return &BitStream{data: data, len: n}, nil
 }
 
-// FromFile reads a file containing a list of numbers and returns a Bitstream.
-func FromFile(filename string) (*BitStream, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, err
+// Split partitions the bitstream into count consecutive sequences of n bits each.
+// It returns ErrNotEnoughBits if the bitstream holds fewer than n*count bits.
+func (bs *BitStream) Split(n, count int) ([]*BitStream, error) {
+	if n <= 0 || count <= 0 {
+		return nil, ErrOutOfRange
+	}
+	if n*count > bs.len {
+		return nil, ErrNotEnoughBits
 	}
-	defer file.Close()
 
-	var data []byte
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if num, err := strconv.Atoi(line); err == nil {
-			if num <= 0xff {
-				data = append(data, byte(num))
-			} else {
-				data = append(data, byte(num>>8), byte(num&0xff))
-			}
-		} else {
+	seqs := make([]*BitStream, count)
+	for i := range seqs {
+		seq, err := bs.Slice(i*n, (i+1)*n)
+		if err != nil {
 			return nil, err
 		}
+		seqs[i] = seq
 	}
+	return seqs, nil
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
+// getIndexes is a helper function that calculates the byte and bit index within the byte
+// for the given bit position in the bitsream.
+func getIndexes(index int) (byteInex, bitIndex int) {
+	return index / bitSize, index % bitSize
+}
 
-	return NewBitStream(data), nil
+// FromFile reads a file containing a list of numbers and returns a Bitstream.
+// Use FromFileFormat to read the other formats.
+func FromFile(filename string) (*BitStream, error) {
+	return FromFileFormat(filename, FormatDecimal)
 }
 
 // FromFileWithLimit reads a file containing a list of numbers and returns a Bitstream.
@@ -131,29 +151,5 @@ func FromFileWithLimit(filename string, limit int) (*BitStream, error) {
 	}
 	defer file.Close()
 
-	var data []byte
-	scanner := bufio.NewScanner(file)
-	lineCount := 0
-	for scanner.Scan() {
-		if lineCount >= limit {
-			break
-		}
-		line := scanner.Text()
-		if num, err := strconv.Atoi(line); err == nil {
-			if num <= 0xff {
-				data = append(data, byte(num))
-			} else {
-				data = append(data, byte(num>>8), byte(num&0xff))
-			}
-		} else {
-			return nil, err
-		}
-		lineCount++
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
-
-	return NewBitStream(data), nil
+	return readIntegers(file, DefaultIntegerOptions(), limit)
 }
@@ -1,6 +1,7 @@
 package bitstream
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -196,3 +197,57 @@ func TestBit(t *testing.T) {
 		}
 	}
 }
+
+func TestSlice(t *testing.T) {
+	bs := NewBitStream([]byte{0b10110011, 0b01011100}) // 10110011 01011100
+	tests := []struct {
+		name       string
+		start, end int
+		wantLen    int
+		wantBytes  []byte
+		wantErr    error
+	}{
+		{"Aligned", 0, 8, 8, []byte{0b10110011}, nil},
+		{"Aligned partial", 8, 12, 4, []byte{0b01010000}, nil},
+		{"Unaligned", 3, 13, 10, []byte{0b10011010, 0b11000000}, nil},
+		{"Empty", 5, 5, 0, []byte{}, nil},
+		{"Out of range", 4, 17, 0, nil, ErrOutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := bs.Slice(tt.start, tt.end)
+			if err != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if got.Len() != tt.wantLen {
+				t.Errorf("Expected length %d, got %d", tt.wantLen, got.Len())
+			}
+			if !reflect.DeepEqual(got.Bytes(), tt.wantBytes) {
+				t.Errorf("Expected bytes %08b, got %08b", tt.wantBytes, got.Bytes())
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	bs := NewBitStream([]byte{0xF0, 0x0F, 0xAA})
+
+	seqs, err := bs.Split(6, 4)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := [][]byte{{0b11110000}, {0b00000000}, {0b11111000}, {0b10101000}}
+	for i, seq := range seqs {
+		if seq.Len() != 6 || !reflect.DeepEqual(seq.Bytes(), want[i]) {
+			t.Errorf("sequence %d: expected %08b, got %08b (len %d)", i, want[i], seq.Bytes(), seq.Len())
+		}
+	}
+
+	if _, err := bs.Split(8, 4); err != ErrNotEnoughBits {
+		t.Errorf("Expected error %v, got %v", ErrNotEnoughBits, err)
+	}
+}
@@ -0,0 +1,102 @@
+package bitstream
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown input format")
+	ErrInvalidInput  = errors.New("invalid input")
+)
+
+// Format is the encoding of the bits in an input file.
+type Format int
+
+const (
+	// FormatDecimal is one decimal integer per line (the format of the files in rand_data).
+	// Read takes each integer as a byte; use ReadIntegers for wider words.
+	FormatDecimal Format = iota
+	// FormatRaw is binary data, whose bytes are used as-is (e.g. a dump of /dev/urandom).
+	FormatRaw
+	// FormatASCII is text made of the characters '0' and '1', one per bit
+	// (the format of data.pi and data.e of the NIST reference implementation).
+	// Whitespace is ignored.
+	FormatASCII
+	// FormatHex is text made of hexadecimal digits, four bits per digit.
+	// Whitespace and "0x" prefixes are ignored.
+	FormatHex
+)
+
+var formatNames = map[Format]string{
+	FormatDecimal: "decimal",
+	FormatRaw:     "raw",
+	FormatASCII:   "ascii",
+	FormatHex:     "hex",
+}
+
+// String returns the name of the format, as accepted by ParseFormat.
+func (f Format) String() string {
+	if name, ok := formatNames[f]; ok {
+		return name
+	}
+	return fmt.Sprintf("Format(%d)", int(f))
+}
+
+// ParseFormat returns the format with the given name ("decimal", "raw", "ascii" or "hex").
+func ParseFormat(name string) (Format, error) {
+	for f, n := range formatNames {
+		if n == strings.ToLower(name) {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrUnknownFormat, name)
+}
+
+// Read reads all bits from r, encoded in the given format.
+func Read(r io.Reader, format Format) (*BitStream, error) {
+	switch format {
+	case FormatDecimal:
+		return ReadIntegers(r, DefaultIntegerOptions())
+	case FormatRaw:
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return NewBitStream(data), nil
+	}
+
+	sr, err := NewStreamReader(r, format)
+	if err != nil {
+		return nil, err
+	}
+	return readAll(sr.next)
+}
+
+// FromFileFormat reads a file whose bits are encoded in the given format.
+func FromFileFormat(filename string, format Format) (*BitStream, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Read(bufio.NewReader(file), format)
+}
+
+// hexDigit decodes a hexadecimal digit.
+func hexDigit(c byte) (uint8, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
@@ -0,0 +1,125 @@
+package bitstream
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   Format
+		input    string
+		wantBits []byte
+		wantLen  int
+		wantErr  error
+	}{
+		{
+			name:     "Decimal",
+			format:   FormatDecimal,
+			input:    "170\n15\n\n255\n",
+			wantBits: []byte{0xAA, 0x0F, 0xFF},
+			wantLen:  24,
+		},
+		{
+			name:    "Decimal Invalid",
+			format:  FormatDecimal,
+			input:   "170\nabc\n",
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name:     "Raw",
+			format:   FormatRaw,
+			input:    "\x00\xff\n",
+			wantBits: []byte{0x00, 0xFF, 0x0A},
+			wantLen:  24,
+		},
+		{
+			name:     "ASCII",
+			format:   FormatASCII,
+			input:    "1010 1010\r\n0000\n1",
+			wantBits: []byte{0xAA, 0x08},
+			wantLen:  13,
+		},
+		{
+			name:    "ASCII Invalid",
+			format:  FormatASCII,
+			input:   "0101\n0121\n",
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name:     "Hex",
+			format:   FormatHex,
+			input:    "0xaa0F ff\nC",
+			wantBits: []byte{0xAA, 0x0F, 0xFF, 0xC0},
+			wantLen:  28,
+		},
+		{
+			name:    "Hex Invalid",
+			format:  FormatHex,
+			input:   "aag",
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name:    "Unknown Format",
+			format:  Format(42),
+			wantErr: ErrUnknownFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bs, err := Read(strings.NewReader(tt.input), tt.format)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Read() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if bs.Len() != tt.wantLen {
+				t.Errorf("Len() = %d, want %d", bs.Len(), tt.wantLen)
+			}
+			if !reflect.DeepEqual(bs.Bytes(), tt.wantBits) {
+				t.Errorf("Bytes() = %x, want %x", bs.Bytes(), tt.wantBits)
+			}
+		})
+	}
+}
+
+func TestReadLineNumber(t *testing.T) {
+	_, err := Read(strings.NewReader("0101\n\n01x1\n"), FormatASCII)
+	if err == nil || !strings.HasPrefix(err.Error(), "line 3:") {
+		t.Errorf("Read() error = %v, want an error on line 3", err)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, f := range []Format{FormatDecimal, FormatRaw, FormatASCII, FormatHex} {
+		got, err := ParseFormat(f.String())
+		if err != nil || got != f {
+			t.Errorf("ParseFormat(%q) = %v, %v, want %v", f.String(), got, err, f)
+		}
+	}
+	if _, err := ParseFormat("base64"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("ParseFormat(\"base64\") error = %v, want %v", err, ErrUnknownFormat)
+	}
+}
+
+func TestFromFileFormat(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "data.e")
+	if err := os.WriteFile(filename, []byte("   1011011111100001\n   0101000101100010\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	bs, err := FromFileFormat(filename, FormatASCII)
+	if err != nil {
+		t.Fatalf("FromFileFormat() error = %v", err)
+	}
+	if want := []byte{0xB7, 0xE1, 0x51, 0x62}; !reflect.DeepEqual(bs.Bytes(), want) {
+		t.Errorf("Bytes() = %x, want %x", bs.Bytes(), want)
+	}
+}
@@ -0,0 +1,99 @@
+package bitstream
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+)
+
+// IntegerOptions describes how the decimal integers of a FormatDecimal input are turned into bits.
+type IntegerOptions struct {
+	WordSize     int  // width in bits of each integer: 8, 16, 32 or 64
+	LittleEndian bool // write the bytes of each word least significant first
+	LowBits      int  // use only the low bits of each word, 0 to use the whole word
+}
+
+// DefaultIntegerOptions returns the options of FromFile: one byte per line.
+func DefaultIntegerOptions() IntegerOptions {
+	return IntegerOptions{WordSize: 8}
+}
+
+// bits returns the number of bits written for each word.
+func (o IntegerOptions) bits() int {
+	if o.LowBits == 0 {
+		return o.WordSize
+	}
+	return o.LowBits
+}
+
+func (o IntegerOptions) validate() error {
+	switch o.WordSize {
+	case 8, 16, 32, 64:
+	default:
+		return fmt.Errorf("word size must be 8, 16, 32 or 64 bits, got %d", o.WordSize)
+	}
+	if o.LowBits < 0 || o.LowBits > o.WordSize {
+		return fmt.Errorf("low bits must be between 1 and the word size %d, got %d", o.WordSize, o.LowBits)
+	}
+	if o.LittleEndian && o.bits()%bitSize != 0 {
+		return fmt.Errorf("little endian words need a whole number of bytes, got %d bits", o.bits())
+	}
+	return nil
+}
+
+// ReadIntegers reads one decimal integer per line and writes each as a word of opts.WordSize
+// bits. Values that do not fit in a word are rejected with the number of their line.
+// Blank lines are skipped.
+func ReadIntegers(r io.Reader, opts IntegerOptions) (*BitStream, error) {
+	return readIntegers(r, opts, -1)
+}
+
+// FromFileIntegers reads a file of decimal integers, one per line, as ReadIntegers does.
+func FromFileIntegers(filename string, opts IntegerOptions) (*BitStream, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadIntegers(file, opts)
+}
+
+// readIntegers reads at most limit integers, or all of them if limit is negative.
+func readIntegers(r io.Reader, opts IntegerOptions, limit int) (*BitStream, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	next := integerDecoder(bufio.NewReader(r), opts)
+	if limit >= 0 {
+		unlimited := next
+		next = func() (uint64, int, error) {
+			if limit == 0 {
+				return 0, 0, io.EOF
+			}
+			limit--
+			return unlimited()
+		}
+	}
+	return readAll(next)
+}
+
+// word returns the low bits() bits of num in the order they are written: most significant
+// bit first, or byte by byte from the least significant byte for little endian words.
+func (o IntegerOptions) word(num uint64) uint64 {
+	n := o.bits()
+	if n < 64 {
+		num &= 1<<uint(n) - 1
+	}
+	if !o.LittleEndian {
+		return num
+	}
+
+	var swapped uint64
+	for i := 0; i < n; i += bitSize {
+		swapped = swapped<<bitSize | (num>>uint(i))&0xff
+	}
+	return swapped
+}
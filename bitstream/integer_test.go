@@ -0,0 +1,109 @@
+package bitstream
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadIntegers(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     IntegerOptions
+		wantBits []byte
+		wantLen  int
+		wantErr  error
+	}{
+		{
+			name:     "Bytes",
+			input:    "170\n15\n",
+			opts:     DefaultIntegerOptions(),
+			wantBits: []byte{0xAA, 0x0F},
+			wantLen:  16,
+		},
+		{
+			name:    "Byte Out Of Range",
+			input:   "170\n256\n",
+			opts:    DefaultIntegerOptions(),
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name:    "Negative",
+			input:   "-1\n",
+			opts:    DefaultIntegerOptions(),
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name:     "32-bit Big Endian",
+			input:    "2706329911\n1",
+			opts:     IntegerOptions{WordSize: 32},
+			wantBits: []byte{0xA1, 0x4F, 0x51, 0x37, 0x00, 0x00, 0x00, 0x01},
+			wantLen:  64,
+		},
+		{
+			name:     "32-bit Little Endian",
+			input:    "2706329911\n1",
+			opts:     IntegerOptions{WordSize: 32, LittleEndian: true},
+			wantBits: []byte{0x37, 0x51, 0x4F, 0xA1, 0x01, 0x00, 0x00, 0x00},
+			wantLen:  64,
+		},
+		{
+			name:     "64-bit",
+			input:    "18446744073709551615",
+			opts:     IntegerOptions{WordSize: 64},
+			wantBits: []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF},
+			wantLen:  64,
+		},
+		{
+			name:     "Low Bits",
+			input:    "65535\n65520\n",
+			opts:     IntegerOptions{WordSize: 16, LowBits: 4},
+			wantBits: []byte{0xF0},
+			wantLen:  8,
+		},
+		{
+			name:     "Low Bytes Little Endian",
+			input:    "66051\n",
+			opts:     IntegerOptions{WordSize: 32, LittleEndian: true, LowBits: 16},
+			wantBits: []byte{0x03, 0x02},
+			wantLen:  16,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bs, err := ReadIntegers(strings.NewReader(tt.input), tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReadIntegers() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if bs.Len() != tt.wantLen {
+				t.Errorf("Len() = %d, want %d", bs.Len(), tt.wantLen)
+			}
+			if !reflect.DeepEqual(bs.Bytes(), tt.wantBits) {
+				t.Errorf("Bytes() = %x, want %x", bs.Bytes(), tt.wantBits)
+			}
+		})
+	}
+}
+
+func TestReadIntegersErrors(t *testing.T) {
+	_, err := ReadIntegers(strings.NewReader("1\n2\n\n70000\n"), IntegerOptions{WordSize: 16})
+	if err == nil || err.Error() != "line 4: invalid input: 70000 does not fit in 16 bits" {
+		t.Errorf("ReadIntegers() error = %v, want an out of range error on line 4", err)
+	}
+
+	for _, opts := range []IntegerOptions{
+		{WordSize: 12},
+		{WordSize: 8, LowBits: 9},
+		{WordSize: 16, LowBits: 4, LittleEndian: true},
+	} {
+		if _, err := ReadIntegers(strings.NewReader("1\n"), opts); err == nil {
+			t.Errorf("ReadIntegers() with %+v: expected an error", opts)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package bitstream
 
-import "errors"
+import (
+	"errors"
+	"io"
+)
 
 var ErrNotEnoughBits = errors.New("not enough bits")
 
@@ -46,8 +49,11 @@ func (r *BitStreamReader) ReadByte() (byte, error) {
 }
 
 // ReadBit reads a single bit from the BitStream and returns it as a byte.
-// It returns an error if there are not enough bits to read.
+// It returns io.EOF once every bit was read, so that a BitStreamReader is a BitSource.
 func (r *BitStreamReader) ReadBit() (byte, error) {
+	if r.offset >= r.bs.Len() {
+		return 0, io.EOF
+	}
 	b, err := r.bs.Bit(r.offset)
 	if err != nil {
 		return 0, err
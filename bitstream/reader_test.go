@@ -2,6 +2,7 @@ package bitstream
 
 import (
 	"errors"
+	"io"
 	"reflect"
 	"testing"
 )
@@ -116,3 +117,15 @@ func TestBitStreamReader(t *testing.T) {
 		})
 	}
 }
+
+func TestBitStreamReaderEOF(t *testing.T) {
+	var src BitSource = NewBitStreamReader(NewBitStream([]byte{0x80}))
+	for i := 0; i < 8; i++ {
+		if _, err := src.ReadBit(); err != nil {
+			t.Fatalf("bit %d: %v", i, err)
+		}
+	}
+	if _, err := src.ReadBit(); err != io.EOF {
+		t.Errorf("got %v after the last bit, expected io.EOF", err)
+	}
+}
@@ -0,0 +1,168 @@
+package bitstream
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// BitSource yields the bits of a sequence one at a time.
+// ReadBit returns io.EOF once every bit was read.
+type BitSource interface {
+	ReadBit() (byte, error)
+}
+
+// decoder returns the next n bits of the input, most significant bit first in word.
+// It returns io.EOF at the end of the input.
+type decoder func() (word uint64, n int, err error)
+
+// StreamReader reads the bits of an io.Reader as they are needed, so that inputs
+// larger than the memory can be tested. It implements BitSource.
+type StreamReader struct {
+	next  decoder
+	word  uint64 // bits decoded but not read yet
+	n     int    // number of bits left in word
+	count int    // number of bits read so far
+}
+
+// NewStreamReader returns a StreamReader decoding r in the given format.
+// Decimal integers are taken as bytes; use NewIntegerStreamReader for wider words.
+func NewStreamReader(r io.Reader, format Format) (*StreamReader, error) {
+	br := bufio.NewReader(r)
+	switch format {
+	case FormatDecimal:
+		return NewIntegerStreamReader(br, DefaultIntegerOptions())
+	case FormatRaw:
+		return &StreamReader{next: func() (uint64, int, error) {
+			c, err := br.ReadByte()
+			return uint64(c), bitSize, err
+		}}, nil
+	case FormatASCII:
+		return &StreamReader{next: textDecoder(br, 1, func(c byte) (uint8, bool) {
+			if c == '0' || c == '1' {
+				return c - '0', true
+			}
+			return 0, false
+		})}, nil
+	case FormatHex:
+		return &StreamReader{next: textDecoder(br, 4, hexDigit)}, nil
+	}
+	return nil, fmt.Errorf("%w: %v", ErrUnknownFormat, format)
+}
+
+// NewIntegerStreamReader returns a StreamReader decoding one decimal integer per line
+// of r, as ReadIntegers does.
+func NewIntegerStreamReader(r io.Reader, opts IntegerOptions) (*StreamReader, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	return &StreamReader{next: integerDecoder(bufio.NewReader(r), opts)}, nil
+}
+
+// ReadBit returns the next bit of the input, or io.EOF at its end.
+func (r *StreamReader) ReadBit() (byte, error) {
+	for r.n == 0 {
+		word, n, err := r.next()
+		if err != nil {
+			return 0, err
+		}
+		r.word, r.n = word, n
+	}
+	r.n--
+	r.count++
+	return byte(r.word>>uint(r.n)) & 1, nil
+}
+
+// Count returns the number of bits read so far.
+func (r *StreamReader) Count() int {
+	return r.count
+}
+
+// readAll appends every bit decoded by next to a new BitStream.
+func readAll(next decoder) (*BitStream, error) {
+	bs := NewBitStream(nil)
+	for {
+		word, n, err := next()
+		if err == io.EOF {
+			return bs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		for i := n - 1; i >= 0; i-- {
+			bs.Append(byte(word>>uint(i)) & 1)
+		}
+	}
+}
+
+// textDecoder decodes text where each character decoded by digit holds the given number of bits.
+// Whitespace is skipped, and so is the "0x" prefix of hexadecimal numbers.
+func textDecoder(br *bufio.Reader, bitsPerChar int, digit func(c byte) (uint8, bool)) decoder {
+	lineNum := 1
+	wordStart := true
+	return func() (uint64, int, error) {
+		for {
+			c, err := br.ReadByte()
+			if err != nil {
+				return 0, 0, err
+			}
+
+			switch c {
+			case '\n':
+				lineNum++
+				wordStart = true
+				continue
+			case ' ', '\t', '\r':
+				wordStart = true
+				continue
+			}
+
+			if bitsPerChar == 4 && wordStart && c == '0' {
+				if next, err := br.Peek(1); err == nil && (next[0] == 'x' || next[0] == 'X') {
+					br.ReadByte()
+					wordStart = false
+					continue
+				}
+			}
+			wordStart = false
+
+			v, ok := digit(c)
+			if !ok {
+				return 0, 0, fmt.Errorf("line %d: %w: unexpected character %q", lineNum, ErrInvalidInput, c)
+			}
+			return uint64(v), bitsPerChar, nil
+		}
+	}
+}
+
+// integerDecoder decodes one decimal integer per line into a word of opts.bits() bits.
+// Values that do not fit in opts.WordSize bits are rejected with the number of their line.
+// Blank lines are skipped.
+func integerDecoder(br *bufio.Reader, opts IntegerOptions) decoder {
+	lineNum := 0
+	return func() (uint64, int, error) {
+		for {
+			line, err := br.ReadString('\n')
+			if err != nil && (err != io.EOF || line == "") {
+				return 0, 0, err
+			}
+			lineNum++
+
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+
+			num, err := strconv.ParseUint(line, 10, opts.WordSize)
+			if err != nil {
+				if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+					return 0, 0, fmt.Errorf("line %d: %w: %s does not fit in %d bits", lineNum, ErrInvalidInput, line, opts.WordSize)
+				}
+				return 0, 0, fmt.Errorf("line %d: %w: %q is not a non-negative decimal integer", lineNum, ErrInvalidInput, line)
+			}
+			return opts.word(num), opts.bits(), nil
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package bitstream
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestStreamReader(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		input  string
+		want   string
+	}{
+		{name: "Raw", format: FormatRaw, input: "\xa5\x0f", want: "1010010100001111"},
+		{name: "Decimal", format: FormatDecimal, input: "165\n\n15\n", want: "1010010100001111"},
+		{name: "ASCII", format: FormatASCII, input: "101 0\n01", want: "101001"},
+		{name: "Hex", format: FormatHex, input: "0xa5 0F\n", want: "1010010100001111"},
+		{name: "Empty", format: FormatRaw, input: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewStreamReader(strings.NewReader(tt.input), tt.format)
+			if err != nil {
+				t.Fatalf("NewStreamReader() error = %v", err)
+			}
+
+			var got strings.Builder
+			for {
+				bit, err := r.ReadBit()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("ReadBit() error = %v", err)
+				}
+				got.WriteByte('0' + bit)
+			}
+			if got.String() != tt.want {
+				t.Errorf("read %s, want %s", got.String(), tt.want)
+			}
+			if r.Count() != len(tt.want) {
+				t.Errorf("Count() = %d, want %d", r.Count(), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestStreamReaderError(t *testing.T) {
+	r, err := NewIntegerStreamReader(strings.NewReader("1\n300\n"), DefaultIntegerOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 8; i++ {
+		if _, err := r.ReadBit(); err != nil {
+			t.Fatalf("ReadBit() error = %v", err)
+		}
+	}
+	if _, err := r.ReadBit(); !errors.Is(err, ErrInvalidInput) || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Errorf("ReadBit() error = %v, want an invalid input error on line 2", err)
+	}
+
+	if _, err := NewStreamReader(strings.NewReader(""), Format(42)); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("NewStreamReader() error = %v, want %v", err, ErrUnknownFormat)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/notJoon/drbg/diehard"
+	nist "github.com/notJoon/drbg/nist"
+)
+
+// diehardTests implements the diehard subcommand: it runs the tests of the Diehard battery on the
+// 32-bit words of a file or a generator, every test reading the words from the start.
+func diehardTests(args []string) {
+	flags := flag.NewFlagSet("diehard", flag.ExitOnError)
+	in := addInputFlags(flags, 0)
+	testList := flags.String("tests", "", "Comma-separated list of test ids to run (see -list). Empty runs the whole battery")
+	list := flags.Bool("list", false, "List the tests of the battery")
+	format := flags.String("format", "table", "Output format: table, json, csv or junit")
+	flags.Parse(args)
+
+	if *list {
+		listTests(os.Stdout, diehard.IDs(), diehard.New)
+		return
+	}
+
+	switch *format {
+	case "table", "json", "csv", "junit":
+	default:
+		fmt.Printf("Error: unknown format %q (expected table, json, csv or junit)\n", *format)
+		os.Exit(1)
+	}
+
+	ids := appendIDs(nil, *testList)
+	if len(ids) == 0 {
+		ids = diehard.IDs()
+	}
+	tests := make([]nist.Test, 0, len(ids))
+	for _, id := range ids {
+		test, err := diehard.New(id)
+		if err != nil {
+			fmt.Printf("Error (%s): %v\n", id, err)
+			os.Exit(1)
+		}
+		tests = append(tests, test)
+	}
+
+	// with -bits 0, draw the words read by the longest test
+	if *in.bits == 0 {
+		for _, test := range tests {
+			*in.bits = max(*in.bits, test.MinLength())
+		}
+	}
+	bs, source, err := in.read()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	rep := runTests(source, ids, tests, bs)
+	if err := writeReport(os.Stdout, *format, rep); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if _, _, _, errored := rep.Counts(); errored > 0 {
+		os.Exit(1)
+	}
+}
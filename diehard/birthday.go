@@ -0,0 +1,89 @@
+package diehard
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	b "github.com/notJoon/drbg/bitstream"
+	nist "github.com/notJoon/drbg/nist"
+)
+
+const (
+	birthdays       = 512     // m, the birthdays of a sample
+	birthdayDays    = 1 << 24 // n, the days of a year
+	birthdayOffsets = 9       // the 24-bit birthdays start at the bits 1 to 9 of the words
+	birthdaySamples = 500
+)
+
+type birthdaySpacingsTest struct{}
+
+// NewBirthdaySpacingsTest returns the Birthday Spacings Test: m = 512 birthdays are drawn in a
+// year of n = 2^24 days, and the number of values occurring more than once among the sorted
+// spacings between them is asymptotically Poisson with mean λ = m³ / 4n = 2. For each of the 9
+// choices of 24 consecutive bits of the words, the counts of 500 samples are compared with the
+// Poisson distribution by a χ² test.
+func NewBirthdaySpacingsTest() nist.Test { return birthdaySpacingsTest{} }
+
+func (birthdaySpacingsTest) Name() string    { return "Birthday Spacings Test" }
+func (birthdaySpacingsTest) Section() string { return "1" }
+func (birthdaySpacingsTest) Params() map[string]any {
+	return map[string]any{"m": birthdays, "n": birthdayDays, "samples": birthdaySamples}
+}
+func (birthdaySpacingsTest) MinLength() int {
+	return birthdayOffsets * birthdaySamples * birthdays * 32
+}
+
+func (t birthdaySpacingsTest) Run(bs *b.BitStream) (*nist.Result, error) {
+	n := birthdayOffsets * birthdaySamples * birthdays
+	ws, err := newWords(bs, n)
+	if err != nil {
+		return nil, err
+	}
+
+	// the Poisson probabilities of 0 to 5 repeated spacings, and of 6 or more
+	const lambda = float64(birthdays) * birthdays * birthdays / (4 * birthdayDays)
+	probs := make([]float64, 7)
+	tail := 1.0
+	for j := 0; j < 6; j++ {
+		probs[j] = math.Exp(-lambda) * math.Pow(lambda, float64(j)) / math.Gamma(float64(j+1))
+		tail -= probs[j]
+	}
+	probs[6] = tail
+	expected := make([]float64, len(probs))
+	for i, p := range probs {
+		expected[i] = p * birthdaySamples
+	}
+
+	var labels []string
+	var pValues, statistics []float64
+	days := make([]uint32, birthdays)
+	spacings := make([]uint32, birthdays)
+	for offset := 0; offset < birthdayOffsets; offset++ {
+		counts := make([]int, len(probs))
+		for s := 0; s < birthdaySamples; s++ {
+			sample, _ := ws.take(birthdays)
+			for i, w := range sample {
+				days[i] = w >> (8 - offset) & (birthdayDays - 1)
+			}
+			slices.Sort(days)
+			spacings[0] = days[0]
+			for i := 1; i < birthdays; i++ {
+				spacings[i] = days[i] - days[i-1]
+			}
+			slices.Sort(spacings)
+			repeated := 0
+			for i := 1; i < birthdays; i++ {
+				if spacings[i] == spacings[i-1] {
+					repeated++
+				}
+			}
+			counts[min(repeated, 6)]++
+		}
+		x := chiSquare(counts, expected)
+		labels = append(labels, fmt.Sprintf("bits %d-%d", offset+1, offset+24))
+		pValues = append(pValues, chiSquareP(x, len(probs)-1))
+		statistics = append(statistics, x)
+	}
+	return result(t, n*32, labels, pValues, statistics), nil
+}
@@ -0,0 +1,125 @@
+package diehard
+
+import (
+	"fmt"
+	"math/bits"
+
+	b "github.com/notJoon/drbg/bitstream"
+	nist "github.com/notJoon/drbg/nist"
+)
+
+const (
+	countOnesWindows = 256000 // the overlapping 5-letter words of a sample
+	countOnesSamples = 2
+)
+
+// countOnesProbs are the probabilities of the letters of the count-the-1s tests: a byte with at
+// most 2, 3, 4, 5, or at least 6 ones.
+var countOnesProbs = [5]float64{37.0 / 256, 56.0 / 256, 70.0 / 256, 56.0 / 256, 37.0 / 256}
+
+// countOnesLetter returns the letter of a byte.
+func countOnesLetter(c byte) int {
+	return min(max(bits.OnesCount8(c), 2), 6) - 2
+}
+
+// countOnesQ returns Q5 - Q4 for the letters: with Qk the χ² statistic of the counts of the
+// overlapping k-letter words, Q5 - Q4 is asymptotically χ² with 5^5 - 5^4 = 2500 degrees of
+// freedom.
+func countOnesQ(letters []int) float64 {
+	q := func(k int) float64 {
+		cells := 1
+		for i := 0; i < k; i++ {
+			cells *= 5
+		}
+		counts := make([]int, cells)
+		expected := make([]float64, cells)
+		for w := range expected {
+			p := float64(countOnesWindows)
+			for i, x := 0, w; i < k; i, x = i+1, x/5 {
+				p *= countOnesProbs[x%5]
+			}
+			expected[w] = p
+		}
+		for i := 0; i < countOnesWindows; i++ {
+			w := 0
+			for _, l := range letters[i : i+k] {
+				w = w*5 + l
+			}
+			counts[w]++
+		}
+		return chiSquare(counts, expected)
+	}
+	return q(5) - q(4)
+}
+
+type countOnesTest struct {
+	bytes bool // the letters are a byte of each word, instead of every byte of the words
+}
+
+// NewCountOnesStreamTest returns the Count-the-1s Test on a stream of bytes: each byte of the
+// words is a letter, according to its number of ones, and the counts of the 256,000 overlapping
+// 5-letter words of 2 samples are tested by the statistic Q5 - Q4.
+func NewCountOnesStreamTest() nist.Test { return countOnesTest{false} }
+
+// NewCountOnesBytesTest returns the Count-the-1s Test on specific bytes: a byte of each word is
+// a letter, and each of the 25 choices of 8 consecutive bits is tested on the same 256,004 words.
+func NewCountOnesBytesTest() nist.Test { return countOnesTest{true} }
+
+func (t countOnesTest) Name() string {
+	if t.bytes {
+		return "Count-the-1s Test (specific bytes)"
+	}
+	return "Count-the-1s Test (stream)"
+}
+
+func (t countOnesTest) Section() string {
+	if t.bytes {
+		return "11"
+	}
+	return "10"
+}
+
+func (t countOnesTest) Params() map[string]any { return map[string]any{"windows": countOnesWindows} }
+func (t countOnesTest) MinLength() int         { return t.words() * 32 }
+
+func (t countOnesTest) words() int {
+	if t.bytes {
+		return countOnesWindows + 4
+	}
+	return countOnesSamples * (countOnesWindows + 4) / 4
+}
+
+func (t countOnesTest) Run(bs *b.BitStream) (*nist.Result, error) {
+	ws, err := newWords(bs, t.words())
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []string
+	var pValues, statistics []float64
+	letters := make([]int, countOnesWindows+4)
+	add := func(label string) {
+		q := countOnesQ(letters)
+		labels = append(labels, label)
+		pValues = append(pValues, chiSquareP(q, 2500))
+		statistics = append(statistics, q)
+	}
+
+	if t.bytes {
+		for offset := 0; offset < 25; offset++ {
+			for i := range letters {
+				letters[i] = countOnesLetter(byte(ws.w[i] >> (24 - offset)))
+			}
+			add(fmt.Sprintf("bits %d-%d", offset+1, offset+8))
+		}
+	} else {
+		data := bs.Bytes()
+		for s := 0; s < countOnesSamples; s++ {
+			for i := range letters {
+				letters[i] = countOnesLetter(data[s*len(letters)+i])
+			}
+			add(fmt.Sprintf("sample %d", s+1))
+		}
+	}
+	return result(t, t.words()*32, labels, pValues, statistics), nil
+}
@@ -0,0 +1,90 @@
+package diehard
+
+import (
+	"math"
+
+	b "github.com/notJoon/drbg/bitstream"
+	nist "github.com/notJoon/drbg/nist"
+)
+
+const (
+	crapsGames     = 200000
+	crapsMaxThrows = 21 // the games of 21 or more throws are counted together
+)
+
+type crapsTest struct{}
+
+// NewCrapsTest returns the Craps Test: 200,000 games of craps are played with dice rolled from
+// uniform floats. The number of wins is approximately normal with mean 244/495 of the games, and
+// the numbers of throws of the games, from 1 to 21 or more, are compared with their distribution
+// by a χ² test.
+func NewCrapsTest() nist.Test { return crapsTest{} }
+
+func (crapsTest) Name() string           { return "Craps Test" }
+func (crapsTest) Section() string        { return "18" }
+func (crapsTest) Params() map[string]any { return map[string]any{"games": crapsGames} }
+
+// MinLength is the length needed on average, plus a margin: a game reads 6.76 words on average.
+func (crapsTest) MinLength() int { return 1400000 * 32 }
+
+func (t crapsTest) Run(bs *b.BitStream) (*nist.Result, error) {
+	ws, err := newWords(bs, 0)
+	if err != nil {
+		return nil, err
+	}
+	roll := func() (int, bool) {
+		w1, ok1 := ws.next()
+		w2, ok2 := ws.next()
+		return int(6*uniform(w1)) + int(6*uniform(w2)) + 2, ok1 && ok2
+	}
+
+	wins := 0
+	throws := make([]int, crapsMaxThrows)
+	for g := 0; g < crapsGames; g++ {
+		sum, ok := roll()
+		n := 1
+		switch sum {
+		case 7, 11:
+			wins++
+		case 2, 3, 12:
+		default:
+			for {
+				next, more := roll()
+				ok = ok && more
+				n++
+				if next == sum {
+					wins++
+					break
+				}
+				if next == 7 {
+					break
+				}
+			}
+		}
+		if !ok {
+			return nil, ws.errEnd()
+		}
+		throws[min(n, crapsMaxThrows)-1]++
+	}
+
+	const p = 244.0 / 495
+	z := (float64(wins) - crapsGames*p) / math.Sqrt(crapsGames*p*(1-p))
+
+	// a game of 2 or more throws has a point of 4 to 10 other than 7, reached with probability q,
+	// and ends at each throw with probability e, the probability of the point or of 7
+	expected := make([]float64, crapsMaxThrows)
+	expected[0] = 1.0 / 3
+	for _, point := range []struct{ q, e float64 }{{3.0 / 36, 9.0 / 36}, {4.0 / 36, 10.0 / 36}, {5.0 / 36, 11.0 / 36}} {
+		for n := 2; n < crapsMaxThrows; n++ {
+			expected[n-1] += 2 * point.q * math.Pow(1-point.e, float64(n-2)) * point.e
+		}
+		expected[crapsMaxThrows-1] += 2 * point.q * math.Pow(1-point.e, crapsMaxThrows-2)
+	}
+	for i := range expected {
+		expected[i] *= crapsGames
+	}
+	x := chiSquare(throws, expected)
+
+	return result(t, ws.i*32, []string{"wins", "throws"},
+		[]float64{normalP(z), chiSquareP(x, crapsMaxThrows-1)}, []float64{z, x}), nil
+}
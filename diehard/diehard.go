@@ -0,0 +1,167 @@
+// Package diehard implements the tests of George Marsaglia's Diehard battery on the 32-bit
+// words of a bitstream, most significant bit first.
+//
+// Every test is a nist.Test, so that the battery can be run and reported like the tests of
+// SP 800-22. A test computes one p-value per sample, or per choice of bits of the words, and
+// sums them up by the p-value of a Kolmogorov-Smirnov test of their uniformity, the last
+// sub-test of its result. Each test reads the words from the start of the bitstream; tests
+// needing the same number of samples for several choices of bits reuse the same words.
+package diehard
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	b "github.com/notJoon/drbg/bitstream"
+	nist "github.com/notJoon/drbg/nist"
+)
+
+var ErrNotEnoughBits = b.ErrNotEnoughBits
+
+// Registry holds the tests of the battery, in the order of the battery.
+var Registry = nist.NewRegistry()
+
+// IDs returns the ids of every test of the battery.
+func IDs() []string {
+	return Registry.IDs()
+}
+
+// New builds the test registered under the given id.
+func New(id string) (nist.Test, error) {
+	return Registry.New(id, nist.DefaultOptions())
+}
+
+func init() {
+	tests := []struct {
+		id   string
+		test nist.Test
+	}{
+		{"birthday-spacings", NewBirthdaySpacingsTest()},
+		{"operm5", NewOPERM5Test()},
+		{"rank-31x31", NewRankTest(31, 31)},
+		{"rank-32x32", NewRankTest(32, 32)},
+		{"rank-6x8", NewRankTest(6, 8)},
+		{"bitstream", NewBitstreamTest()},
+		{"opso", NewOPSOTest()},
+		{"oqso", NewOQSOTest()},
+		{"dna", NewDNATest()},
+		{"count-ones-stream", NewCountOnesStreamTest()},
+		{"count-ones-bytes", NewCountOnesBytesTest()},
+		{"parking-lot", NewParkingLotTest()},
+		{"minimum-distance", NewMinimumDistanceTest()},
+		{"3d-spheres", NewSpheresTest()},
+		{"squeeze", NewSqueezeTest()},
+		{"overlapping-sums", NewOverlappingSumsTest()},
+		{"runs", NewRunsTest()},
+		{"craps", NewCrapsTest()},
+	}
+	for _, t := range tests {
+		test := t.test
+		if err := Registry.Register(t.id, func(nist.Options) (nist.Test, error) { return test, nil }); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// words holds the 32-bit words of a bitstream, read in order.
+type words struct {
+	w []uint32
+	i int
+}
+
+// newWords returns the words of bs. It returns ErrNotEnoughBits if bs holds fewer than n words.
+func newWords(bs *b.BitStream, n int) (*words, error) {
+	count := bs.Len() / 32
+	if count < n {
+		return nil, fmt.Errorf("%w: %d words needed, got %d", ErrNotEnoughBits, n, count)
+	}
+	data := bs.Bytes()
+	w := make([]uint32, count)
+	for i := range w {
+		w[i] = binary.BigEndian.Uint32(data[4*i:])
+	}
+	return &words{w: w}, nil
+}
+
+// next returns the next word, or false at the end of the words.
+func (s *words) next() (uint32, bool) {
+	if s.i >= len(s.w) {
+		return 0, false
+	}
+	s.i++
+	return s.w[s.i-1], true
+}
+
+// take returns the next n words, or false if fewer are left.
+func (s *words) take(n int) ([]uint32, bool) {
+	if s.i+n > len(s.w) {
+		return nil, false
+	}
+	s.i += n
+	return s.w[s.i-n : s.i], true
+}
+
+// errEnd is the error of a test that reads every word before its end.
+func (s *words) errEnd() error {
+	return fmt.Errorf("%w: the test read all %d words", ErrNotEnoughBits, len(s.w))
+}
+
+// uniform maps a word to a float in (0, 1).
+func uniform(w uint32) float64 {
+	return (float64(w) + 0.5) / (1 << 32)
+}
+
+// normalP returns the two-sided p-value of a standard normal statistic.
+func normalP(z float64) float64 {
+	return math.Erfc(math.Abs(z) / math.Sqrt2)
+}
+
+// chiSquareP returns the p-value of a χ² statistic with df degrees of freedom.
+func chiSquareP(x float64, df int) float64 {
+	if x <= 0 {
+		return 1
+	}
+	return nist.Igamc(float64(df)/2, x/2)
+}
+
+// chiSquare returns Σ (o_i - e_i)² / e_i.
+func chiSquare(observed []int, expected []float64) float64 {
+	t := 0.0
+	for i, e := range expected {
+		d := float64(observed[i]) - e
+		t += d * d / e
+	}
+	return t
+}
+
+// lumpTails merges the first and last cells of a distribution into their neighbours until the
+// expected count of the tail cells, n times their probability, is at least 5. It returns the
+// probabilities of the cells kept and the first and last original cells they stand for.
+func lumpTails(probs []float64, n int) (cells []float64, first, last int) {
+	first, last = 0, len(probs)-1
+	lo, hi := probs[first], probs[last]
+	for float64(n)*lo < 5 && first < last-1 {
+		first++
+		lo += probs[first]
+	}
+	for float64(n)*hi < 5 && last > first+1 {
+		last--
+		hi += probs[last]
+	}
+	cells = append([]float64{lo}, probs[first+1:last]...)
+	return append(cells, hi), first, last
+}
+
+// result builds the result of a test from the p-value and the statistic of each sub-test, and
+// appends the Kolmogorov-Smirnov test of the uniformity of the p-values when there are several.
+func result(t nist.Test, n int, labels []string, pValues, statistics []float64) *nist.Result {
+	res := &nist.Result{Name: t.Name(), N: n, PValues: pValues, Labels: labels, Statistics: statistics}
+	if len(pValues) > 1 {
+		d, p := KS(pValues)
+		res.PValues = append(res.PValues, p)
+		res.Labels = append(res.Labels, "KS")
+		res.Statistics = append(res.Statistics, d)
+	}
+	return res
+}
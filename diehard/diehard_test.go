@@ -0,0 +1,137 @@
+package diehard
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	b "github.com/notJoon/drbg/bitstream"
+)
+
+// batteryBits returns the length read by the longest test of the battery.
+func batteryBits(t *testing.T) int {
+	n := 0
+	for _, id := range IDs() {
+		test, err := New(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n = max(n, test.MinLength())
+	}
+	return n
+}
+
+func TestKS(t *testing.T) {
+	tests := []struct {
+		n    int
+		d, p float64
+	}{
+		{1, 0.75, 0.5},                           // P(D_1 < d) = 2d - 1
+		{2, 0.75, 0.125},                         // P(D_2 < d) = 1 - 2 (1 - d)²
+		{1000, 1.3581 / math.Sqrt(1000), 0.0486}, // Stephens' approximation, near the 5% point
+	}
+	for _, tt := range tests {
+		if got := 1 - kolmogorov(tt.n, tt.d); math.Abs(got-tt.p) > 5e-4 {
+			t.Errorf("P(D_%d >= %v) = %v, expected %v", tt.n, tt.d, got, tt.p)
+		}
+	}
+
+	d, p := KS([]float64{0.1, 0.3, 0.5, 0.7, 0.9})
+	if math.Abs(d-0.1) > 1e-12 || p < 0.99 {
+		t.Errorf("KS of evenly spread values = %v, %v", d, p)
+	}
+	if _, p := KS([]float64{0.01, 0.02, 0.03, 0.04, 0.05}); p > 1e-5 {
+		t.Errorf("KS of small values has p-value %v", p)
+	}
+}
+
+func TestRankProbability(t *testing.T) {
+	expected := map[int]float64{32: 0.2888, 31: 0.5776, 30: 0.1284}
+	total := 0.0
+	for r := 0; r <= 32; r++ {
+		p := rankProbability(r, 32, 32)
+		total += p
+		if e, ok := expected[r]; ok && math.Abs(p-e) > 1e-4 {
+			t.Errorf("P(rank %d) = %v, expected %v", r, p, e)
+		}
+	}
+	if math.Abs(total-1) > 1e-12 {
+		t.Errorf("the probabilities of the ranks sum to %v", total)
+	}
+
+	rows := []uint32{0b110, 0b011, 0b101}
+	if r := rankGF2(rows, 3); r != 2 {
+		t.Errorf("rank = %d, expected 2", r)
+	}
+}
+
+func TestSqueezeDistribution(t *testing.T) {
+	total, mean := 0.0, 0.0
+	for k, p := range squeezeDistribution() {
+		total += p
+		mean += float64(k) * p
+	}
+	if math.Abs(total-1) > 1e-12 || math.Abs(mean-23.06) > 0.01 {
+		t.Errorf("the distribution sums to %v with mean %v", total, mean)
+	}
+}
+
+func TestRunsStatistic(t *testing.T) {
+	// a single increasing run of the whole sample is far from the expected counts
+	values := make([]float64, 1000)
+	for i := range values {
+		values[i] = float64(i)
+	}
+	if v := RunsStatistic(values, false); chiSquareP(v, 6) > 1e-6 {
+		t.Errorf("V = %v for sorted values", v)
+	}
+}
+
+func TestBattery(t *testing.T) {
+	if testing.Short() {
+		t.Skip("the battery reads 77 Mbit")
+	}
+	data := make([]byte, batteryBits(t)/8)
+	rand.New(rand.NewSource(1)).Read(data)
+	bs := b.NewBitStream(data)
+	for _, id := range IDs() {
+		test, _ := New(id)
+		res, err := test.Run(bs)
+		if err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+		if p := res.PValues[len(res.PValues)-1]; p < 0.001 {
+			t.Errorf("%s: p-value %v on random data", id, p)
+		}
+	}
+}
+
+func TestRANDU(t *testing.T) {
+	// the triples of RANDU, x' = 65539 x mod 2^31, lie on 15 planes
+	test, _ := New("3d-spheres")
+	data := make([]byte, test.MinLength()/8)
+	x := uint32(1)
+	for i := 0; i < len(data); i += 4 {
+		x = x * 65539 & (1<<31 - 1)
+		binary.BigEndian.PutUint32(data[i:], x<<1)
+	}
+	res, err := test.Run(b.NewBitStream(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := res.PValues[len(res.PValues)-1]; p > 1e-6 {
+		t.Errorf("p-value %v for RANDU", p)
+	}
+}
+
+func TestNotEnoughBits(t *testing.T) {
+	bs := b.NewBitStream(make([]byte, 1000))
+	for _, id := range IDs() {
+		test, _ := New(id)
+		if _, err := test.Run(bs); !errors.Is(err, ErrNotEnoughBits) {
+			t.Errorf("%s: error %v, expected ErrNotEnoughBits", id, err)
+		}
+	}
+}
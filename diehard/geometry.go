@@ -0,0 +1,187 @@
+package diehard
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	b "github.com/notJoon/drbg/bitstream"
+	nist "github.com/notJoon/drbg/nist"
+)
+
+const (
+	parkingAttempts = 12000
+	parkingSamples  = 10
+
+	distancePoints  = 8000
+	distanceSamples = 100
+
+	spheresPoints  = 4000
+	spheresSamples = 20
+)
+
+type parkingLotTest struct{}
+
+// NewParkingLotTest returns the Parking Lot Test: 12,000 cars, squares of side 2, are parked at
+// random in a lot of side 100, each car that would hit a parked car being dropped. The number of
+// parked cars is approximately normal with mean 3523 and standard deviation 21.9; 10 samples
+// are tested.
+func NewParkingLotTest() nist.Test { return parkingLotTest{} }
+
+func (parkingLotTest) Name() string    { return "Parking Lot Test" }
+func (parkingLotTest) Section() string { return "12" }
+func (parkingLotTest) Params() map[string]any {
+	return map[string]any{"attempts": parkingAttempts, "samples": parkingSamples}
+}
+func (parkingLotTest) MinLength() int { return parkingSamples * parkingAttempts * 2 * 32 }
+
+func (t parkingLotTest) Run(bs *b.BitStream) (*nist.Result, error) {
+	n := parkingSamples * parkingAttempts * 2
+	ws, err := newWords(bs, n)
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []string
+	var pValues, statistics []float64
+	// the parked cars by the unit cell of the lot holding their center
+	var grid [100][100][]point
+	for s := 0; s < parkingSamples; s++ {
+		for i := range grid {
+			for j := range grid[i] {
+				grid[i][j] = grid[i][j][:0]
+			}
+		}
+		parked := 0
+		for a := 0; a < parkingAttempts; a++ {
+			coords, _ := ws.take(2)
+			x, y := 100*uniform(coords[0]), 100*uniform(coords[1])
+			cx, cy := int(x), int(y)
+			crash := false
+			for i := max(cx-1, 0); i <= min(cx+1, 99) && !crash; i++ {
+				for j := max(cy-1, 0); j <= min(cy+1, 99) && !crash; j++ {
+					for _, c := range grid[i][j] {
+						if math.Abs(c[0]-x) <= 1 && math.Abs(c[1]-y) <= 1 {
+							crash = true
+							break
+						}
+					}
+				}
+			}
+			if !crash {
+				grid[cx][cy] = append(grid[cx][cy], point{x, y})
+				parked++
+			}
+		}
+		labels = append(labels, fmt.Sprintf("sample %d", s+1))
+		pValues = append(pValues, normalP((float64(parked)-3523)/21.9))
+		statistics = append(statistics, float64(parked))
+	}
+	return result(t, n*32, labels, pValues, statistics), nil
+}
+
+type minimumDistanceTest struct{}
+
+// NewMinimumDistanceTest returns the Minimum Distance Test: with d the smallest distance between
+// 8000 random points in a square of side 10,000, d² is approximately exponential with mean 0.995,
+// so that 1 - exp(-d² / 0.995) is uniform; 100 samples are tested.
+func NewMinimumDistanceTest() nist.Test { return minimumDistanceTest{} }
+
+func (minimumDistanceTest) Name() string    { return "Minimum Distance Test" }
+func (minimumDistanceTest) Section() string { return "13" }
+func (minimumDistanceTest) Params() map[string]any {
+	return map[string]any{"points": distancePoints, "samples": distanceSamples}
+}
+func (minimumDistanceTest) MinLength() int { return distanceSamples * distancePoints * 2 * 32 }
+
+func (t minimumDistanceTest) Run(bs *b.BitStream) (*nist.Result, error) {
+	n := distanceSamples * distancePoints * 2
+	ws, err := newWords(bs, n)
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []string
+	var pValues, statistics []float64
+	points := make([]point, distancePoints)
+	for s := 0; s < distanceSamples; s++ {
+		for i := range points {
+			coords, _ := ws.take(2)
+			points[i] = point{10000 * uniform(coords[0]), 10000 * uniform(coords[1])}
+		}
+		d := math.Sqrt(minimumSquaredDistance(points))
+		labels = append(labels, fmt.Sprintf("sample %d", s+1))
+		pValues = append(pValues, 1-math.Exp(-d*d/0.995))
+		statistics = append(statistics, d)
+	}
+	return result(t, n*32, labels, pValues, statistics), nil
+}
+
+type spheresTest struct{}
+
+// NewSpheresTest returns the 3D Spheres Test: with r the smallest distance between 4000 random
+// points in a cube of side 1000, r³ is approximately exponential with mean 30, so that
+// 1 - exp(-r³ / 30) is uniform; 20 samples are tested.
+func NewSpheresTest() nist.Test { return spheresTest{} }
+
+func (spheresTest) Name() string    { return "3D Spheres Test" }
+func (spheresTest) Section() string { return "14" }
+func (spheresTest) Params() map[string]any {
+	return map[string]any{"points": spheresPoints, "samples": spheresSamples}
+}
+func (spheresTest) MinLength() int { return spheresSamples * spheresPoints * 3 * 32 }
+
+func (t spheresTest) Run(bs *b.BitStream) (*nist.Result, error) {
+	n := spheresSamples * spheresPoints * 3
+	ws, err := newWords(bs, n)
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []string
+	var pValues, statistics []float64
+	points := make([]point3, spheresPoints)
+	for s := 0; s < spheresSamples; s++ {
+		for i := range points {
+			coords, _ := ws.take(3)
+			points[i] = point3{1000 * uniform(coords[0]), 1000 * uniform(coords[1]), 1000 * uniform(coords[2])}
+		}
+		r := math.Sqrt(minimumSquaredDistance(points))
+		labels = append(labels, fmt.Sprintf("sample %d", s+1))
+		pValues = append(pValues, 1-math.Exp(-r*r*r/30))
+		statistics = append(statistics, r)
+	}
+	return result(t, n*32, labels, pValues, statistics), nil
+}
+
+type point [2]float64
+type point3 [3]float64
+
+// minimumSquaredDistance returns the smallest squared distance between two of the points,
+// which are sorted by their first coordinate.
+func minimumSquaredDistance[P point | point3](points []P) float64 {
+	slices.SortFunc(points, func(a, b P) int {
+		switch {
+		case a[0] < b[0]:
+			return -1
+		case a[0] > b[0]:
+			return 1
+		}
+		return 0
+	})
+	best := math.Inf(1)
+	for i := range points {
+		for j := i + 1; j < len(points); j++ {
+			dx := points[j][0] - points[i][0]
+			if dx*dx >= best {
+				break
+			}
+			d := 0.0
+			for k := 0; k < len(points[i]); k++ {
+				d += (points[j][k] - points[i][k]) * (points[j][k] - points[i][k])
+			}
+			best = min(best, d)
+		}
+	}
+	return best
+}
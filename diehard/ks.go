@@ -0,0 +1,105 @@
+package diehard
+
+import (
+	"math"
+	"slices"
+)
+
+// KS returns the Kolmogorov-Smirnov statistic D of the uniformity on [0, 1] of the values, and
+// its p-value P(D_n >= D) from the exact distribution of D_n.
+func KS(values []float64) (d, p float64) {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	n := float64(len(sorted))
+	for i, v := range sorted {
+		d = max(d, float64(i+1)/n-v, v-float64(i)/n)
+	}
+	return d, 1 - kolmogorov(len(sorted), d)
+}
+
+// kolmogorov returns P(D_n < d), computed as in Marsaglia, Tsang and Wang, "Evaluating
+// Kolmogorov's distribution" (2003): the power of a matrix of size 2k - 1 for k = ⌊nd⌋ + 1,
+// with the exponent of the result kept apart to avoid underflows.
+func kolmogorov(n int, d float64) float64 {
+	if d <= 0 {
+		return 0
+	}
+	if d >= 1 {
+		return 1
+	}
+	s := d * d * float64(n)
+	if s > 7.24 || (s > 3.76 && n > 99) {
+		return 1 - 2*math.Exp(-(2.000071+0.331/math.Sqrt(float64(n))+1.409/float64(n))*s)
+	}
+
+	k := int(float64(n)*d) + 1
+	m := 2*k - 1
+	h := float64(k) - float64(n)*d
+	H := make([]float64, m*m)
+	for i := 0; i < m; i++ {
+		for j := 0; j < m; j++ {
+			if i-j+1 >= 0 {
+				H[i*m+j] = 1
+			}
+		}
+	}
+	for i := 0; i < m; i++ {
+		H[i*m] -= math.Pow(h, float64(i+1))
+		H[(m-1)*m+i] -= math.Pow(h, float64(m-i))
+	}
+	if 2*h-1 > 0 {
+		H[(m-1)*m] += math.Pow(2*h-1, float64(m))
+	}
+	for i := 0; i < m; i++ {
+		for j := 0; j < m; j++ {
+			for g := 1; g <= i-j+1; g++ {
+				H[i*m+j] /= float64(g)
+			}
+		}
+	}
+
+	Q, e := matrixPower(H, m, n)
+	s = Q[(k-1)*m+k-1]
+	for i := 1; i <= n; i++ {
+		s = s * float64(i) / float64(n)
+		if s < 1e-140 {
+			s *= 1e140
+			e -= 140
+		}
+	}
+	return s * math.Pow(10, float64(e))
+}
+
+// matrixPower returns A^n for an m × m matrix, as a matrix and a power of 10 scaling it.
+func matrixPower(A []float64, m, n int) ([]float64, int) {
+	if n == 1 {
+		return slices.Clone(A), 0
+	}
+	half, e := matrixPower(A, m, n/2)
+	B := matrixMultiply(half, half, m)
+	e *= 2
+	if n%2 == 1 {
+		B = matrixMultiply(A, B, m)
+	}
+	if B[(m/2)*m+m/2] > 1e140 {
+		for i := range B {
+			B[i] *= 1e-140
+		}
+		e += 140
+	}
+	return B, e
+}
+
+func matrixMultiply(A, B []float64, m int) []float64 {
+	C := make([]float64, m*m)
+	for i := 0; i < m; i++ {
+		for j := 0; j < m; j++ {
+			s := 0.0
+			for k := 0; k < m; k++ {
+				s += A[i*m+k] * B[k*m+j]
+			}
+			C[i*m+j] = s
+		}
+	}
+	return C
+}
@@ -0,0 +1,112 @@
+package diehard
+
+import (
+	"fmt"
+	"math/bits"
+
+	b "github.com/notJoon/drbg/bitstream"
+	nist "github.com/notJoon/drbg/nist"
+)
+
+const (
+	monkeyKeys    = 1 << 21   // the overlapping 20-bit words typed by a monkey in a sample
+	monkeyMissing = 141909.33 // the expected number of missing 20-bit words, 2^20 e^-2
+	bitstreamRuns = 20
+)
+
+// monkeyTest is a monkey test: the overlapping words of 20 bits made of letters of letterBits
+// bits typed by a monkey are counted, and the number of the 2^20 words that never occur is
+// approximately normal with mean 141909 and standard deviation sigma.
+type monkeyTest struct {
+	name       string
+	section    string
+	letterBits int
+	sigma      float64
+}
+
+// NewBitstreamTest returns the Bitstream Test: the letters are the bits of the bitstream, and
+// 20 samples of 2^21 overlapping words of 20 bits are tested.
+func NewBitstreamTest() nist.Test { return monkeyTest{"Bitstream Test", "6", 1, 428} }
+
+// NewOPSOTest returns the Overlapping-Pairs-Sparse-Occupancy Test: the letters are 10 consecutive
+// bits of each word, and the words are pairs of letters. Each of the 23 choices of the bits of
+// the letters is tested on the same 2^21 + 1 words.
+func NewOPSOTest() nist.Test { return monkeyTest{"OPSO Test", "7", 10, 290} }
+
+// NewOQSOTest returns the Overlapping-Quadruples-Sparse-Occupancy Test: the letters are 5
+// consecutive bits of each word, and the words are 4 letters long. Each of the 28 choices of
+// the bits of the letters is tested on the same 2^21 + 3 words.
+func NewOQSOTest() nist.Test { return monkeyTest{"OQSO Test", "8", 5, 295} }
+
+// NewDNATest returns the DNA Test: the letters are 2 consecutive bits of each word, and the
+// words are 10 letters long. Each of the 31 choices of the bits of the letters is tested on the
+// same 2^21 + 9 words.
+func NewDNATest() nist.Test { return monkeyTest{"DNA Test", "9", 2, 339} }
+
+func (t monkeyTest) Name() string    { return t.name }
+func (t monkeyTest) Section() string { return t.section }
+func (t monkeyTest) Params() map[string]any {
+	return map[string]any{"letter_bits": t.letterBits, "letters": 20 / t.letterBits, "keys": monkeyKeys}
+}
+func (t monkeyTest) MinLength() int { return t.words() * 32 }
+
+// words returns the number of words read by the test.
+func (t monkeyTest) words() int {
+	if t.letterBits == 1 {
+		return (bitstreamRuns*(monkeyKeys+19) + 31) / 32
+	}
+	return monkeyKeys + 20/t.letterBits - 1
+}
+
+func (t monkeyTest) Run(bs *b.BitStream) (*nist.Result, error) {
+	ws, err := newWords(bs, t.words())
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make([]uint64, 1<<20/64)
+	missing := func(letters func(i int) uint32) int {
+		clear(seen)
+		key := uint32(0)
+		for i := 0; i < monkeyKeys+20/t.letterBits-1; i++ {
+			key = (key<<t.letterBits | letters(i)) & (1<<20 - 1)
+			if i >= 20/t.letterBits-1 {
+				seen[key/64] |= 1 << (key % 64)
+			}
+		}
+		count := 0
+		for _, s := range seen {
+			count += 64 - bits.OnesCount64(s)
+		}
+		return count
+	}
+
+	var labels []string
+	var pValues, statistics []float64
+	add := func(label string, m int) {
+		z := (float64(m) - monkeyMissing) / t.sigma
+		labels = append(labels, label)
+		pValues = append(pValues, normalP(z))
+		statistics = append(statistics, float64(m))
+	}
+
+	if t.letterBits == 1 {
+		for s := 0; s < bitstreamRuns; s++ {
+			start := s * (monkeyKeys + 19)
+			m := missing(func(i int) uint32 {
+				j := start + i
+				return ws.w[j/32] >> (31 - j%32) & 1
+			})
+			add(fmt.Sprintf("sample %d", s+1), m)
+		}
+	} else {
+		for offset := 0; offset <= 32-t.letterBits; offset++ {
+			shift := 32 - t.letterBits - offset
+			m := missing(func(i int) uint32 {
+				return ws.w[i] >> shift & (1<<t.letterBits - 1)
+			})
+			add(fmt.Sprintf("bits %d-%d", offset+1, offset+t.letterBits), m)
+		}
+	}
+	return result(t, t.words()*32, labels, pValues, statistics), nil
+}
@@ -0,0 +1,206 @@
+package diehard
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	b "github.com/notJoon/drbg/bitstream"
+	nist "github.com/notJoon/drbg/nist"
+)
+
+const (
+	operm5Windows = 1000000 // the overlapping 5-tuples of a sample
+	operm5Samples = 2
+)
+
+type operm5Test struct{}
+
+// NewOPERM5Test returns the Overlapping 5-Permutation Test: each of the 1,000,000 overlapping
+// 5-tuples of consecutive words is in one of 120 orders, and the counts of the orders are
+// compared with their expected values by the quadratic form of the pseudo-inverse of their
+// covariance, a χ² statistic with 96 = 5! - 4! degrees of freedom. The covariance of the
+// overlapping tuples is computed exactly, instead of the matrix of the original battery.
+func NewOPERM5Test() nist.Test { return operm5Test{} }
+
+func (operm5Test) Name() string    { return "Overlapping 5-Permutation Test" }
+func (operm5Test) Section() string { return "2" }
+func (operm5Test) Params() map[string]any {
+	return map[string]any{"windows": operm5Windows, "samples": operm5Samples}
+}
+func (operm5Test) MinLength() int { return operm5Samples * (operm5Windows + 4) * 32 }
+
+func (t operm5Test) Run(bs *b.BitStream) (*nist.Result, error) {
+	n := operm5Samples * (operm5Windows + 4)
+	ws, err := newWords(bs, n)
+	if err != nil {
+		return nil, err
+	}
+	inverse, df := operm5Covariance()
+
+	var labels []string
+	var pValues, statistics []float64
+	for s := 0; s < operm5Samples; s++ {
+		sample, _ := ws.take(operm5Windows + 4)
+		var counts [120]float64
+		for i := 0; i < operm5Windows; i++ {
+			counts[order5(sample[i:i+5])]++
+		}
+		for i := range counts {
+			counts[i] -= operm5Windows / 120.0
+		}
+		q := 0.0
+		for i := range counts {
+			for j := range counts {
+				q += counts[i] * inverse[i*120+j] * counts[j]
+			}
+		}
+		q /= operm5Windows
+		labels = append(labels, fmt.Sprintf("sample %d", s+1))
+		pValues = append(pValues, chiSquareP(q, df))
+		statistics = append(statistics, q)
+	}
+	return result(t, n*32, labels, pValues, statistics), nil
+}
+
+// order5 returns the index in [0, 120) of the order of 5 values.
+func order5[T uint32 | int](v []T) int {
+	index := 0
+	for i := 0; i < 4; i++ {
+		smaller := 0
+		for j := i + 1; j < 5; j++ {
+			if v[j] < v[i] {
+				smaller++
+			}
+		}
+		index = index*(5-i) + smaller
+	}
+	return index
+}
+
+var (
+	operm5Once    sync.Once
+	operm5Inverse []float64
+	operm5DF      int
+)
+
+// operm5Covariance returns the pseudo-inverse of the covariance of the counts of the orders of
+// the overlapping 5-tuples, per tuple, and its rank.
+func operm5Covariance() ([]float64, int) {
+	operm5Once.Do(func() {
+		const p = 1.0 / 120
+		cov := make([]float64, 120*120)
+		for i := 0; i < 120; i++ {
+			cov[i*120+i] = p
+		}
+		for i := range cov {
+			cov[i] -= p * p
+		}
+		// the tuples starting d = 1 to 4 words apart share 5 - d words: the probabilities of
+		// their pairs of orders are counted over the orders of their 5 + d words
+		for d := 1; d <= 4; d++ {
+			joint := make([]float64, 120*120)
+			total := 0
+			permutations(5+d, func(perm []int) {
+				joint[order5(perm[:5])*120+order5(perm[d:d+5])]++
+				total++
+			})
+			for i := 0; i < 120; i++ {
+				for j := 0; j < 120; j++ {
+					cov[i*120+j] += (joint[i*120+j]+joint[j*120+i])/float64(total) - 2*p*p
+				}
+			}
+		}
+		operm5Inverse, operm5DF = pseudoInverse(cov, 120)
+	})
+	return operm5Inverse, operm5DF
+}
+
+// permutations calls f with every permutation of 0, ..., n-1 (Heap's algorithm).
+func permutations(n int, f func([]int)) {
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+	c := make([]int, n)
+	f(perm)
+	for i := 0; i < n; {
+		if c[i] < i {
+			if i%2 == 0 {
+				perm[0], perm[i] = perm[i], perm[0]
+			} else {
+				perm[c[i]], perm[i] = perm[i], perm[c[i]]
+			}
+			f(perm)
+			c[i]++
+			i = 0
+		} else {
+			c[i] = 0
+			i++
+		}
+	}
+}
+
+// pseudoInverse returns the Moore-Penrose pseudo-inverse of a symmetric n × n matrix and its
+// rank, from its eigendecomposition by the cyclic Jacobi method.
+func pseudoInverse(a []float64, n int) ([]float64, int) {
+	A := append([]float64(nil), a...)
+	V := make([]float64, n*n)
+	for i := 0; i < n; i++ {
+		V[i*n+i] = 1
+	}
+	for sweep := 0; sweep < 100; sweep++ {
+		off := 0.0
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				off += A[i*n+j] * A[i*n+j]
+			}
+		}
+		if off < 1e-30 {
+			break
+		}
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				if math.Abs(A[p*n+q]) < 1e-300 {
+					continue
+				}
+				theta := (A[q*n+q] - A[p*n+p]) / (2 * A[p*n+q])
+				t := math.Copysign(1, theta) / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := 0; k < n; k++ {
+					akp, akq := A[k*n+p], A[k*n+q]
+					A[k*n+p], A[k*n+q] = c*akp-s*akq, s*akp+c*akq
+				}
+				for k := 0; k < n; k++ {
+					apk, aqk := A[p*n+k], A[q*n+k]
+					A[p*n+k], A[q*n+k] = c*apk-s*aqk, s*apk+c*aqk
+				}
+				for k := 0; k < n; k++ {
+					vkp, vkq := V[k*n+p], V[k*n+q]
+					V[k*n+p], V[k*n+q] = c*vkp-s*vkq, s*vkp+c*vkq
+				}
+			}
+		}
+	}
+
+	largest := 0.0
+	for i := 0; i < n; i++ {
+		largest = max(largest, math.Abs(A[i*n+i]))
+	}
+	inverse := make([]float64, n*n)
+	rank := 0
+	for k := 0; k < n; k++ {
+		lambda := A[k*n+k]
+		if math.Abs(lambda) <= 1e-9*largest {
+			continue
+		}
+		rank++
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				inverse[i*n+j] += V[i*n+k] * V[j*n+k] / lambda
+			}
+		}
+	}
+	return inverse, rank
+}
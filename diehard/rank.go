@@ -0,0 +1,139 @@
+package diehard
+
+import (
+	"fmt"
+	"math"
+
+	b "github.com/notJoon/drbg/bitstream"
+	nist "github.com/notJoon/drbg/nist"
+)
+
+type rankTest struct {
+	rows, columns int
+}
+
+// NewRankTest returns the Binary Rank Test of rows × columns matrices over GF(2), with 31 × 31,
+// 32 × 32 or 6 × 8 matrices. The rows of the square matrices are the leftmost bits of successive
+// words, and the ranks of 40,000 matrices are compared with their distribution by a χ² test; the
+// lowest ranks are counted together. The rows of the 6 × 8 matrices are a byte of 6 successive
+// words: for each of the 25 choices of 8 consecutive bits, the ranks of 100,000 matrices, made of
+// the same words, are tested.
+func NewRankTest(rows, columns int) nist.Test { return rankTest{rows, columns} }
+
+func (t rankTest) Name() string {
+	return fmt.Sprintf("Binary Rank Test (%dx%d)", t.rows, t.columns)
+}
+
+func (t rankTest) Section() string {
+	switch {
+	case t.rows == 31:
+		return "3"
+	case t.rows == 32:
+		return "4"
+	}
+	return "5"
+}
+
+func (t rankTest) Params() map[string]any {
+	return map[string]any{"rows": t.rows, "columns": t.columns, "matrices": t.matrices()}
+}
+
+func (t rankTest) MinLength() int { return t.matrices() * t.rows * 32 }
+
+// matrices returns the number of matrices of a sample.
+func (t rankTest) matrices() int {
+	if t.rows == t.columns {
+		return 40000
+	}
+	return 100000
+}
+
+// offsets returns the number of choices of the bits of the rows in the words.
+func (t rankTest) offsets() int {
+	if t.rows == t.columns {
+		return 1
+	}
+	return 33 - t.columns
+}
+
+func (t rankTest) Run(bs *b.BitStream) (*nist.Result, error) {
+	n := t.matrices() * t.rows
+	ws, err := newWords(bs, n)
+	if err != nil {
+		return nil, err
+	}
+
+	// the probabilities of the ranks, the lowest ones counted together in cells expecting at
+	// least 5 matrices
+	probs := make([]float64, t.rows+1)
+	for r := range probs {
+		probs[r] = rankProbability(r, t.rows, t.columns)
+	}
+	cells, first, _ := lumpTails(probs, t.matrices())
+	expected := make([]float64, len(cells))
+	for i, p := range cells {
+		expected[i] = p * float64(t.matrices())
+	}
+
+	var labels []string
+	var pValues, statistics []float64
+	rows := make([]uint32, t.rows)
+	for offset := 0; offset < t.offsets(); offset++ {
+		shift := 32 - t.columns - offset
+		counts := make([]int, len(cells))
+		for m := 0; m < t.matrices(); m++ {
+			for i, w := range ws.w[m*t.rows : (m+1)*t.rows] {
+				rows[i] = w >> shift & (1<<t.columns - 1)
+			}
+			r := rankGF2(rows, t.columns)
+			counts[max(r-first, 0)]++
+		}
+		x := chiSquare(counts, expected)
+		label := fmt.Sprintf("%dx%d", t.rows, t.columns)
+		if t.offsets() > 1 {
+			label = fmt.Sprintf("bits %d-%d", offset+1, offset+t.columns)
+		}
+		labels = append(labels, label)
+		pValues = append(pValues, chiSquareP(x, len(cells)-1))
+		statistics = append(statistics, x)
+	}
+	return result(t, n*32, labels, pValues, statistics), nil
+}
+
+// rankProbability returns the probability that a random m × n matrix over GF(2) has rank r:
+//
+//	2^(r(n+m-r) - nm) Π_{i=0}^{r-1} (1 - 2^(i-n)) (1 - 2^(i-m)) / (1 - 2^(i-r))
+func rankProbability(r, m, n int) float64 {
+	p := math.Exp2(float64(r*(n+m-r) - n*m))
+	for i := 0; i < r; i++ {
+		p *= (1 - math.Exp2(float64(i-n))) * (1 - math.Exp2(float64(i-m))) / (1 - math.Exp2(float64(i-r)))
+	}
+	return p
+}
+
+// rankGF2 returns the rank over GF(2) of the matrix whose rows are the low columns bits of rows.
+// The rows are modified.
+func rankGF2(rows []uint32, columns int) int {
+	rank := 0
+	for c := columns - 1; c >= 0 && rank < len(rows); c-- {
+		bit := uint32(1) << c
+		pivot := -1
+		for i := rank; i < len(rows); i++ {
+			if rows[i]&bit != 0 {
+				pivot = i
+				break
+			}
+		}
+		if pivot < 0 {
+			continue
+		}
+		rows[rank], rows[pivot] = rows[pivot], rows[rank]
+		for i := rank + 1; i < len(rows); i++ {
+			if rows[i]&bit != 0 {
+				rows[i] ^= rows[rank]
+			}
+		}
+		rank++
+	}
+	return rank
+}
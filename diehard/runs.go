@@ -0,0 +1,96 @@
+package diehard
+
+import (
+	"fmt"
+
+	b "github.com/notJoon/drbg/bitstream"
+	nist "github.com/notJoon/drbg/nist"
+)
+
+const (
+	runsLength  = 10000 // the uniform floats of a sample
+	runsSamples = 10
+)
+
+// runsA and runsB are the coefficients of the statistic of the runs up of Knuth, The Art of
+// Computer Programming, Vol. 2, section 3.3.2 G.
+var (
+	runsA = [6][6]float64{
+		{4529.4, 9044.9, 13568, 18091, 22615, 27892},
+		{9044.9, 18097, 27139, 36187, 45234, 55789},
+		{13568, 27139, 40721, 54281, 67852, 83685},
+		{18091, 36187, 54281, 72414, 90470, 111580},
+		{22615, 45234, 67852, 90470, 113262, 139476},
+		{27892, 55789, 83685, 111580, 139476, 172860},
+	}
+	runsB = [6]float64{1.0 / 6, 5.0 / 24, 11.0 / 120, 19.0 / 720, 29.0 / 5040, 1.0 / 840}
+)
+
+// RunsStatistic returns Knuth's statistic of the runs up of the values, or of the runs down if
+// down is set: with R_i the number of runs of length i, and of length 6 or more for i = 6,
+//
+//	V = 1/(n-6) Σ_{i,j} (R_i - n b_i) (R_j - n b_j) a_ij
+//
+// is asymptotically χ² with 6 degrees of freedom.
+func RunsStatistic(values []float64, down bool) float64 {
+	var counts [6]float64
+	length := 1
+	for i := 1; i <= len(values); i++ {
+		if i < len(values) && (values[i] > values[i-1]) != down && values[i] != values[i-1] {
+			length++
+			continue
+		}
+		counts[min(length, 6)-1]++
+		length = 1
+	}
+	n := float64(len(values))
+	v := 0.0
+	for i := range counts {
+		for j := range counts {
+			v += (counts[i] - n*runsB[i]) * (counts[j] - n*runsB[j]) * runsA[i][j]
+		}
+	}
+	return v / (n - 6)
+}
+
+type runsTest struct{}
+
+// NewRunsTest returns the Runs Test: the runs up and the runs down of 10,000 uniform floats are
+// tested by Knuth's statistic; 10 samples are tested.
+func NewRunsTest() nist.Test { return runsTest{} }
+
+func (runsTest) Name() string    { return "Runs Up and Down Test" }
+func (runsTest) Section() string { return "17" }
+func (runsTest) Params() map[string]any {
+	return map[string]any{"length": runsLength, "samples": runsSamples}
+}
+func (runsTest) MinLength() int { return runsSamples * runsLength * 32 }
+
+func (t runsTest) Run(bs *b.BitStream) (*nist.Result, error) {
+	n := runsSamples * runsLength
+	ws, err := newWords(bs, n)
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []string
+	var pValues, statistics []float64
+	values := make([]float64, runsLength)
+	for s := 0; s < runsSamples; s++ {
+		sample, _ := ws.take(runsLength)
+		for i, w := range sample {
+			values[i] = uniform(w)
+		}
+		for _, down := range []bool{false, true} {
+			v := RunsStatistic(values, down)
+			label := fmt.Sprintf("sample %d up", s+1)
+			if down {
+				label = fmt.Sprintf("sample %d down", s+1)
+			}
+			labels = append(labels, label)
+			pValues = append(pValues, chiSquareP(v, 6))
+			statistics = append(statistics, v)
+		}
+	}
+	return result(t, n*32, labels, pValues, statistics), nil
+}
@@ -0,0 +1,106 @@
+package diehard
+
+import (
+	"math"
+	"sync"
+
+	b "github.com/notJoon/drbg/bitstream"
+	nist "github.com/notJoon/drbg/nist"
+)
+
+const (
+	squeezeSamples = 100000
+	squeezeStart   = 1 << 31
+	squeezeMax     = 100 // the largest number of steps whose probability is computed
+)
+
+type squeezeTest struct{}
+
+// NewSqueezeTest returns the Squeeze Test: starting from k = 2^31, k is replaced by ⌈kU⌉ for
+// uniform floats U until it reaches 1. The numbers of steps of 100,000 samples are compared with
+// their distribution by a χ² test, the rare counts of both tails being counted together.
+func NewSqueezeTest() nist.Test { return squeezeTest{} }
+
+func (squeezeTest) Name() string           { return "Squeeze Test" }
+func (squeezeTest) Section() string        { return "15" }
+func (squeezeTest) Params() map[string]any { return map[string]any{"samples": squeezeSamples} }
+
+// MinLength is the length needed on average, plus a margin: a sample reads 23 words on average.
+func (squeezeTest) MinLength() int { return 2400000 * 32 }
+
+func (t squeezeTest) Run(bs *b.BitStream) (*nist.Result, error) {
+	ws, err := newWords(bs, 0)
+	if err != nil {
+		return nil, err
+	}
+	cells, first, last := lumpTails(squeezeDistribution(), squeezeSamples)
+	expected := make([]float64, len(cells))
+	for i, p := range cells {
+		expected[i] = p * squeezeSamples
+	}
+
+	counts := make([]int, len(cells))
+	for s := 0; s < squeezeSamples; s++ {
+		k, steps := uint64(squeezeStart), 0
+		for k != 1 {
+			w, ok := ws.next()
+			if !ok {
+				return nil, ws.errEnd()
+			}
+			k = uint64(math.Ceil(float64(k) * uniform(w)))
+			steps++
+		}
+		counts[min(max(steps, first), last)-first]++
+	}
+	x := chiSquare(counts, expected)
+	return result(t, ws.i*32, nil, []float64{chiSquareP(x, len(cells)-1)}, []float64{x}), nil
+}
+
+var (
+	squeezeOnce  sync.Once
+	squeezeProbs []float64
+)
+
+// squeezeDistribution returns the probabilities of 0 to squeezeMax steps from 2^31 to 1, the last
+// one including the longer runs.
+//
+// From k, ⌈kU⌉ is uniform on {1, ..., k}, so that the probabilities f_k(j) of j steps from k
+// satisfy f_k(j) = 1/k Σ_{m=1}^{k} f_m(j - 1); they are computed exactly up to k = 2^16. Above,
+// the steps multiply k by uniform floats: the number of steps from 2^31 until k falls below 2^16
+// follows a Poisson law of mean 15 ln 2, and k is then uniform on {1, ..., 2^16} as after a step
+// from 2^16. The steps from 2^31 are thus the sum of a Poisson variable and of the steps from 2^16.
+func squeezeDistribution() []float64 {
+	squeezeOnce.Do(func() {
+		const k0 = 1 << 16
+		f := make([]float64, squeezeMax+1)    // f_k
+		sums := make([]float64, squeezeMax+1) // Σ_{m<k} f_m
+		f[0] = 1                              // f_1: no step
+		for k := 2; k <= k0; k++ {
+			for j := range sums {
+				sums[j] += f[j]
+			}
+			// f_k(j) = (sums(j-1) + f_k(j-1)) / k, the sum including m = k
+			f[0] = 0
+			for j := 1; j <= squeezeMax; j++ {
+				f[j] = (sums[j-1] + f[j-1]) / float64(k)
+			}
+		}
+
+		lambda := math.Log(squeezeStart / k0)
+		probs := make([]float64, squeezeMax+1)
+		poisson := math.Exp(-lambda)
+		for i := 0; i <= squeezeMax; i++ {
+			for j := 0; i+j <= squeezeMax; j++ {
+				probs[i+j] += poisson * f[j]
+			}
+			poisson *= lambda / float64(i+1)
+		}
+		total := 0.0
+		for _, p := range probs[:squeezeMax] {
+			total += p
+		}
+		probs[squeezeMax] = 1 - total
+		squeezeProbs = probs
+	})
+	return squeezeProbs
+}
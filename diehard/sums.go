@@ -0,0 +1,113 @@
+package diehard
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	b "github.com/notJoon/drbg/bitstream"
+	nist "github.com/notJoon/drbg/nist"
+)
+
+const (
+	sumsLength  = 100 // m, the uniform floats of each sum
+	sumsCount   = 100 // the overlapping sums of a sample
+	sumsSamples = 10
+)
+
+type overlappingSumsTest struct{}
+
+// NewOverlappingSumsTest returns the Overlapping Sums Test: the 100 sums S_j = U_j + ... +
+// U_{j+99} of overlapping uniform floats are approximately normal, with the covariances
+// (100 - |i - j|) / 12. They are decorrelated by the Cholesky factor of their covariance, and the
+// uniformity of the normal CDF of the results is tested by a Kolmogorov-Smirnov test; 10 samples
+// are tested.
+func NewOverlappingSumsTest() nist.Test { return overlappingSumsTest{} }
+
+func (overlappingSumsTest) Name() string    { return "Overlapping Sums Test" }
+func (overlappingSumsTest) Section() string { return "16" }
+func (overlappingSumsTest) Params() map[string]any {
+	return map[string]any{"m": sumsLength, "sums": sumsCount, "samples": sumsSamples}
+}
+func (overlappingSumsTest) MinLength() int {
+	return sumsSamples * (sumsCount + sumsLength - 1) * 32
+}
+
+func (t overlappingSumsTest) Run(bs *b.BitStream) (*nist.Result, error) {
+	n := sumsSamples * (sumsCount + sumsLength - 1)
+	ws, err := newWords(bs, n)
+	if err != nil {
+		return nil, err
+	}
+	L := sumsCholesky()
+
+	var labels []string
+	var pValues, statistics []float64
+	sums := make([]float64, sumsCount)
+	u := make([]float64, sumsCount)
+	for s := 0; s < sumsSamples; s++ {
+		sample, _ := ws.take(sumsCount + sumsLength - 1)
+		sum := 0.0
+		for i, w := range sample {
+			sum += uniform(w)
+			if i >= sumsLength {
+				sum -= uniform(sample[i-sumsLength])
+			}
+			if i >= sumsLength-1 {
+				sums[i-sumsLength+1] = sum - sumsLength/2.0
+			}
+		}
+		// solve L y = S - m/2 by forward substitution
+		for i := range sums {
+			y := sums[i]
+			for j := 0; j < i; j++ {
+				y -= L[i*sumsCount+j] * u[j]
+			}
+			u[i] = y / L[i*sumsCount+i]
+		}
+		for i, y := range u {
+			sums[i] = 0.5 * math.Erfc(-y/math.Sqrt2)
+		}
+		d, p := KS(sums)
+		labels = append(labels, fmt.Sprintf("sample %d", s+1))
+		pValues = append(pValues, p)
+		statistics = append(statistics, d)
+	}
+	return result(t, n*32, labels, pValues, statistics), nil
+}
+
+var (
+	sumsOnce sync.Once
+	sumsL    []float64
+)
+
+// sumsCholesky returns the lower triangular Cholesky factor of the covariance of the overlapping
+// sums.
+func sumsCholesky() []float64 {
+	sumsOnce.Do(func() {
+		const n = sumsCount
+		cov := func(i, j int) float64 {
+			d := i - j
+			if d < 0 {
+				d = -d
+			}
+			return float64(max(sumsLength-d, 0)) / 12
+		}
+		L := make([]float64, n*n)
+		for i := 0; i < n; i++ {
+			for j := 0; j <= i; j++ {
+				s := cov(i, j)
+				for k := 0; k < j; k++ {
+					s -= L[i*n+k] * L[j*n+k]
+				}
+				if i == j {
+					L[i*n+i] = math.Sqrt(s)
+				} else {
+					L[i*n+j] = s / L[j*n+j]
+				}
+			}
+		}
+		sumsL = L
+	})
+	return sumsL
+}
@@ -0,0 +1,220 @@
+package drbg
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// cavpVector is one COUNT of a CAVP DRBG vector file.
+type cavpVector struct {
+	count            int
+	entropy          []byte
+	nonce            []byte
+	personalization  []byte
+	reseedEntropy    []byte // nil when the vector has no reseed
+	reseedAdditional []byte
+	additional       [][]byte // one per Generate request
+	entropyPR        [][]byte // one per Generate request when prediction resistance is on
+	returned         []byte
+}
+
+// cavpSection holds the vectors following a block of [name = value] headers.
+type cavpSection struct {
+	name                 string // the header without a value, e.g. "SHA-256"
+	params               map[string]string
+	predictionResistance bool
+	vectors              []*cavpVector
+}
+
+// cavpGenerateCalls is the number of Generate requests of every CAVP DRBG vector.
+const cavpGenerateCalls = 2
+
+// readCAVP parses a CAVP .rsp file of DRBG vectors.
+func readCAVP(t *testing.T, filename string) []*cavpSection {
+	t.Helper()
+
+	file, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var sections []*cavpSection
+	var section *cavpSection
+	var vector *cavpVector
+	inHeaders := false
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<20)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			if !inHeaders {
+				section = &cavpSection{params: make(map[string]string)}
+				sections = append(sections, section)
+				inHeaders = true
+			}
+			name, value, ok := strings.Cut(line[1:len(line)-1], "=")
+			if !ok {
+				section.name = line[1 : len(line)-1]
+				continue
+			}
+			name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+			section.params[name] = value
+			if name == "PredictionResistance" {
+				section.predictionResistance = value == "True"
+			}
+			continue
+		}
+		inHeaders = false
+
+		name, value, ok := strings.Cut(line, "=")
+		if !ok || section == nil {
+			t.Fatalf("%s:%d: unexpected line %q", filename, lineNum, line)
+		}
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+
+		if name == "COUNT" {
+			count, err := strconv.Atoi(value)
+			if err != nil {
+				t.Fatalf("%s:%d: %v", filename, lineNum, err)
+			}
+			vector = &cavpVector{count: count}
+			section.vectors = append(section.vectors, vector)
+			continue
+		}
+		if vector == nil {
+			t.Fatalf("%s:%d: %s before COUNT", filename, lineNum, name)
+		}
+
+		data, err := hex.DecodeString(value)
+		if err != nil {
+			t.Fatalf("%s:%d: %v", filename, lineNum, err)
+		}
+		switch name {
+		case "EntropyInput":
+			vector.entropy = data
+		case "Nonce":
+			vector.nonce = data
+		case "PersonalizationString":
+			vector.personalization = data
+		case "EntropyInputReseed":
+			vector.reseedEntropy = data
+		case "AdditionalInputReseed":
+			vector.reseedAdditional = data
+		case "AdditionalInput":
+			vector.additional = append(vector.additional, data)
+		case "EntropyInputPR":
+			vector.entropyPR = append(vector.entropyPR, data)
+		case "ReturnedBits":
+			vector.returned = data
+		default:
+			t.Fatalf("%s:%d: unknown field %s", filename, lineNum, name)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	// a truncated vector would otherwise compare an empty output with no expected bits
+	for _, s := range sections {
+		for _, v := range s.vectors {
+			switch {
+			case v.returned == nil:
+				t.Fatalf("%s: %s COUNT = %d: no ReturnedBits", filename, s.name, v.count)
+			case len(v.additional) != cavpGenerateCalls:
+				t.Fatalf("%s: %s COUNT = %d: %d AdditionalInput, expected %d", filename, s.name, v.count, len(v.additional), cavpGenerateCalls)
+			case s.predictionResistance && len(v.entropyPR) != cavpGenerateCalls:
+				t.Fatalf("%s: %s COUNT = %d: %d EntropyInputPR, expected %d", filename, s.name, v.count, len(v.entropyPR), cavpGenerateCalls)
+			}
+		}
+	}
+	return sections
+}
+
+// instantiateFunc builds the DRBG of a section, or returns false if the section is not supported.
+type instantiateFunc func(s *cavpSection, entropy, nonce, personalization []byte, cfg Config) (*DRBG, bool, error)
+
+// runCAVP checks every vector of the files matching pattern: instantiate, reseed if the vector
+// has a reseed, then one Generate request per additional input, the output of the last one being
+// ReturnedBits. Official CAVP files can be dropped into testdata next to the bundled ones.
+func runCAVP(t *testing.T, pattern string, instantiate instantiateFunc) {
+	filenames, err := filepath.Glob(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filenames) == 0 {
+		t.Fatalf("no files match %s", pattern)
+	}
+
+	tested := 0
+	for _, filename := range filenames {
+		tested += runCAVPFile(t, filename, instantiate)
+	}
+	if tested == 0 {
+		t.Fatalf("%s: no supported vectors", pattern)
+	}
+}
+
+// runCAVPFile checks the vectors of a single file and returns how many were supported.
+func runCAVPFile(t *testing.T, filename string, instantiate instantiateFunc) int {
+	sections := readCAVP(t, filename)
+	if len(sections) == 0 {
+		t.Fatalf("%s: no vectors", filename)
+	}
+
+	tested := 0
+	for _, s := range sections {
+		for _, v := range s.vectors {
+			cfg := Config{PredictionResistance: s.predictionResistance}
+			if s.predictionResistance {
+				cfg.Entropy = bytes.NewReader(bytes.Join(v.entropyPR, nil))
+			}
+
+			d, ok, err := instantiate(s, v.entropy, v.nonce, v.personalization, cfg)
+			if !ok {
+				continue
+			}
+			name := filename + ": " + s.name + " " + strings.Join([]string{
+				"PR=" + s.params["PredictionResistance"],
+				"perso=" + s.params["PersonalizationStringLen"],
+				"add=" + s.params["AdditionalInputLen"],
+				"COUNT=" + strconv.Itoa(v.count),
+			}, " ")
+			if err != nil {
+				t.Errorf("%s: instantiate: %v", name, err)
+				continue
+			}
+
+			if v.reseedEntropy != nil {
+				if err := d.Reseed(v.reseedEntropy, v.reseedAdditional); err != nil {
+					t.Errorf("%s: reseed: %v", name, err)
+					continue
+				}
+			}
+
+			out := make([]byte, len(v.returned))
+			for i, additional := range v.additional {
+				if err := d.Generate(out, additional); err != nil {
+					t.Errorf("%s: generate %d: %v", name, i+1, err)
+					break
+				}
+			}
+			if !bytes.Equal(out, v.returned) {
+				t.Errorf("%s: got %x, expected %x", name, out, v.returned)
+			}
+			tested++
+		}
+	}
+	return tested
+}
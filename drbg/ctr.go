@@ -0,0 +1,190 @@
+package drbg
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+)
+
+// NewCTR instantiates a CTR_DRBG (SP 800-90A, section 10.2.1) using AES with a key of keyLen bytes
+// (16, 24 or 32) and a counter field of the whole block.
+//
+// With the derivation function, the entropy input needs at least security strength / 8 bytes, the nonce
+// should hold at least half as many, and inputs may have any length. Without it, the entropy input must
+// be exactly seedlen (keyLen + 16) bytes, the nonce is not used, and the personalization string and
+// additional input are at most seedlen bytes.
+func NewCTR(keyLen int, derivationFunction bool, entropy, nonce, personalization []byte, cfg Config) (*DRBG, error) {
+	switch keyLen {
+	case 16, 24, 32:
+	default:
+		return nil, fmt.Errorf("%w: AES key of %d bytes, need 16, 24 or 32", ErrInvalidInput, keyLen)
+	}
+
+	mech := &ctrDRBG{
+		keyLen: keyLen,
+		df:     derivationFunction,
+		key:    make([]byte, keyLen),
+		v:      make([]byte, aes.BlockSize),
+	}
+	seedLen := mech.seedLen()
+
+	lim := limits{strength: keyLen * 8}
+	name := fmt.Sprintf("CTR_DRBG AES-%d", keyLen*8)
+	if derivationFunction {
+		lim.minEntropy, lim.maxEntropy, lim.maxInput = keyLen, maxInputBytes, maxInputBytes
+	} else {
+		lim.minEntropy, lim.maxEntropy, lim.maxInput = seedLen, seedLen, seedLen
+		name += " no df"
+	}
+	return newDRBG(name, mech, lim, entropy, nonce, personalization, cfg)
+}
+
+// ctrDRBG is the state of a CTR_DRBG: the AES key and the counter V.
+type ctrDRBG struct {
+	keyLen int
+	df     bool // use the block cipher derivation function
+	key    []byte
+	v      []byte
+}
+
+// seedLen returns seedlen in bytes: the key and one block.
+func (d *ctrDRBG) seedLen() int {
+	return d.keyLen + aes.BlockSize
+}
+
+func (d *ctrDRBG) instantiate(entropy, nonce, personalization []byte) {
+	clear(d.key)
+	clear(d.v)
+	if d.df {
+		d.update(d.derive(entropy, nonce, personalization))
+		return
+	}
+	d.update(d.xorSeed(entropy, personalization))
+}
+
+func (d *ctrDRBG) reseed(entropy, additional []byte) {
+	if d.df {
+		d.update(d.derive(entropy, additional))
+		return
+	}
+	d.update(d.xorSeed(entropy, additional))
+}
+
+// generate is CTR_DRBG_Generate_algorithm of section 10.2.1.5.
+func (d *ctrDRBG) generate(out, additional []byte, _ uint64) {
+	provided := make([]byte, d.seedLen())
+	if len(additional) > 0 {
+		if d.df {
+			provided = d.derive(additional)
+		} else {
+			copy(provided, additional)
+		}
+		d.update(provided)
+	}
+
+	block := d.cipher()
+	var buf [aes.BlockSize]byte
+	for n := 0; n < len(out); {
+		add(d.v, []byte{0x01})
+		block.Encrypt(buf[:], d.v)
+		n += copy(out[n:], buf[:])
+	}
+	d.update(provided)
+}
+
+func (d *ctrDRBG) clear() {
+	clear(d.key)
+	clear(d.v)
+}
+
+func (d *ctrDRBG) cipher() cipher.Block {
+	block, err := aes.NewCipher(d.key)
+	if err != nil {
+		panic(err) // the key length was checked by NewCTR
+	}
+	return block
+}
+
+// update is CTR_DRBG_Update of section 10.2.1.2: it encrypts V+1, V+2... with the key,
+// XORs the seedlen bytes with provided and uses them as the new key and V.
+func (d *ctrDRBG) update(provided []byte) {
+	block := d.cipher()
+	temp := make([]byte, 0, d.seedLen()+aes.BlockSize)
+	var buf [aes.BlockSize]byte
+	for len(temp) < d.seedLen() {
+		add(d.v, []byte{0x01})
+		block.Encrypt(buf[:], d.v)
+		temp = append(temp, buf[:]...)
+	}
+	temp = temp[:d.seedLen()]
+	subtle.XORBytes(temp, temp, provided)
+
+	copy(d.key, temp[:d.keyLen])
+	copy(d.v, temp[d.keyLen:])
+}
+
+// xorSeed returns entropy XOR input, input being padded with zeros to seedlen bytes.
+func (d *ctrDRBG) xorSeed(entropy, input []byte) []byte {
+	seed := make([]byte, d.seedLen())
+	copy(seed, input)
+	subtle.XORBytes(seed, seed, entropy)
+	return seed
+}
+
+// derive is Block_Cipher_df of section 10.3.2, returning seedlen bytes derived from the
+// concatenation of inputs.
+func (d *ctrDRBG) derive(inputs ...[]byte) []byte {
+	length := 0
+	for _, in := range inputs {
+		length += len(in)
+	}
+
+	// S = L || N || input || 0x80, padded with zeros to a whole number of blocks
+	s := make([]byte, 8, 8+length+aes.BlockSize)
+	binary.BigEndian.PutUint32(s[0:], uint32(length))
+	binary.BigEndian.PutUint32(s[4:], uint32(d.seedLen()))
+	for _, in := range inputs {
+		s = append(s, in...)
+	}
+	s = append(s, 0x80)
+	for len(s)%aes.BlockSize != 0 {
+		s = append(s, 0x00)
+	}
+
+	// the key is 0x00 0x01 0x02...
+	key := make([]byte, d.keyLen)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	block, _ := aes.NewCipher(key)
+
+	temp := make([]byte, 0, d.seedLen()+aes.BlockSize)
+	iv := make([]byte, aes.BlockSize)
+	for i := uint32(0); len(temp) < d.seedLen(); i++ {
+		binary.BigEndian.PutUint32(iv, i)
+		temp = append(temp, bcc(block, iv, s)...)
+	}
+
+	block, _ = aes.NewCipher(temp[:d.keyLen])
+	x := temp[d.keyLen:d.seedLen()]
+	out := make([]byte, 0, d.seedLen()+aes.BlockSize)
+	for len(out) < d.seedLen() {
+		block.Encrypt(x, x)
+		out = append(out, x...)
+	}
+	return out[:d.seedLen()]
+}
+
+// bcc is the BCC function of section 10.3.3: the CBC-MAC of the blocks of iv || data with a zero IV.
+func bcc(block cipher.Block, iv, data []byte) []byte {
+	chain := make([]byte, aes.BlockSize)
+	subtle.XORBytes(chain, chain, iv)
+	block.Encrypt(chain, chain)
+	for i := 0; i < len(data); i += aes.BlockSize {
+		subtle.XORBytes(chain, chain, data[i:i+aes.BlockSize])
+		block.Encrypt(chain, chain)
+	}
+	return chain
+}
@@ -0,0 +1,81 @@
+package drbg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/notJoon/drbg/nist"
+)
+
+func TestCTRCAVP(t *testing.T) {
+	runCAVP(t, "testdata/CTR_DRBG*.rsp", func(s *cavpSection, entropy, nonce, personalization []byte, cfg Config) (*DRBG, bool, error) {
+		var bits int
+		var df string
+		if _, err := fmt.Sscanf(s.name, "AES-%d %s df", &bits, &df); err != nil {
+			// e.g. the TDES sections of the CAVP files
+			return nil, false, nil
+		}
+		d, err := NewCTR(bits/8, df == "use", entropy, nonce, personalization, cfg)
+		return d, true, err
+	})
+}
+
+func TestCTRInputs(t *testing.T) {
+	if _, err := NewCTR(20, true, make([]byte, 32), nil, nil, Config{}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("20-byte key: got %v, expected ErrInvalidInput", err)
+	}
+
+	// without the derivation function the entropy input is exactly seedlen bytes,
+	// and the other inputs at most seedlen bytes
+	if _, err := NewCTR(16, false, make([]byte, 16), nil, nil, Config{}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("short entropy input: got %v, expected ErrInvalidInput", err)
+	}
+	if _, err := NewCTR(16, false, make([]byte, 32), nil, make([]byte, 33), Config{}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("long personalization string: got %v, expected ErrInvalidInput", err)
+	}
+	d, err := NewCTR(16, false, make([]byte, 32), nil, make([]byte, 32), Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Generate(make([]byte, 16), make([]byte, 33)); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("long additional input: got %v, expected ErrInvalidInput", err)
+	}
+	if d.Name() != "CTR_DRBG AES-128 no df" || d.SecurityStrength() != 128 {
+		t.Errorf("got %q with strength %d, expected CTR_DRBG AES-128 no df with strength 128", d.Name(), d.SecurityStrength())
+	}
+
+	// with it, any length is accepted
+	d, err = NewCTR(32, true, make([]byte, 40), make([]byte, 16), make([]byte, 100), Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Generate(make([]byte, MaxRequestBytes), make([]byte, 100)); err != nil {
+		t.Errorf("request of MaxRequestBytes: %v", err)
+	}
+	if err := d.Generate(make([]byte, MaxRequestBytes+1), nil); !errors.Is(err, ErrRequestTooLarge) {
+		t.Errorf("large request: got %v, expected ErrRequestTooLarge", err)
+	}
+}
+
+func TestCTRSuite(t *testing.T) {
+	d, err := NewCTR(32, true, bytes.Repeat([]byte{1}, 32), bytes.Repeat([]byte{2}, 16), nil, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := ReadBits(d, 1000000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []nist.Test{nist.NewFrequencyTest(), nist.NewRunsTest(), nist.NewLongestRunOfOnesTest()} {
+		res, err := test.Run(bs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Passed() {
+			t.Errorf("%s: p-values %v on CTR_DRBG output", test.Name(), res.PValues)
+		}
+	}
+}
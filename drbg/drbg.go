@@ -0,0 +1,234 @@
+// Package drbg implements the deterministic random bit generators of NIST SP 800-90A Rev. 1,
+// so that their output can be checked against known answers and tested with the nist package.
+//
+// Every mechanism shares the same state machine: a DRBG is instantiated from an entropy input,
+// a nonce and an optional personalization string, then serves Generate requests until it must be
+// reseeded. Uninstantiate clears its internal state.
+package drbg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	b "github.com/notJoon/drbg/bitstream"
+)
+
+const (
+	// MaxRequestBytes is the largest number of bytes returned by a single Generate request
+	// (2^19 bits, Table 2 and Table 3 of SP 800-90A).
+	MaxRequestBytes = 1 << 16
+
+	// MaxReseedInterval is the largest number of Generate requests between two reseeds.
+	MaxReseedInterval = 1 << 48
+
+	// maxInputBytes bounds the entropy input, personalization string and additional input
+	// (2^35 bits in SP 800-90A, lowered to fit an int on 32-bit platforms).
+	maxInputBytes = math.MaxInt32
+)
+
+var (
+	ErrUninstantiated  = errors.New("drbg: not instantiated")
+	ErrReseedRequired  = errors.New("drbg: reseed required")
+	ErrRequestTooLarge = errors.New("drbg: request too large")
+	ErrInvalidInput    = errors.New("drbg: invalid input")
+	ErrNoEntropySource = errors.New("drbg: no entropy source")
+)
+
+// Config holds the optional settings of a DRBG.
+type Config struct {
+	// Entropy is the source of the entropy input of the reseeds done by the DRBG itself,
+	// for prediction resistance or once the reseed interval is over. Without a source,
+	// Generate returns ErrReseedRequired instead.
+	Entropy io.Reader
+
+	// PredictionResistance reseeds the DRBG from Entropy before every Generate request.
+	PredictionResistance bool
+
+	// ReseedInterval is the number of Generate requests allowed between two reseeds,
+	// 0 for MaxReseedInterval.
+	ReseedInterval uint64
+}
+
+// mechanism is the algorithm of a DRBG: it updates its internal state, while DRBG
+// checks the inputs and keeps the reseed counter.
+type mechanism interface {
+	instantiate(entropy, nonce, personalization []byte)
+	reseed(entropy, additional []byte)
+	// generate fills out, reseedCounter being the number of requests since the last reseed plus one.
+	generate(out, additional []byte, reseedCounter uint64)
+	clear()
+}
+
+// limits are the input lengths accepted by a mechanism, in bytes.
+type limits struct {
+	strength   int // security strength in bits
+	minEntropy int
+	maxEntropy int
+	maxInput   int // personalization string and additional input
+}
+
+// DRBG is an instantiated deterministic random bit generator. It implements io.Reader,
+// so that its output can be written to files or read into a bitstream.BitStream.
+// A DRBG is not safe for concurrent use.
+type DRBG struct {
+	name   string
+	mech   mechanism
+	limits limits
+	config Config
+
+	reseedInterval uint64
+	reseedCounter  uint64
+	instantiated   bool
+}
+
+func newDRBG(name string, mech mechanism, lim limits, entropy, nonce, personalization []byte, cfg Config) (*DRBG, error) {
+	d := &DRBG{name: name, mech: mech, limits: lim, config: cfg, reseedInterval: cfg.ReseedInterval}
+	if d.reseedInterval == 0 || d.reseedInterval > MaxReseedInterval {
+		d.reseedInterval = MaxReseedInterval
+	}
+	if cfg.PredictionResistance && cfg.Entropy == nil {
+		return nil, fmt.Errorf("%w: prediction resistance needs Config.Entropy", ErrNoEntropySource)
+	}
+	if err := d.checkEntropy(entropy); err != nil {
+		return nil, err
+	}
+	if err := d.checkInput("personalization string", personalization); err != nil {
+		return nil, err
+	}
+
+	mech.instantiate(entropy, nonce, personalization)
+	d.reseedCounter = 1
+	d.instantiated = true
+	return d, nil
+}
+
+// Name returns the name of the mechanism, e.g. "Hash_DRBG SHA-256".
+func (d *DRBG) Name() string {
+	return d.name
+}
+
+// SecurityStrength returns the security strength of the DRBG in bits.
+func (d *DRBG) SecurityStrength() int {
+	return d.limits.strength
+}
+
+// Reseed mixes a new entropy input and an optional additional input into the state.
+func (d *DRBG) Reseed(entropy, additional []byte) error {
+	if !d.instantiated {
+		return ErrUninstantiated
+	}
+	if err := d.checkEntropy(entropy); err != nil {
+		return err
+	}
+	if err := d.checkInput("additional input", additional); err != nil {
+		return err
+	}
+
+	d.mech.reseed(entropy, additional)
+	d.reseedCounter = 1
+	return nil
+}
+
+// Generate fills out with pseudorandom bytes, mixing the optional additional input into the state first.
+// It returns ErrRequestTooLarge if out is longer than MaxRequestBytes, and ErrReseedRequired if the reseed
+// interval is over and there is no entropy source to reseed from.
+func (d *DRBG) Generate(out, additional []byte) error {
+	if !d.instantiated {
+		return ErrUninstantiated
+	}
+	if len(out) > MaxRequestBytes {
+		return fmt.Errorf("%w: %d bytes, at most %d", ErrRequestTooLarge, len(out), MaxRequestBytes)
+	}
+	if err := d.checkInput("additional input", additional); err != nil {
+		return err
+	}
+
+	if d.config.PredictionResistance || d.reseedCounter > d.reseedInterval {
+		if d.config.Entropy == nil {
+			return ErrReseedRequired
+		}
+		if err := d.reseedFromSource(additional); err != nil {
+			return err
+		}
+		// the additional input was used by the reseed (SP 800-90A, section 9.3.1)
+		additional = nil
+	}
+
+	d.mech.generate(out, additional, d.reseedCounter)
+	d.reseedCounter++
+	return nil
+}
+
+// Read fills p with pseudorandom bytes, split into requests of at most MaxRequestBytes.
+func (d *DRBG) Read(p []byte) (int, error) {
+	for n := 0; n < len(p); {
+		chunk := p[n:]
+		if len(chunk) > MaxRequestBytes {
+			chunk = chunk[:MaxRequestBytes]
+		}
+		if err := d.Generate(chunk, nil); err != nil {
+			return n, err
+		}
+		n += len(chunk)
+	}
+	return len(p), nil
+}
+
+// Uninstantiate clears the internal state. Later requests return ErrUninstantiated.
+func (d *DRBG) Uninstantiate() {
+	d.mech.clear()
+	d.reseedCounter = 0
+	d.instantiated = false
+}
+
+func (d *DRBG) reseedFromSource(additional []byte) error {
+	entropy := make([]byte, d.limits.minEntropy)
+	if _, err := io.ReadFull(d.config.Entropy, entropy); err != nil {
+		return fmt.Errorf("drbg: reading entropy input: %w", err)
+	}
+	return d.Reseed(entropy, additional)
+}
+
+func (d *DRBG) checkEntropy(entropy []byte) error {
+	if len(entropy) < d.limits.minEntropy || len(entropy) > d.limits.maxEntropy {
+		if d.limits.minEntropy == d.limits.maxEntropy {
+			return fmt.Errorf("%w: entropy input of %d bytes, need %d", ErrInvalidInput, len(entropy), d.limits.minEntropy)
+		}
+		return fmt.Errorf("%w: entropy input of %d bytes, need at least %d", ErrInvalidInput, len(entropy), d.limits.minEntropy)
+	}
+	return nil
+}
+
+func (d *DRBG) checkInput(name string, input []byte) error {
+	if len(input) > d.limits.maxInput {
+		return fmt.Errorf("%w: %s of %d bytes, at most %d", ErrInvalidInput, name, len(input), d.limits.maxInput)
+	}
+	return nil
+}
+
+// ReadBits reads n bits from r, e.g. a DRBG, into a BitStream that can be given to the nist tests.
+func ReadBits(r io.Reader, n int) (*b.BitStream, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("%w: negative number of bits %d", ErrInvalidInput, n)
+	}
+	data := make([]byte, (n+7)/8)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return b.NewBitStream(data).Slice(0, n)
+}
+
+// add sets x to (x + y) mod 2^(8 len(x)), both being big-endian integers.
+func add(x, y []byte) {
+	var carry uint16
+	for i, j := len(x)-1, len(y)-1; i >= 0; i, j = i-1, j-1 {
+		sum := uint16(x[i]) + carry
+		if j >= 0 {
+			sum += uint16(y[j])
+		}
+		x[i] = byte(sum)
+		carry = sum >> 8
+	}
+}
@@ -0,0 +1,115 @@
+package drbg
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+)
+
+// NewHash instantiates a Hash_DRBG (SP 800-90A, section 10.1.1) using the hash function h,
+// e.g. sha256.New or sha512.New. The entropy input needs at least security strength / 8 bytes;
+// the nonce should hold at least half as many.
+func NewHash(h func() hash.Hash, entropy, nonce, personalization []byte, cfg Config) (*DRBG, error) {
+	mech := &hashDRBG{h: h()}
+	size := mech.h.Size()
+
+	// seedlen is 440 bits for the hash functions with 512-bit blocks, 888 bits for those with 1024-bit blocks
+	seedLen := 55
+	if mech.h.BlockSize() == 128 {
+		seedLen = 111
+	}
+	mech.v = make([]byte, seedLen)
+	mech.c = make([]byte, seedLen)
+
+	lim := limits{strength: hashStrength(size), maxEntropy: maxInputBytes, maxInput: maxInputBytes}
+	lim.minEntropy = lim.strength / 8
+	return newDRBG(fmt.Sprintf("Hash_DRBG %s", hashName(size, mech.h.BlockSize())), mech, lim, entropy, nonce, personalization, cfg)
+}
+
+// hashStrength returns the highest security strength supported by a hash function
+// with an output of size bytes (SP 800-57 Part 1, Table 3).
+func hashStrength(size int) int {
+	switch {
+	case size <= 20:
+		return 128
+	case size <= 28:
+		return 192
+	}
+	return 256
+}
+
+// hashName names the SHA-1 and SHA-2 functions by their output and block sizes.
+func hashName(size, blockSize int) string {
+	switch {
+	case size == 20:
+		return "SHA-1"
+	case blockSize == 128 && size < 48:
+		return fmt.Sprintf("SHA-512/%d", size*8)
+	}
+	return fmt.Sprintf("SHA-%d", size*8)
+}
+
+// hashDRBG is the state of a Hash_DRBG: the value V and the constant C of seedlen bits.
+type hashDRBG struct {
+	h    hash.Hash
+	v, c []byte
+}
+
+func (d *hashDRBG) instantiate(entropy, nonce, personalization []byte) {
+	d.hashDF(d.v, entropy, nonce, personalization)
+	d.hashDF(d.c, []byte{0x00}, d.v)
+}
+
+func (d *hashDRBG) reseed(entropy, additional []byte) {
+	v := make([]byte, len(d.v))
+	d.hashDF(v, []byte{0x01}, d.v, entropy, additional)
+	copy(d.v, v)
+	d.hashDF(d.c, []byte{0x00}, d.v)
+}
+
+// generate is Hash_DRBG_Generate_algorithm of section 10.1.1.4.
+func (d *hashDRBG) generate(out, additional []byte, reseedCounter uint64) {
+	if len(additional) > 0 {
+		add(d.v, d.sum([]byte{0x02}, d.v, additional))
+	}
+
+	// Hashgen: hash V, V+1, V+2... until out is full
+	data := make([]byte, len(d.v))
+	copy(data, d.v)
+	for n := 0; n < len(out); {
+		n += copy(out[n:], d.sum(data))
+		add(data, []byte{0x01})
+	}
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], reseedCounter)
+	h := d.sum([]byte{0x03}, d.v)
+	add(d.v, h)
+	add(d.v, d.c)
+	add(d.v, counter[:])
+}
+
+func (d *hashDRBG) clear() {
+	clear(d.v)
+	clear(d.c)
+}
+
+// sum returns the hash of the concatenation of inputs.
+func (d *hashDRBG) sum(inputs ...[]byte) []byte {
+	d.h.Reset()
+	for _, in := range inputs {
+		d.h.Write(in)
+	}
+	return d.h.Sum(nil)
+}
+
+// hashDF is the Hash_df derivation function of section 10.3.1: it fills out with
+// the hashes of counter || len(out) in bits || inputs, for counter = 1, 2...
+func (d *hashDRBG) hashDF(out []byte, inputs ...[]byte) {
+	var prefix [5]byte
+	binary.BigEndian.PutUint32(prefix[1:], uint32(len(out)*8))
+	for n := 0; n < len(out); {
+		prefix[0]++
+		n += copy(out[n:], d.sum(append([][]byte{prefix[:]}, inputs...)...))
+	}
+}
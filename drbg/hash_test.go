@@ -0,0 +1,157 @@
+package drbg
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"hash"
+	"testing"
+
+	"github.com/notJoon/drbg/nist"
+)
+
+var hashFunctions = map[string]func() hash.Hash{
+	"SHA-1":       sha1.New,
+	"SHA-224":     sha256.New224,
+	"SHA-256":     sha256.New,
+	"SHA-384":     sha512.New384,
+	"SHA-512":     sha512.New,
+	"SHA-512/224": sha512.New512_224,
+	"SHA-512/256": sha512.New512_256,
+}
+
+func TestHashCAVP(t *testing.T) {
+	runCAVP(t, "testdata/Hash_DRBG*.rsp", func(s *cavpSection, entropy, nonce, personalization []byte, cfg Config) (*DRBG, bool, error) {
+		h, ok := hashFunctions[s.name]
+		if !ok {
+			return nil, false, nil
+		}
+		d, err := NewHash(h, entropy, nonce, personalization, cfg)
+		return d, true, err
+	})
+}
+
+func TestHashName(t *testing.T) {
+	for name, h := range hashFunctions {
+		d, err := NewHash(h, make([]byte, 32), make([]byte, 16), nil, Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Name() != "Hash_DRBG "+name {
+			t.Errorf("Name() = %q, expected %q", d.Name(), "Hash_DRBG "+name)
+		}
+	}
+}
+
+func newTestHash(t *testing.T, cfg Config) *DRBG {
+	t.Helper()
+	d, err := NewHash(sha256.New, bytes.Repeat([]byte{1}, 32), bytes.Repeat([]byte{2}, 16), []byte("test"), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestStateMachine(t *testing.T) {
+	if _, err := NewHash(sha256.New, make([]byte, 31), nil, nil, Config{}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("short entropy input: got %v, expected ErrInvalidInput", err)
+	}
+	if _, err := NewHash(sha256.New, make([]byte, 32), nil, nil, Config{PredictionResistance: true}); !errors.Is(err, ErrNoEntropySource) {
+		t.Errorf("prediction resistance without source: got %v, expected ErrNoEntropySource", err)
+	}
+
+	d := newTestHash(t, Config{ReseedInterval: 2})
+	if d.SecurityStrength() != 256 {
+		t.Errorf("SecurityStrength() = %d, expected 256", d.SecurityStrength())
+	}
+	if err := d.Generate(make([]byte, MaxRequestBytes+1), nil); !errors.Is(err, ErrRequestTooLarge) {
+		t.Errorf("large request: got %v, expected ErrRequestTooLarge", err)
+	}
+
+	out := make([]byte, 16)
+	for i := 0; i < 2; i++ {
+		if err := d.Generate(out, nil); err != nil {
+			t.Fatalf("request %d: %v", i+1, err)
+		}
+	}
+	if err := d.Generate(out, nil); !errors.Is(err, ErrReseedRequired) {
+		t.Errorf("request after the reseed interval: got %v, expected ErrReseedRequired", err)
+	}
+	if err := d.Reseed(make([]byte, 32), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Generate(out, nil); err != nil {
+		t.Errorf("request after reseed: %v", err)
+	}
+
+	d.Uninstantiate()
+	if err := d.Generate(out, nil); !errors.Is(err, ErrUninstantiated) {
+		t.Errorf("request after uninstantiate: got %v, expected ErrUninstantiated", err)
+	}
+	if err := d.Reseed(make([]byte, 32), nil); !errors.Is(err, ErrUninstantiated) {
+		t.Errorf("reseed after uninstantiate: got %v, expected ErrUninstantiated", err)
+	}
+}
+
+func TestAutomaticReseed(t *testing.T) {
+	// with an entropy source, the DRBG reseeds itself once the interval is over
+	entropy := bytes.Repeat([]byte{3}, 32)
+	d := newTestHash(t, Config{ReseedInterval: 1, Entropy: bytes.NewReader(entropy)})
+	expected := newTestHash(t, Config{})
+
+	got, want := make([]byte, 32), make([]byte, 32)
+	for i := 0; i < 2; i++ {
+		if err := d.Generate(got, []byte("add")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected.Generate(want, []byte("add"))
+	expected.Reseed(entropy, []byte("add"))
+	expected.Generate(want, nil)
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %x, expected %x", got, want)
+	}
+
+	// the source is empty now
+	if err := d.Generate(got, nil); err == nil {
+		t.Error("expected an error once the entropy source is exhausted")
+	}
+}
+
+func TestRead(t *testing.T) {
+	// Read splits large reads into requests of MaxRequestBytes
+	d := newTestHash(t, Config{})
+	got := make([]byte, MaxRequestBytes+100)
+	if n, err := d.Read(got); err != nil || n != len(got) {
+		t.Fatalf("Read() = %d, %v", n, err)
+	}
+
+	expected := newTestHash(t, Config{})
+	want := make([]byte, len(got))
+	expected.Generate(want[:MaxRequestBytes], nil)
+	expected.Generate(want[MaxRequestBytes:], nil)
+	if !bytes.Equal(got, want) {
+		t.Error("Read() differs from the Generate requests")
+	}
+}
+
+func TestReadBits(t *testing.T) {
+	bs, err := ReadBits(newTestHash(t, Config{}), 100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bs.Len() != 100000 {
+		t.Fatalf("Len() = %d, expected 100000", bs.Len())
+	}
+
+	p, isRandom, err := nist.FrequencyTest(bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isRandom {
+		t.Errorf("frequency test p-value %f on Hash_DRBG output", p)
+	}
+}
@@ -0,0 +1,87 @@
+package drbg
+
+import (
+	"crypto/hmac"
+	"fmt"
+	"hash"
+)
+
+// NewHMAC instantiates an HMAC_DRBG (SP 800-90A, section 10.1.2) using HMAC with the hash function h,
+// e.g. sha256.New or sha512.New. The entropy input needs at least security strength / 8 bytes;
+// the nonce should hold at least half as many.
+func NewHMAC(h func() hash.Hash, entropy, nonce, personalization []byte, cfg Config) (*DRBG, error) {
+	probe := h()
+	size := probe.Size()
+	mech := &hmacDRBG{h: h, k: make([]byte, size), v: make([]byte, size)}
+
+	lim := limits{strength: hashStrength(size), maxEntropy: maxInputBytes, maxInput: maxInputBytes}
+	lim.minEntropy = lim.strength / 8
+	return newDRBG(fmt.Sprintf("HMAC_DRBG %s", hashName(size, probe.BlockSize())), mech, lim, entropy, nonce, personalization, cfg)
+}
+
+// hmacDRBG is the state of an HMAC_DRBG: the key K and the value V of outlen bits.
+type hmacDRBG struct {
+	h    func() hash.Hash
+	k, v []byte
+}
+
+func (d *hmacDRBG) instantiate(entropy, nonce, personalization []byte) {
+	for i := range d.k {
+		d.k[i] = 0x00
+		d.v[i] = 0x01
+	}
+	d.update(entropy, nonce, personalization)
+}
+
+func (d *hmacDRBG) reseed(entropy, additional []byte) {
+	d.update(entropy, additional)
+}
+
+// generate is HMAC_DRBG_Generate_algorithm of section 10.1.2.5.
+func (d *hmacDRBG) generate(out, additional []byte, _ uint64) {
+	if len(additional) > 0 {
+		d.update(additional)
+	}
+
+	mac := hmac.New(d.h, d.k)
+	for n := 0; n < len(out); {
+		mac.Reset()
+		mac.Write(d.v)
+		d.v = mac.Sum(d.v[:0])
+		n += copy(out[n:], d.v)
+	}
+	d.update(additional)
+}
+
+func (d *hmacDRBG) clear() {
+	clear(d.k)
+	clear(d.v)
+}
+
+// update is HMAC_DRBG_Update of section 10.1.2.2, the provided data being the concatenation of inputs.
+func (d *hmacDRBG) update(inputs ...[]byte) {
+	provided := 0
+	for _, in := range inputs {
+		provided += len(in)
+	}
+
+	for round := byte(0x00); round <= 0x01; round++ {
+		// K = HMAC(K, V || round || provided data)
+		mac := hmac.New(d.h, d.k)
+		mac.Write(d.v)
+		mac.Write([]byte{round})
+		for _, in := range inputs {
+			mac.Write(in)
+		}
+		d.k = mac.Sum(d.k[:0])
+
+		// V = HMAC(K, V)
+		mac = hmac.New(d.h, d.k)
+		mac.Write(d.v)
+		d.v = mac.Sum(d.v[:0])
+
+		if provided == 0 {
+			return
+		}
+	}
+}
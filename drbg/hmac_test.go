@@ -0,0 +1,83 @@
+package drbg
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/notJoon/drbg/nist"
+)
+
+func TestHMACCAVP(t *testing.T) {
+	runCAVP(t, "testdata/HMAC_DRBG*.rsp", func(s *cavpSection, entropy, nonce, personalization []byte, cfg Config) (*DRBG, bool, error) {
+		h, ok := hashFunctions[s.name]
+		if !ok {
+			return nil, false, nil
+		}
+		d, err := NewHMAC(h, entropy, nonce, personalization, cfg)
+		return d, true, err
+	})
+}
+
+func TestHMACName(t *testing.T) {
+	d, err := NewHMAC(sha512.New384, make([]byte, 32), make([]byte, 16), nil, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Name() != "HMAC_DRBG SHA-384" || d.SecurityStrength() != 256 {
+		t.Errorf("got %q with strength %d, expected HMAC_DRBG SHA-384 with strength 256", d.Name(), d.SecurityStrength())
+	}
+}
+
+func TestHMACStateMachine(t *testing.T) {
+	d, err := NewHMAC(sha256.New, bytes.Repeat([]byte{1}, 32), bytes.Repeat([]byte{2}, 16), []byte("perso"), Config{ReseedInterval: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := make([]byte, 64)
+	if err := d.Generate(out, []byte("additional")); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Generate(out, nil); !errors.Is(err, ErrReseedRequired) {
+		t.Errorf("request after the reseed interval: got %v, expected ErrReseedRequired", err)
+	}
+	if err := d.Reseed(bytes.Repeat([]byte{3}, 16), nil); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("short reseed entropy input: got %v, expected ErrInvalidInput", err)
+	}
+	if err := d.Reseed(bytes.Repeat([]byte{3}, 32), []byte("additional")); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Generate(out, nil); err != nil {
+		t.Errorf("request after reseed: %v", err)
+	}
+
+	d.Uninstantiate()
+	if _, err := d.Read(out); !errors.Is(err, ErrUninstantiated) {
+		t.Errorf("read after uninstantiate: got %v, expected ErrUninstantiated", err)
+	}
+}
+
+func TestHMACReader(t *testing.T) {
+	d, err := NewHMAC(sha256.New, bytes.Repeat([]byte{1}, 32), bytes.Repeat([]byte{2}, 16), nil, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r io.Reader = d
+	bs, err := ReadBits(r, 1000000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := nist.NewRunsTest().Run(bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Pass(0) {
+		t.Errorf("runs test p-value %f on HMAC_DRBG output", res.PValues[0])
+	}
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/jedib0t/go-pretty/table"
+
+	stream "github.com/notJoon/drbg/bitstream"
+	"github.com/notJoon/drbg/entropy"
+	"github.com/notJoon/drbg/generator"
+)
+
+// assessEntropy implements the entropy subcommand: it estimates the min-entropy per sample of
+// the samples of a file or a generator (SP 800-90B), with the IID track when -iid is given and
+// the IID tests pass, and the non-IID track otherwise. With -restart-files or -restart-file it
+// then runs the restart tests with that min-entropy, or the one given by -min-entropy.
+func assessEntropy(args []string) {
+	flags := flag.NewFlagSet("entropy", flag.ExitOnError)
+	filename := flags.String("file", "", "File containing the samples")
+	inputFormat := flags.String("input-format", "decimal", "Encoding of the file: decimal (one integer per line), raw (binary), ascii ('0' and '1' characters) or hex")
+	wordSize := flags.Int("word-size", 8, "Width in bits of each integer of a decimal file: 8, 16, 32 or 64")
+	endian := flags.String("endian", "big", "Byte order of the integers of a decimal file: big or little")
+	lowBits := flags.Int("low-bits", 0, "Use only the low bits of each integer of a decimal file. 0 uses the whole word")
+	gen := flags.String("gen", "", "Assess the output of this generator instead of a file (see generate -list)")
+	seedHex := flags.String("seed", "", "Seed of -gen in hexadecimal. If empty, the reference seed of the generator, or a random seed that is printed")
+	genBits := flags.Int("bits", 8000000, "Number of bits to draw from -gen")
+
+	sampleBits := flags.Int("sample-bits", 8, "Width in bits of each sample, from 1 to 8. The input is split into samples of this width")
+	byteSamples := flags.Bool("byte-samples", false, "Each byte of the input holds one sample in its low -sample-bits bits,\nas in the files of the SP 800-90B reference implementation")
+
+	iid := flags.Bool("iid", false, "Run the IID tests, and use the IID track if they pass")
+	permutations := flags.Int("permutations", 10000, "Number of shuffles of the permutation tests")
+	shuffleSeed := flags.Int64("shuffle-seed", 0, "Seed of the shuffles of the permutation tests")
+	workers := flags.Int("workers", 0, "Number of shuffles tested concurrently. 0 uses every CPU")
+
+	restartFiles := flags.String("restart-files", "", "Run the restart tests on the files matching this pattern, one file per restart in lexical order")
+	restartFile := flags.String("restart-file", "", "Run the restart tests on this file, holding the samples of -restarts restarts")
+	restarts := flags.Int("restarts", entropy.RestartCount, "Number of restarts in -restart-file")
+	layout := flags.String("layout", "rows", "Layout of -restart-file: rows (the samples of each restart are consecutive)\nor columns (the i-th samples of every restart are consecutive)")
+	minEntropy := flags.Float64("min-entropy", 0, "Min-entropy per sample H_I of the sequential samples for the restart tests.\n0 assesses it on -file or -gen")
+	flags.Parse(args)
+
+	in := sampleInput{
+		wordSize:    *wordSize,
+		endian:      *endian,
+		lowBits:     *lowBits,
+		sampleBits:  *sampleBits,
+		byteSamples: *byteSamples,
+	}
+	var err error
+	if in.format, err = stream.ParseFormat(*inputFormat); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *endian != "big" && *endian != "little" {
+		fmt.Printf("Error: unknown byte order %q (expected big or little)\n", *endian)
+		os.Exit(1)
+	}
+	if *filename != "" && *gen != "" {
+		fmt.Println("Error: -file cannot be combined with -gen")
+		os.Exit(1)
+	}
+	if *restartFiles != "" && *restartFile != "" {
+		fmt.Println("Error: -restart-files cannot be combined with -restart-file")
+		os.Exit(1)
+	}
+	if *layout != "rows" && *layout != "columns" {
+		fmt.Printf("Error: unknown layout %q (expected rows or columns)\n", *layout)
+		os.Exit(1)
+	}
+	restart := *restartFiles != "" || *restartFile != ""
+	sequential := *filename != "" || *gen != ""
+	if !sequential && !(restart && *minEntropy > 0) {
+		fmt.Println("Error: No file specified")
+		os.Exit(1)
+	}
+
+	// the rows of the restart matrix are read first, so that a bad layout fails early
+	var rows []*entropy.Samples
+	switch {
+	case *restartFiles != "":
+		rows, err = in.readRestartFiles(*restartFiles)
+	case *restartFile != "":
+		var s *entropy.Samples
+		if s, err = in.readFile(*restartFile); err == nil {
+			rows, err = entropy.RestartRows(s, *restarts, *layout == "columns")
+		}
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	assessment := entropy.NonIID
+	hInitial := *minEntropy
+	if sequential {
+		source := *filename
+		var samples *entropy.Samples
+		if *gen != "" {
+			var g generator.Generator
+			var bs *stream.BitStream
+			if g, source, err = seededGenerator(*gen, *seedHex); err == nil {
+				if bs, err = generator.ReadBits(g, *genBits); err == nil {
+					samples, err = in.samples(bs)
+				}
+			}
+		} else {
+			samples, err = in.readFile(*filename)
+		}
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("%s: %d samples of %d bits\n", source, samples.Len(), samples.Bits)
+
+		if *iid {
+			res, err := entropy.CheckIID(samples, entropy.IIDOptions{
+				Permutations: *permutations,
+				Seed:         *shuffleSeed,
+				Workers:      *workers,
+			})
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			writeIIDResult(os.Stdout, res)
+			if res.IID {
+				assessment = entropy.IID
+			} else {
+				fmt.Println("The IID assumption is rejected: using the non-IID track")
+			}
+		}
+
+		a, err := assessment(samples)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		writeAssessment(os.Stdout, a)
+		if hInitial == 0 {
+			hInitial = a.MinEntropy
+		}
+	}
+
+	if !restart {
+		return
+	}
+	fmt.Printf("Restart tests: %d restarts of %d samples of %d bits\n", len(rows), rows[0].Len(), rows[0].Bits)
+	res, err := entropy.Restart(rows, hInitial, assessment)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	writeRestartResult(os.Stdout, res)
+}
+
+// sampleInput is how the entropy subcommand reads samples from files.
+type sampleInput struct {
+	format      stream.Format
+	wordSize    int
+	endian      string
+	lowBits     int
+	sampleBits  int
+	byteSamples bool
+}
+
+// readFile reads the samples of a file.
+func (in *sampleInput) readFile(filename string) (*entropy.Samples, error) {
+	var bs *stream.BitStream
+	var err error
+	if in.format != stream.FormatDecimal {
+		bs, err = stream.FromFileFormat(filename, in.format)
+	} else {
+		bs, err = stream.FromFileIntegers(filename, stream.IntegerOptions{
+			WordSize:     in.wordSize,
+			LittleEndian: in.endian == "little",
+			LowBits:      in.lowBits,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return in.samples(bs)
+}
+
+// samples splits bits into samples of sampleBits bits, or keeps the low bits of each byte with
+// byteSamples.
+func (in *sampleInput) samples(bs *stream.BitStream) (*entropy.Samples, error) {
+	if !in.byteSamples {
+		return entropy.FromBitStream(bs, in.sampleBits)
+	}
+	if in.sampleBits < 1 || in.sampleBits > 8 {
+		return nil, fmt.Errorf("%w, got %d", entropy.ErrInvalidSampleSize, in.sampleBits)
+	}
+	data := bs.Bytes()[:bs.Len()/8]
+	for i := range data {
+		data[i] &= 1<<in.sampleBits - 1
+	}
+	return entropy.NewSamples(data, in.sampleBits)
+}
+
+// readRestartFiles reads the files matching pattern, in lexical order, as the rows of a restart
+// matrix.
+func (in *sampleInput) readRestartFiles(pattern string) ([]*entropy.Samples, error) {
+	names, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no file matches %q", pattern)
+	}
+	slices.Sort(names)
+	rows := make([]*entropy.Samples, len(names))
+	for i, name := range names {
+		if rows[i], err = in.readFile(name); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return rows, nil
+}
+
+// writeIIDResult renders the outcome of the IID tests as a table.
+func writeIIDResult(w io.Writer, res *entropy.IIDResult) {
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.AppendHeader(table.Row{"SP 800-90B IID Test", "Statistic", "Outcome", "Result"})
+
+	for _, st := range res.Statistics {
+		outcome := fmt.Sprintf("C0 = %d, C1 = %d", st.Greater, st.Equal)
+		t.AppendRow(table.Row{st.Name, fmt.Sprintf("%.4f", st.T), outcome, passFail(st.Pass)})
+	}
+	for _, cs := range []*entropy.ChiSquareResult{res.Independence, res.GoodnessOfFit} {
+		if cs.Err != nil {
+			t.AppendRow(table.Row{cs.Name, "-", "-", fmt.Sprintf("Skipped (%v)", cs.Err)})
+			continue
+		}
+		outcome := fmt.Sprintf("df = %d, p = %.4f", cs.DF, cs.P)
+		t.AppendRow(table.Row{cs.Name, fmt.Sprintf("%.4f", cs.T), outcome, passFail(cs.Pass)})
+	}
+	outcome := fmt.Sprintf("p_col = %.6f, P = %.4f", res.LRS.PCol, res.LRS.P)
+	t.AppendRow(table.Row{"Longest Repeated Substring", res.LRS.W, outcome, passFail(res.LRS.Pass)})
+
+	t.AppendFooter(table.Row{"Permutations", res.Permutations, "IID", passFail(res.IID)})
+	t.Render()
+}
+
+// writeAssessment renders the estimates of an assessment and the assessed min-entropy.
+func writeAssessment(w io.Writer, a *entropy.Assessment) {
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.AppendHeader(table.Row{"SP 800-90B Estimator", "Section", "Data", "p", "Min-entropy"})
+
+	appendEstimates := func(estimates []*entropy.Estimate, data string) {
+		for _, e := range estimates {
+			if e.Err != nil {
+				t.AppendRow(table.Row{e.Name, e.Section, data, "-", fmt.Sprintf("Skipped (%v)", e.Err)})
+				continue
+			}
+			t.AppendRow(table.Row{e.Name, e.Section, data, fmt.Sprintf("%.6f", e.P), fmt.Sprintf("%.6f", e.MinEntropy)})
+		}
+	}
+	appendEstimates(a.Original, "samples")
+	appendEstimates(a.Bitstring, "bitstring")
+
+	t.AppendFooter(table.Row{"H_original", "", "", "", fmt.Sprintf("%.6f", a.HOriginal)})
+	if a.Bits > 1 {
+		t.AppendFooter(table.Row{"H_bitstring", "", "", "", fmt.Sprintf("%.6f", a.HBitstring)})
+	}
+	t.AppendFooter(table.Row{"Min-entropy per sample", "", "", "", fmt.Sprintf("%.6f", a.MinEntropy)})
+	t.Render()
+}
+
+// writeRestartResult renders the outcome of the restart tests.
+func writeRestartResult(w io.Writer, res *entropy.RestartResult) {
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.AppendHeader(table.Row{"SP 800-90B Restart Test", "Statistic", "Outcome", "Result"})
+
+	t.AppendRow(table.Row{"Sanity Check (rows)", res.RowMax, fmt.Sprintf("cutoff %d, alpha = %.3g", res.RowCutoff, res.Alpha), passFail(res.RowMax <= res.RowCutoff)})
+	t.AppendRow(table.Row{"Sanity Check (columns)", res.ColumnMax, fmt.Sprintf("cutoff %d, alpha = %.3g", res.ColumnCutoff, res.Alpha), passFail(res.ColumnMax <= res.ColumnCutoff)})
+	half := fmt.Sprintf("H_I / 2 = %.6f", res.HInitial/2)
+	if res.RowAssessment != nil {
+		t.AppendRow(table.Row{"Row Min-entropy H_r", fmt.Sprintf("%.6f", res.RowAssessment.MinEntropy), half, passFail(res.RowAssessment.MinEntropy >= res.HInitial/2)})
+		t.AppendRow(table.Row{"Column Min-entropy H_c", fmt.Sprintf("%.6f", res.ColumnAssessment.MinEntropy), half, passFail(res.ColumnAssessment.MinEntropy >= res.HInitial/2)})
+	}
+
+	final := "-"
+	if res.Pass {
+		final = fmt.Sprintf("%.6f", res.MinEntropy)
+	}
+	t.AppendFooter(table.Row{"Min-entropy per sample", final, fmt.Sprintf("H_I = %.6f", res.HInitial), passFail(res.Pass)})
+	t.Render()
+}
+
+func passFail(pass bool) string {
+	if pass {
+		return "Pass"
+	}
+	return "Fail"
+}
@@ -0,0 +1,530 @@
+package entropy
+
+// This file implements the bzip2 compressor used by the compression statistic of the
+// permutation tests (section 5.1.11). The standard library only decompresses bzip2, and the
+// statistic is the length of the compressed data, so the encoder follows libbzip2 1.0.x step
+// by step, including its choice of Huffman tables, and produces the same number of bytes.
+
+// Parameters of the bzip2 format and of libbzip2.
+const (
+	bzRunA         = 0   // the MTF symbols coding runs of zeros
+	bzRunB         = 1   //
+	bzGroupSize    = 50  // the number of symbols coded with the same table
+	bzMaxGroups    = 6   //
+	bzIterations   = 4   // the refinements of the coding tables
+	bzMaxCodeLen   = 17  //
+	bzLesserICost  = 0   // the initial lengths of the symbols of a table
+	bzGreaterICost = 15  //
+	bzMaxAlpha     = 258 // 256 byte values, RUNA/RUNB and the end of block
+)
+
+// bzip2Compress compresses data as libbzip2 does with the given block size, from 1 to 9
+// (×100 kB), and returns the .bz2 stream.
+func bzip2Compress(data []byte, blockSize100k int) []byte {
+	w := &bzWriter{}
+	w.bytes('B', 'Z', 'h', byte('0'+blockSize100k))
+
+	// the first run-length encoding, from bzlib.c: runs of 4 to 255 equal bytes are written as
+	// 4 bytes and a count, and a pending run is carried over to the next block
+	blockMax := 100000*blockSize100k - 19
+	block := make([]byte, 0, blockMax+5)
+	var combined uint32
+	crc := bzCRCInit
+	run, runLen := 256, 0 // run is the byte of the pending run, 256 if none
+	flushRun := func() {
+		if run < 256 {
+			for i := 0; i < runLen; i++ {
+				crc = bzCRCUpdate(crc, byte(run))
+			}
+			for i := 0; i < min(runLen, 4); i++ {
+				block = append(block, byte(run))
+			}
+			if runLen >= 4 {
+				block = append(block, byte(runLen-4))
+			}
+		}
+		run, runLen = 256, 0
+	}
+	writeBlock := func() {
+		if len(block) == 0 {
+			return
+		}
+		blockCRC := ^crc
+		combined = (combined<<1 | combined>>31) ^ blockCRC
+		w.bytes(0x31, 0x41, 0x59, 0x26, 0x53, 0x59)
+		w.bits(32, blockCRC)
+		w.bits(1, 0) // not randomised
+		bzCompressBlock(w, block)
+		block, crc = block[:0], bzCRCInit
+	}
+
+	for _, c := range data {
+		if len(block) >= blockMax {
+			writeBlock()
+		}
+		switch {
+		case int(c) != run || runLen == 255:
+			flushRun()
+			run, runLen = int(c), 1
+		default:
+			runLen++
+		}
+	}
+	flushRun()
+	writeBlock()
+
+	w.bytes(0x17, 0x72, 0x45, 0x38, 0x50, 0x90)
+	w.bits(32, combined)
+	return w.flush()
+}
+
+// bzCompressBlock writes the origin pointer and the Huffman coded MTF values of a block
+// (BZ2_compressBlock, generateMTFValues and sendMTFValues of compress.c).
+func bzCompressBlock(w *bzWriter, block []byte) {
+	n := len(block)
+	ptr := bwtSort(block)
+	for i, p := range ptr {
+		if p == 0 {
+			w.bits(24, uint32(i))
+			break
+		}
+	}
+
+	// the byte values in use, numbered in increasing order
+	var inUse [256]bool
+	for _, c := range block {
+		inUse[c] = true
+	}
+	var seq [256]uint16
+	nInUse := 0
+	for i, used := range inUse {
+		if used {
+			seq[i] = uint16(nInUse)
+			nInUse++
+		}
+	}
+	alphaSize := nInUse + 2
+	eob := uint16(nInUse + 1)
+
+	// move-to-front coding of the Burrows-Wheeler transform, with the runs of zeros
+	// written in bijective base 2 with RUNA and RUNB
+	mtfv := make([]uint16, 0, n+1)
+	var freq [bzMaxAlpha]int32
+	var order [256]uint16
+	for i := range order {
+		order[i] = uint16(i)
+	}
+	zeros := 0
+	flushZeros := func() {
+		if zeros == 0 {
+			return
+		}
+		zeros--
+		for {
+			sym := uint16(bzRunA)
+			if zeros&1 != 0 {
+				sym = bzRunB
+			}
+			mtfv = append(mtfv, sym)
+			freq[sym]++
+			if zeros < 2 {
+				break
+			}
+			zeros = (zeros - 2) / 2
+		}
+		zeros = 0
+	}
+	for _, p := range ptr {
+		j := int(p) - 1
+		if j < 0 {
+			j += n
+		}
+		c := seq[block[j]]
+		if order[0] == c {
+			zeros++
+			continue
+		}
+		flushZeros()
+		k := 1
+		for order[k] != c {
+			k++
+		}
+		copy(order[1:k+1], order[:k])
+		order[0] = c
+		mtfv = append(mtfv, uint16(k+1))
+		freq[k+1]++
+	}
+	flushZeros()
+	mtfv = append(mtfv, eob)
+	freq[eob]++
+	nMTF := len(mtfv)
+
+	var groups int
+	switch {
+	case nMTF < 200:
+		groups = 2
+	case nMTF < 600:
+		groups = 3
+	case nMTF < 1200:
+		groups = 4
+	case nMTF < 2400:
+		groups = 5
+	default:
+		groups = 6
+	}
+
+	// initial tables: each covers a range of symbols holding about 1/groups of the values
+	var lens [bzMaxGroups][bzMaxAlpha]uint8
+	for t := range lens {
+		for v := 0; v < alphaSize; v++ {
+			lens[t][v] = bzGreaterICost
+		}
+	}
+	remaining, gs := nMTF, 0
+	for part := groups; part > 0; part-- {
+		target := remaining / part
+		ge, sum := gs-1, 0
+		for sum < target && ge < alphaSize-1 {
+			ge++
+			sum += int(freq[ge])
+		}
+		if ge > gs && part != groups && part != 1 && (groups-part)%2 == 1 {
+			sum -= int(freq[ge])
+			ge--
+		}
+		for v := 0; v < alphaSize; v++ {
+			if v >= gs && v <= ge {
+				lens[part-1][v] = bzLesserICost
+			} else {
+				lens[part-1][v] = bzGreaterICost
+			}
+		}
+		gs = ge + 1
+		remaining -= sum
+	}
+
+	// refine the tables: code each group of 50 symbols with its cheapest table, and rebuild
+	// the tables from the frequencies of the symbols they code
+	selectors := make([]uint8, 0, (nMTF+bzGroupSize-1)/bzGroupSize)
+	for iter := 0; iter < bzIterations; iter++ {
+		var rfreq [bzMaxGroups][bzMaxAlpha]int32
+		selectors = selectors[:0]
+		for gs := 0; gs < nMTF; gs += bzGroupSize {
+			ge := min(gs+bzGroupSize, nMTF)
+			var cost [bzMaxGroups]int
+			for _, v := range mtfv[gs:ge] {
+				for t := 0; t < groups; t++ {
+					cost[t] += int(lens[t][v])
+				}
+			}
+			best := 0
+			for t := 1; t < groups; t++ {
+				if cost[t] < cost[best] {
+					best = t
+				}
+			}
+			selectors = append(selectors, uint8(best))
+			for _, v := range mtfv[gs:ge] {
+				rfreq[best][v]++
+			}
+		}
+		for t := 0; t < groups; t++ {
+			huffmanCodeLengths(lens[t][:alphaSize], rfreq[t][:alphaSize], bzMaxCodeLen)
+		}
+	}
+
+	var codes [bzMaxGroups][bzMaxAlpha]uint32
+	for t := 0; t < groups; t++ {
+		huffmanAssignCodes(codes[t][:alphaSize], lens[t][:alphaSize])
+	}
+
+	// the mapping table: a bitmap of the 16-value ranges in use, then the values of each range
+	var inUse16 uint32
+	for i := 0; i < 16; i++ {
+		for j := 0; j < 16; j++ {
+			if inUse[i*16+j] {
+				inUse16 |= 1 << (15 - i)
+				break
+			}
+		}
+	}
+	w.bits(16, inUse16)
+	for i := 0; i < 16; i++ {
+		if inUse16&(1<<(15-i)) == 0 {
+			continue
+		}
+		var bitmap uint32
+		for j := 0; j < 16; j++ {
+			if inUse[i*16+j] {
+				bitmap |= 1 << (15 - j)
+			}
+		}
+		w.bits(16, bitmap)
+	}
+
+	// the selectors, move-to-front coded in unary
+	w.bits(3, uint32(groups))
+	w.bits(15, uint32(len(selectors)))
+	var pos [bzMaxGroups]uint8
+	for i := range pos {
+		pos[i] = uint8(i)
+	}
+	for _, s := range selectors {
+		j := 0
+		for pos[j] != s {
+			j++
+		}
+		copy(pos[1:j+1], pos[:j])
+		pos[0] = s
+		for ; j > 0; j-- {
+			w.bits(1, 1)
+		}
+		w.bits(1, 0)
+	}
+
+	// the code lengths of each table, delta coded
+	for t := 0; t < groups; t++ {
+		curr := lens[t][0]
+		w.bits(5, uint32(curr))
+		for v := 0; v < alphaSize; v++ {
+			for ; curr < lens[t][v]; curr++ {
+				w.bits(2, 2)
+			}
+			for ; curr > lens[t][v]; curr-- {
+				w.bits(2, 3)
+			}
+			w.bits(1, 0)
+		}
+	}
+
+	for i, v := range mtfv {
+		t := selectors[i/bzGroupSize]
+		w.bits(int(lens[t][v]), codes[t][v])
+	}
+}
+
+// huffmanCodeLengths computes the code lengths of a Huffman code for the frequencies, limited
+// to maxLen bits by flattening the frequencies until the code fits (BZ2_hbMakeCodeLengths of
+// huffman.c). Unused symbols are given the frequency 1.
+func huffmanCodeLengths(lens []uint8, freq []int32, maxLen int) {
+	n := len(freq)
+	// a weight holds the frequency in its upper 24 bits and the depth of the subtree in the
+	// lower 8, so that ties go to the shallower subtree
+	weight := make([]int32, 2*n+1)
+	parent := make([]int32, 2*n+1)
+	heap := make([]int32, n+2)
+	for i, f := range freq {
+		weight[i+1] = max(f, 1) << 8
+	}
+
+	up := func(z int) {
+		tmp := heap[z]
+		for weight[tmp] < weight[heap[z>>1]] {
+			heap[z] = heap[z>>1]
+			z >>= 1
+		}
+		heap[z] = tmp
+	}
+	down := func(z, size int) {
+		tmp := heap[z]
+		for {
+			y := z << 1
+			if y > size {
+				break
+			}
+			if y < size && weight[heap[y+1]] < weight[heap[y]] {
+				y++
+			}
+			if weight[tmp] < weight[heap[y]] {
+				break
+			}
+			heap[z] = heap[y]
+			z = y
+		}
+		heap[z] = tmp
+	}
+
+	for {
+		nodes, size := n, 0
+		heap[0], weight[0], parent[0] = 0, 0, -2
+		for i := 1; i <= n; i++ {
+			parent[i] = -1
+			size++
+			heap[size] = int32(i)
+			up(size)
+		}
+		for size > 1 {
+			n1 := heap[1]
+			heap[1] = heap[size]
+			size--
+			down(1, size)
+			n2 := heap[1]
+			heap[1] = heap[size]
+			size--
+			down(1, size)
+
+			nodes++
+			parent[n1], parent[n2] = int32(nodes), int32(nodes)
+			w1, w2 := weight[n1], weight[n2]
+			weight[nodes] = (w1&^0xff + w2&^0xff) | (1 + max(w1&0xff, w2&0xff))
+			parent[nodes] = -1
+			size++
+			heap[size] = int32(nodes)
+			up(size)
+		}
+
+		tooLong := false
+		for i := 1; i <= n; i++ {
+			depth := 0
+			for k := i; parent[k] >= 0; k = int(parent[k]) {
+				depth++
+			}
+			lens[i-1] = uint8(depth)
+			tooLong = tooLong || depth > maxLen
+		}
+		if !tooLong {
+			return
+		}
+		for i := 1; i <= n; i++ {
+			weight[i] = (1 + (weight[i]>>8)/2) << 8
+		}
+	}
+}
+
+// huffmanAssignCodes assigns the canonical codes of the code lengths (BZ2_hbAssignCodes).
+func huffmanAssignCodes(codes []uint32, lens []uint8) {
+	minLen, maxLen := uint8(32), uint8(0)
+	for _, l := range lens {
+		minLen, maxLen = min(minLen, l), max(maxLen, l)
+	}
+	code := uint32(0)
+	for l := minLen; l <= maxLen; l++ {
+		for i := range lens {
+			if lens[i] == l {
+				codes[i] = code
+				code++
+			}
+		}
+		code <<= 1
+	}
+}
+
+// bwtSort returns the starting positions of the rotations of the block in lexicographic order,
+// sorted by prefix doubling like suffixArray. Equal rotations, in periodic blocks, are in no
+// particular order, which does not change the transform.
+func bwtSort(block []byte) []int32 {
+	n := len(block)
+	sa := make([]int32, n)
+	rank := make([]int32, n)
+	next := make([]int32, n)
+	tmp := make([]int32, n)
+
+	var counts [257]int
+	for _, c := range block {
+		counts[int(c)+1]++
+	}
+	for i := 1; i < len(counts); i++ {
+		counts[i] += counts[i-1]
+	}
+	for i, c := range block {
+		sa[counts[c]] = int32(i)
+		counts[c]++
+	}
+	rank[sa[0]] = 0
+	for i := 1; i < n; i++ {
+		rank[sa[i]] = rank[sa[i-1]]
+		if block[sa[i]] != block[sa[i-1]] {
+			rank[sa[i]]++
+		}
+	}
+
+	bucket := make([]int, n+1)
+	for k := 1; k < n && int(rank[sa[n-1]]) < n-1; k <<= 1 {
+		// the rotations ordered by their second half
+		for i, p := range sa {
+			q := int(p) - k
+			if q < 0 {
+				q += n
+			}
+			tmp[i] = int32(q)
+		}
+
+		clear(bucket)
+		for _, r := range rank {
+			bucket[r+1]++
+		}
+		for i := 1; i <= n; i++ {
+			bucket[i] += bucket[i-1]
+		}
+		for _, p := range tmp {
+			sa[bucket[rank[p]]] = p
+			bucket[rank[p]]++
+		}
+
+		second := func(p int32) int32 {
+			return rank[(int(p)+k)%n]
+		}
+		next[sa[0]] = 0
+		for i := 1; i < n; i++ {
+			a, b := sa[i-1], sa[i]
+			next[b] = next[a]
+			if rank[a] != rank[b] || second(a) != second(b) {
+				next[b]++
+			}
+		}
+		rank, next = next, rank
+	}
+	return sa
+}
+
+// bzip2 uses the CRC-32 of IEEE 802.3 with the bits in big-endian order.
+const bzCRCInit uint32 = 0xffffffff
+
+var bzCRCTable = func() (table [256]uint32) {
+	for i := range table {
+		c := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if c&0x80000000 != 0 {
+				c = c<<1 ^ 0x04c11db7
+			} else {
+				c <<= 1
+			}
+		}
+		table[i] = c
+	}
+	return table
+}()
+
+func bzCRCUpdate(crc uint32, c byte) uint32 {
+	return crc<<8 ^ bzCRCTable[byte(crc>>24)^c]
+}
+
+// bzWriter writes bits most significant bit first.
+type bzWriter struct {
+	out   []byte
+	acc   uint64
+	nbits int
+}
+
+func (w *bzWriter) bits(n int, v uint32) {
+	w.acc = w.acc<<n | uint64(v)&(1<<n-1)
+	w.nbits += n
+	for w.nbits >= 8 {
+		w.nbits -= 8
+		w.out = append(w.out, byte(w.acc>>w.nbits))
+	}
+}
+
+func (w *bzWriter) bytes(bs ...byte) {
+	for _, c := range bs {
+		w.bits(8, uint32(c))
+	}
+}
+
+// flush pads the last byte with zeros and returns the output.
+func (w *bzWriter) flush() []byte {
+	if w.nbits > 0 {
+		w.bits(8-w.nbits, 0)
+	}
+	return w.out
+}
@@ -0,0 +1,74 @@
+package entropy
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"io"
+	"strconv"
+	"testing"
+)
+
+// lcgText returns n values of the ANSI C linear congruential generator modulo mod, written in
+// decimal and separated by spaces.
+func lcgText(n int, mod uint32) []byte {
+	var text []byte
+	x := uint32(1)
+	for i := 0; i < n; i++ {
+		x = (x*1103515245 + 12345) & 0x7fffffff
+		if i > 0 {
+			text = append(text, ' ')
+		}
+		text = strconv.AppendUint(text, uint64(x>>16%mod), 10)
+	}
+	return text
+}
+
+func TestBzip2(t *testing.T) {
+	runs := bytes.Repeat([]byte("a"), 1000)
+	runs = append(runs, "bbb"...)
+	runs = append(runs, bytes.Repeat([]byte("c"), 300)...)
+	runs = append(runs, bytes.Repeat([]byte("abc"), 50)...)
+
+	// the lengths of the output of libbzip2 1.0.8
+	for _, tc := range []struct {
+		name string
+		data []byte
+		want map[int]int // by block size
+	}{
+		{"empty", nil, map[int]int{5: 14, 9: 14}},
+		{"single byte", []byte("a"), map[int]int{5: 37}},
+		{"runs", runs, map[int]int{5: 59, 9: 59}},
+		// several blocks, with runs of equal bytes at their boundaries
+		{"decimal bytes", lcgText(300000, 256), map[int]int{1: 322252, 5: 320355, 9: 320524}},
+		{"decimal bits", lcgText(200000, 2), map[int]int{1: 31363, 5: 29862, 9: 29862}},
+	} {
+		for size, want := range tc.want {
+			out := bzip2Compress(tc.data, size)
+			if len(out) != want {
+				t.Errorf("%s with block size %d: %d bytes, expected %d", tc.name, size, len(out), want)
+			}
+			got, err := io.ReadAll(bzip2.NewReader(bytes.NewReader(out)))
+			if err != nil || !bytes.Equal(got, tc.data) {
+				t.Errorf("%s with block size %d: the output does not decompress to the input (%v)", tc.name, size, err)
+			}
+		}
+	}
+}
+
+func TestHuffmanCodeLengths(t *testing.T) {
+	// Fibonacci frequencies give a code of depth n - 1, flattened to the limit
+	freq := []int32{1, 1, 2, 3, 5, 8, 13, 21, 34, 55, 89, 144, 233, 377, 610, 987, 1597, 2584, 4181, 6765, 10946}
+	lens := make([]uint8, len(freq))
+	huffmanCodeLengths(lens, freq, 17)
+
+	kraft := 0.0
+	for _, l := range lens {
+		if l < 1 || l > 17 {
+			t.Fatalf("code lengths %v exceed 17 bits", lens)
+		}
+		kraft += 1 / float64(uint32(1)<<l)
+	}
+	if kraft != 1 {
+		t.Errorf("code lengths %v do not give a complete prefix code (Kraft sum %f)", lens, kraft)
+	}
+}
@@ -0,0 +1,245 @@
+package entropy
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"slices"
+
+	nist "github.com/notJoon/drbg/nist"
+)
+
+// chiSquareAlpha is the significance level of the tests of section 5.2.
+const chiSquareAlpha = 0.001
+
+// chiSquare returns the result of a χ² statistic with df degrees of freedom.
+func chiSquare(name string, t float64, df int) *ChiSquareResult {
+	p := nist.Igamc(float64(df)/2, t/2)
+	return &ChiSquareResult{Name: name, T: t, DF: df, P: p, Pass: p >= chiSquareAlpha}
+}
+
+// notApplicable returns the result of a test that cannot be run on the samples.
+func notApplicable(name string, err error) *ChiSquareResult {
+	return &ChiSquareResult{Name: name, Pass: true, Err: err}
+}
+
+// binned is a cell of a χ² test: a bin of items, e.g. values or pairs of values.
+type binned struct {
+	item     int
+	expected float64
+}
+
+// allocateBins allocates the items to bins, starting from the smallest expected count, so that
+// each bin has an expected count of at least 5; the last bin is merged with the previous one
+// if its expected count is smaller. It returns the bin of each item and the expected count
+// of each bin.
+func allocateBins(items []binned, size int) ([]int, []float64) {
+	slices.SortStableFunc(items, func(a, b binned) int {
+		switch {
+		case a.expected < b.expected:
+			return -1
+		case a.expected > b.expected:
+			return 1
+		}
+		return 0
+	})
+
+	bin := make([]int, size)
+	var expected []float64
+	current, start := 0.0, 0 // the expected count and the first item of the bin being filled
+	for i, it := range items {
+		bin[it.item] = len(expected)
+		current += it.expected
+		if current >= 5 {
+			expected = append(expected, current)
+			current, start = 0, i+1
+		}
+	}
+	switch {
+	case start == len(items):
+	case len(expected) == 0:
+		expected = append(expected, current)
+	default:
+		last := len(expected) - 1
+		for _, it := range items[start:] {
+			bin[it.item] = last
+		}
+		expected[last] += current
+	}
+	return bin, expected
+}
+
+// independence is the chi-square test for the independence of non-binary samples (section
+// 5.2.1): the pairs of consecutive samples (overlapping) are binned by their expected counts
+// p_i p_j (L - 1), with q - k degrees of freedom for q bins and k distinct values.
+func independence(s *Samples) *ChiSquareResult {
+	const name = "Chi-Square Independence"
+	var counts [256]int
+	for _, v := range s.Data {
+		counts[v]++
+	}
+	var values []int
+	for v, c := range counts {
+		if c > 0 {
+			values = append(values, v)
+		}
+	}
+	k := len(values)
+
+	L := float64(s.Len())
+	var pairs []binned
+	for _, a := range values {
+		for _, b := range values {
+			e := float64(counts[a]) / L * float64(counts[b]) / L * (L - 1)
+			pairs = append(pairs, binned{a<<8 | b, e})
+		}
+	}
+	bin, expected := allocateBins(pairs, 1<<16)
+	if df := len(expected) - k; df < 1 {
+		return notApplicable(name, fmt.Errorf("%w: %d bins for %d values", ErrNotEnoughSamples, len(expected), k))
+	}
+
+	observed := make([]float64, len(expected))
+	for i := 0; i+1 < s.Len(); i++ {
+		observed[bin[int(s.Data[i])<<8|int(s.Data[i+1])]]++
+	}
+	return chiSquare(name, chiSquareSum(observed, expected), len(expected)-k)
+}
+
+// goodnessOfFit is the chi-square goodness-of-fit test of non-binary samples (section 5.2.3):
+// the samples are split into 10 parts, and the counts of the values in each part are compared
+// with a tenth of their counts in all samples, binned so that each bin expects at least 5.
+func goodnessOfFit(s *Samples) *ChiSquareResult {
+	const name = "Chi-Square Goodness of Fit"
+	var counts [256]int
+	for _, v := range s.Data {
+		counts[v]++
+	}
+	var values []binned
+	for v, c := range counts {
+		if c > 0 {
+			values = append(values, binned{v, float64(c) / 10})
+		}
+	}
+	bin, expected := allocateBins(values, 256)
+	if len(expected) < 2 {
+		return notApplicable(name, fmt.Errorf("%w: %d bins", ErrNotEnoughSamples, len(expected)))
+	}
+
+	t := 0.0
+	part := s.Len() / 10
+	for d := 0; d < 10; d++ {
+		observed := make([]float64, len(expected))
+		for _, v := range s.Data[d*part : (d+1)*part] {
+			observed[bin[v]]++
+		}
+		t += chiSquareSum(observed, expected)
+	}
+	return chiSquare(name, t, 9*(len(expected)-1))
+}
+
+// binaryIndependence is the chi-square test for the independence of binary samples (section
+// 5.2.2): the counts of the non-overlapping m-bit tuples are compared with the counts expected
+// from the proportion of ones, for the largest m up to 11 such that every tuple is expected
+// at least 5 times.
+func binaryIndependence(s *Samples) *ChiSquareResult {
+	const name = "Chi-Square Independence"
+	ones := 0
+	for _, v := range s.Data {
+		ones += int(v)
+	}
+	p1 := float64(ones) / float64(s.Len())
+	p0 := 1 - p1
+
+	m := 11
+	for ; m >= 2; m-- {
+		if math.Pow(math.Min(p0, p1), float64(m))*float64(s.Len()/m) >= 5 {
+			break
+		}
+	}
+	if m < 2 {
+		return notApplicable(name, fmt.Errorf("%w: no tuple length gives 5 expected occurrences", ErrNotEnoughSamples))
+	}
+
+	blocks := s.Len() / m
+	observed := make([]float64, 1<<m)
+	for i := 0; i < blocks; i++ {
+		w := 0
+		for _, bit := range s.Data[i*m : (i+1)*m] {
+			w = w<<1 | int(bit)
+		}
+		observed[w]++
+	}
+	expected := make([]float64, 1<<m)
+	for w := range expected {
+		k := bits.OnesCount(uint(w))
+		expected[w] = math.Pow(p1, float64(k)) * math.Pow(p0, float64(m-k)) * float64(blocks)
+	}
+	return chiSquare(name, chiSquareSum(observed, expected), 1<<m-2)
+}
+
+// binaryGoodnessOfFit is the chi-square goodness-of-fit test of binary samples (section 5.2.4):
+// the counts of zeros and ones in 10 parts of the samples are compared with the proportion of
+// ones in all samples.
+func binaryGoodnessOfFit(s *Samples) *ChiSquareResult {
+	const name = "Chi-Square Goodness of Fit"
+	ones := 0
+	for _, v := range s.Data {
+		ones += int(v)
+	}
+	p := float64(ones) / float64(s.Len())
+	part := s.Len() / 10
+	expected := []float64{(1 - p) * float64(part), p * float64(part)}
+	if expected[0] == 0 || expected[1] == 0 {
+		return notApplicable(name, fmt.Errorf("%w: the samples are constant", ErrNotApplicable))
+	}
+
+	t := 0.0
+	for d := 0; d < 10; d++ {
+		observed := make([]float64, 2)
+		for _, v := range s.Data[d*part : (d+1)*part] {
+			observed[v]++
+		}
+		t += chiSquareSum(observed, expected)
+	}
+	return chiSquare(name, t, 9)
+}
+
+// chiSquareSum returns Σ (o_i - e_i)² / e_i.
+func chiSquareSum(observed, expected []float64) float64 {
+	t := 0.0
+	for i, e := range expected {
+		t += (observed[i] - e) * (observed[i] - e) / e
+	}
+	return t
+}
+
+// lrsTest is the longest repeated substring test of section 5.2.5: the probability that IID
+// samples with the collision probability of the samples have a repeated substring of the length
+// W of the longest one, 1 - (1 - p_col^W)^C(L-W+1, 2), must be at least 0.001.
+func lrsTest(s *Samples) *LRSResult {
+	lcp := lcpArray(s.Data, suffixArray(s.Data))
+	w := 0
+	for _, l := range lcp {
+		w = max(w, int(l))
+	}
+
+	var counts [256]int
+	for _, v := range s.Data {
+		counts[v]++
+	}
+	pCol := 0.0
+	for _, c := range counts {
+		p := float64(c) / float64(s.Len())
+		pCol += p * p
+	}
+
+	res := &LRSResult{W: w, PCol: pCol, P: 1}
+	if w > 0 {
+		n := float64(s.Len() - w + 1)
+		pairs := n * (n - 1) / 2
+		res.P = -math.Expm1(pairs * math.Log1p(-math.Pow(pCol, float64(w))))
+	}
+	res.Pass = res.P >= chiSquareAlpha
+	return res
+}
@@ -0,0 +1,228 @@
+// Package entropy estimates the min-entropy of the samples of an entropy source, following
+// NIST SP 800-90B (https://doi.org/10.6028/NIST.SP.800-90B).
+//
+// The estimators of section 6.3 bound the probability of the most likely sample value; the
+// non-IID track of section 3.1.3 combines them into the assessed min-entropy per sample.
+package entropy
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	b "github.com/notJoon/drbg/bitstream"
+)
+
+var (
+	ErrInvalidSampleSize = errors.New("samples must have 1 to 8 bits")
+	ErrNotEnoughSamples  = errors.New("not enough samples")
+	ErrNotBinary         = errors.New("the estimator applies to binary samples only")
+	ErrNotApplicable     = errors.New("the estimator does not apply to these samples")
+)
+
+// z is the quantile of the normal distribution used by every confidence bound of section 6.3 (99%).
+const z = 2.576
+
+// Samples is a sequence of samples of an entropy source, each of 1 to 8 bits.
+type Samples struct {
+	Data []byte // the sample values, each less than 1 << Bits
+	Bits int    // the number of bits per sample
+}
+
+// NewSamples returns the samples of bits bits held in data, checking that every value fits.
+func NewSamples(data []byte, bits int) (*Samples, error) {
+	if bits < 1 || bits > 8 {
+		return nil, fmt.Errorf("%w, got %d", ErrInvalidSampleSize, bits)
+	}
+	for i, v := range data {
+		if int(v) >= 1<<bits {
+			return nil, fmt.Errorf("sample %d is %d, more than %d bits", i, v, bits)
+		}
+	}
+	return &Samples{Data: data, Bits: bits}, nil
+}
+
+// FromBitStream splits the bitstream into consecutive samples of bits bits, most significant bit
+// first. Trailing bits that do not fill a sample are dropped.
+func FromBitStream(bs *b.BitStream, bits int) (*Samples, error) {
+	if bits < 1 || bits > 8 {
+		return nil, fmt.Errorf("%w, got %d", ErrInvalidSampleSize, bits)
+	}
+
+	data := make([]byte, bs.Len()/bits)
+	for i := range data {
+		for j := 0; j < bits; j++ {
+			bit, err := bs.Bit(i*bits + j)
+			if err != nil {
+				return nil, err
+			}
+			data[i] = data[i]<<1 | bit
+		}
+	}
+	return &Samples{Data: data, Bits: bits}, nil
+}
+
+// Len returns the number of samples.
+func (s *Samples) Len() int {
+	return len(s.Data)
+}
+
+// Alphabet returns k, the number of possible sample values.
+func (s *Samples) Alphabet() int {
+	return 1 << s.Bits
+}
+
+// BitString returns the samples converted to a sequence of bits, most significant bit first
+// (section 6.4).
+func (s *Samples) BitString() *Samples {
+	if s.Bits == 1 {
+		return s
+	}
+	data := make([]byte, 0, len(s.Data)*s.Bits)
+	for _, v := range s.Data {
+		for j := s.Bits - 1; j >= 0; j-- {
+			data = append(data, v>>uint(j)&1)
+		}
+	}
+	return &Samples{Data: data, Bits: 1}
+}
+
+// Estimate is the outcome of an estimator: an upper bound on the probability of the most likely
+// sample value, and the matching min-entropy per sample.
+type Estimate struct {
+	Name       string  // name of the estimator
+	Section    string  // section of SP 800-90B describing it, e.g. "6.3.1"
+	P          float64 // upper bound on the probability of the most likely value
+	MinEntropy float64 // -log2(P), in bits per sample
+	Err        error   // why the estimator could not be applied; P and MinEntropy are then zero
+}
+
+// upperBound returns the upper bound of the 99% confidence interval of a proportion p
+// estimated from n samples: min(1, p + 2.576 sqrt(p(1-p)/(n-1))).
+func upperBound(p float64, n int) float64 {
+	return math.Min(1, p+z*math.Sqrt(p*(1-p)/float64(n-1)))
+}
+
+// Estimator is an estimator of section 6.3.
+type Estimator struct {
+	Name    string
+	Section string
+	Binary  bool // the estimator applies to binary samples only
+
+	// p returns the upper bound on the probability of the most likely value
+	p func(s *Samples) (float64, error)
+}
+
+// Estimate runs the estimator on the samples.
+func (e Estimator) Estimate(s *Samples) (*Estimate, error) {
+	if e.Binary && s.Bits != 1 {
+		return nil, ErrNotBinary
+	}
+	if s.Len() < 2 {
+		return nil, ErrNotEnoughSamples
+	}
+	p, err := e.p(s)
+	if err != nil {
+		return nil, err
+	}
+	return &Estimate{Name: e.Name, Section: e.Section, P: p, MinEntropy: math.Max(0, -math.Log2(p))}, nil
+}
+
+// The estimators of section 6.3.
+var (
+	MostCommonValue          = Estimator{"Most Common Value", "6.3.1", false, mostCommonValue}
+	Collision                = Estimator{"Collision", "6.3.2", true, collision}
+	Markov                   = Estimator{"Markov", "6.3.3", true, markov}
+	Compression              = Estimator{"Compression", "6.3.4", true, compression}
+	TTuple                   = Estimator{"t-Tuple", "6.3.5", false, tTuple}
+	LongestRepeatedSubstring = Estimator{"Longest Repeated Substring", "6.3.6", false, longestRepeatedSubstring}
+	MultiMCW                 = Estimator{"Multi Most Common in Window", "6.3.7", false, multiMCW}
+	Lag                      = Estimator{"Lag Prediction", "6.3.8", false, lag}
+	MultiMMC                 = Estimator{"Multi Markov Model with Counting", "6.3.9", false, multiMMC}
+	LZ78Y                    = Estimator{"LZ78Y", "6.3.10", false, lz78y}
+)
+
+// Estimators lists the estimators of section 6.3 in the order of the document.
+var Estimators = []Estimator{
+	MostCommonValue, Collision, Markov, Compression, TTuple,
+	LongestRepeatedSubstring, MultiMCW, Lag, MultiMMC, LZ78Y,
+}
+
+// Assessment is the outcome of the IID or non-IID track of section 3.1.3.
+type Assessment struct {
+	Bits    int // the number of bits per sample
+	Samples int // the number of samples
+
+	// Original holds the estimates on the samples: every estimator for binary samples,
+	// and those that apply to non-binary samples otherwise.
+	Original []*Estimate
+	// Bitstring holds the estimates of every estimator on the samples converted to bits.
+	// It is empty for binary samples.
+	Bitstring []*Estimate
+
+	HOriginal  float64 // the lowest estimate of Original, in bits per sample
+	HBitstring float64 // the lowest estimate of Bitstring, in bits per bit
+	MinEntropy float64 // the assessed min-entropy H_I = min(HOriginal, Bits × HBitstring), in bits per sample
+}
+
+// NonIID runs every estimator of section 6.3 on the samples and returns the assessed
+// min-entropy per sample. Estimators that cannot be applied, e.g. the Longest Repeated
+// Substring estimate on samples without repeated substrings of the required length, are
+// reported with their error and left out of the assessment.
+func NonIID(s *Samples) (*Assessment, error) {
+	return assess(s, Estimators)
+}
+
+// IID returns the assessed min-entropy per sample of the IID track of section 3.1.3, from the
+// Most Common Value estimate alone. The IID assumption should first be checked with CheckIID.
+func IID(s *Samples) (*Assessment, error) {
+	return assess(s, []Estimator{MostCommonValue})
+}
+
+// assess runs the estimators on the samples and, for non-binary samples, on the samples
+// converted to bits.
+func assess(s *Samples, estimators []Estimator) (*Assessment, error) {
+	if s.Bits < 1 || s.Bits > 8 {
+		return nil, fmt.Errorf("%w, got %d", ErrInvalidSampleSize, s.Bits)
+	}
+
+	a := &Assessment{Bits: s.Bits, Samples: s.Len()}
+	var err error
+	if a.Original, a.HOriginal, err = runEstimators(s, estimators); err != nil {
+		return nil, err
+	}
+	a.MinEntropy = a.HOriginal
+	if s.Bits == 1 {
+		a.HBitstring = a.HOriginal
+		return a, nil
+	}
+
+	if a.Bitstring, a.HBitstring, err = runEstimators(s.BitString(), estimators); err != nil {
+		return nil, err
+	}
+	a.MinEntropy = math.Min(a.HOriginal, float64(s.Bits)*a.HBitstring)
+	return a, nil
+}
+
+// runEstimators runs the estimators that apply to the samples and returns their estimates
+// and the lowest one.
+func runEstimators(s *Samples, estimators []Estimator) ([]*Estimate, float64, error) {
+	var estimates []*Estimate
+	h := math.Inf(1)
+	for _, e := range estimators {
+		if e.Binary && s.Bits != 1 {
+			continue
+		}
+		est, err := e.Estimate(s)
+		if err != nil {
+			estimates = append(estimates, &Estimate{Name: e.Name, Section: e.Section, Err: err})
+			continue
+		}
+		estimates = append(estimates, est)
+		h = math.Min(h, est.MinEntropy)
+	}
+	if math.IsInf(h, 1) {
+		return nil, 0, fmt.Errorf("%w: no estimator applies to %d samples", ErrNotEnoughSamples, s.Len())
+	}
+	return estimates, h, nil
+}
@@ -0,0 +1,133 @@
+package entropy
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	b "github.com/notJoon/drbg/bitstream"
+)
+
+// biasedBits returns n independent bits equal to 1 with probability p.
+func biasedBits(n int, p float64, seed int64) *Samples {
+	r := rand.New(rand.NewSource(seed))
+	data := make([]byte, n)
+	for i := range data {
+		if r.Float64() < p {
+			data[i] = 1
+		}
+	}
+	return &Samples{Data: data, Bits: 1}
+}
+
+// uniformSamples returns n independent uniform samples of bits bits.
+func uniformSamples(n, bits int, seed int64) *Samples {
+	r := rand.New(rand.NewSource(seed))
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(r.Intn(1 << bits))
+	}
+	return &Samples{Data: data, Bits: bits}
+}
+
+func TestSamples(t *testing.T) {
+	bs := b.NewBitStream([]byte{0b10110011, 0b01000000})
+	s, err := FromBitStream(bs, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 101 100 110 100 0000000 (the trailing bits are dropped)
+	want := []byte{5, 4, 6, 4, 0}
+	if s.Len() != len(want) || s.Alphabet() != 8 {
+		t.Fatalf("got %v with alphabet %d, expected %v with alphabet 8", s.Data, s.Alphabet(), want)
+	}
+	for i := range want {
+		if s.Data[i] != want[i] {
+			t.Fatalf("got %v, expected %v", s.Data, want)
+		}
+	}
+
+	bits := s.BitString()
+	if bits.Bits != 1 || bits.Len() != 15 || bits.Data[0] != 1 || bits.Data[1] != 0 || bits.Data[2] != 1 {
+		t.Errorf("bitstring %v", bits.Data)
+	}
+
+	if _, err := FromBitStream(bs, 9); !errors.Is(err, ErrInvalidSampleSize) {
+		t.Errorf("9-bit samples: got %v, expected ErrInvalidSampleSize", err)
+	}
+	if _, err := NewSamples([]byte{0, 4}, 2); err == nil {
+		t.Error("expected an error for the value 4 in 2-bit samples")
+	}
+	if _, err := Collision.Estimate(s); !errors.Is(err, ErrNotBinary) {
+		t.Errorf("collision on 3-bit samples: got %v, expected ErrNotBinary", err)
+	}
+}
+
+func TestNonIIDConstant(t *testing.T) {
+	a, err := NonIID(&Samples{Data: make([]byte, 20000), Bits: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range a.Original {
+		if e.Err != nil || e.MinEntropy > 1e-3 {
+			t.Errorf("%s: %f (%v), expected 0", e.Name, e.MinEntropy, e.Err)
+		}
+	}
+	if a.MinEntropy > 1e-3 {
+		t.Errorf("min-entropy %f, expected 0", a.MinEntropy)
+	}
+}
+
+func TestNonIIDBiased(t *testing.T) {
+	// the min-entropy of bits equal to 1 with probability 3/4 is -log2(3/4) = 0.415
+	a, err := NonIID(biasedBits(100000, 0.75, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Original) != len(Estimators) || len(a.Bitstring) != 0 {
+		t.Fatalf("%d estimates on the samples and %d on the bitstring, expected %d and 0", len(a.Original), len(a.Bitstring), len(Estimators))
+	}
+
+	h := -math.Log2(0.75)
+	for _, e := range a.Original {
+		if e.Err != nil {
+			t.Errorf("%s: %v", e.Name, e.Err)
+			continue
+		}
+		// LRS and Lag bound the collision probability and the rate of correct predictions,
+		// p² + q² for independent bits; the others bound the probability of the most likely value
+		if e.Name != LongestRepeatedSubstring.Name && e.Name != Lag.Name && e.MinEntropy > h+0.01 {
+			t.Errorf("%s: %f, more than %f", e.Name, e.MinEntropy, h)
+		}
+	}
+	if a.MinEntropy < 0.2 || a.MinEntropy > h {
+		t.Errorf("min-entropy %f, expected between 0.2 and %f", a.MinEntropy, h)
+	}
+}
+
+func TestNonIIDUniform(t *testing.T) {
+	a, err := NonIID(uniformSamples(20000, 4, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Original) != 7 || len(a.Bitstring) != len(Estimators) {
+		t.Fatalf("%d estimates on the samples and %d on the bitstring, expected 7 and %d", len(a.Original), len(a.Bitstring), len(Estimators))
+	}
+	for _, e := range append(a.Original, a.Bitstring...) {
+		if e.Err != nil {
+			t.Errorf("%s: %v", e.Name, e.Err)
+		}
+	}
+
+	if a.HOriginal < 3.5 || a.HOriginal > 4 {
+		t.Errorf("H_original %f, expected between 3.5 and 4", a.HOriginal)
+	}
+	// the Compression estimate is the lowest, about 0.75 per bit for 80000 bits
+	if a.HBitstring < 0.7 || a.HBitstring > 1 {
+		t.Errorf("H_bitstring %f, expected between 0.7 and 1", a.HBitstring)
+	}
+	if want := math.Min(a.HOriginal, 4*a.HBitstring); a.MinEntropy != want {
+		t.Errorf("min-entropy %f, expected min(H_original, 4 H_bitstring) = %f", a.MinEntropy, want)
+	}
+}
@@ -0,0 +1,222 @@
+package entropy
+
+import (
+	"fmt"
+	"math"
+)
+
+// mostCommonValue is the Most Common Value Estimate (section 6.3.1): the upper bound of the
+// proportion of the most common value.
+func mostCommonValue(s *Samples) (float64, error) {
+	var counts [256]int
+	for _, v := range s.Data {
+		counts[v]++
+	}
+	mode := 0
+	for _, c := range counts {
+		mode = max(mode, c)
+	}
+	return upperBound(float64(mode)/float64(s.Len()), s.Len()), nil
+}
+
+// collision is the Collision Estimate (section 6.3.2). It measures the mean number of samples
+// read until a value repeats, t_i, and solves for the probability p of the most likely value:
+//
+//	X̄' = X̄ - 2.576 σ̂ / sqrt(v),    σ̂ = sqrt(Σ (t_i - X̄)² / (v - 1))
+//
+// For binary samples a value repeats after 2 or 3 samples, and the equation of step 8 reduces
+// to X̄' = 2 + 2p(1 - p).
+func collision(s *Samples) (float64, error) {
+	var times []float64
+	for i := 0; i < s.Len()-1; {
+		t := 2
+		if s.Data[i] != s.Data[i+1] {
+			if i+2 >= s.Len() {
+				break
+			}
+			t = 3
+		}
+		times = append(times, float64(t))
+		i += t
+	}
+	v := len(times)
+	if v < 2 {
+		return 0, fmt.Errorf("%w: %d collisions", ErrNotEnoughSamples, v)
+	}
+
+	mean := 0.0
+	for _, t := range times {
+		mean += t
+	}
+	mean /= float64(v)
+	variance := 0.0
+	for _, t := range times {
+		variance += (t - mean) * (t - mean)
+	}
+	sigma := math.Sqrt(variance / float64(v-1))
+	bound := mean - z*sigma/math.Sqrt(float64(v))
+
+	switch {
+	case bound >= 2.5:
+		// no solution above 1/2
+		return 0.5, nil
+	case bound <= 2:
+		return 1, nil
+	}
+	return 0.5 + math.Sqrt(0.25-(bound-2)/2), nil
+}
+
+// markov is the Markov Estimate (section 6.3.3). It estimates the initial probabilities and
+// the transition probabilities of a first-order Markov model, and returns the probability
+// of the most likely sequence of 128 bits, as a probability per bit: 2^(-H) with
+// H = min(-log2(p_max) / 128, 1).
+func markov(s *Samples) (float64, error) {
+	var ones int
+	var transitions [2][2]int
+	for i, v := range s.Data {
+		ones += int(v)
+		if i > 0 {
+			transitions[s.Data[i-1]][v]++
+		}
+	}
+	p1 := float64(ones) / float64(s.Len())
+	p0 := 1 - p1
+
+	var t [2][2]float64
+	for a := range transitions {
+		if total := transitions[a][0] + transitions[a][1]; total > 0 {
+			t[a][0] = float64(transitions[a][0]) / float64(total)
+			t[a][1] = float64(transitions[a][1]) / float64(total)
+		}
+	}
+
+	// the log2 of the probability of the six candidate sequences; log2(0) is -Inf
+	log2 := math.Log2
+	candidates := []float64{
+		log2(p0) + 127*log2(t[0][0]),                   // 00...0
+		log2(p0) + 64*log2(t[0][1]) + 63*log2(t[1][0]), // 0101...01
+		log2(p0) + log2(t[0][1]) + 126*log2(t[1][1]),   // 011...1
+		log2(p1) + log2(t[1][0]) + 126*log2(t[0][0]),   // 100...0
+		log2(p1) + 64*log2(t[1][0]) + 63*log2(t[0][1]), // 1010...10
+		log2(p1) + 127*log2(t[1][1]),                   // 11...1
+	}
+	pMax := math.Inf(-1)
+	for _, c := range candidates {
+		if !math.IsNaN(c) {
+			pMax = math.Max(pMax, c)
+		}
+	}
+
+	h := math.Min(-pMax/128, 1)
+	return math.Exp2(-h), nil
+}
+
+// Parameters of the Compression Estimate.
+const (
+	compressionBlock = 6    // b, the bits per block
+	compressionInit  = 1000 // d, the blocks used to initialize the dictionary
+)
+
+// compression is the Compression Estimate (section 6.3.4), based on Maurer's universal statistic.
+// The samples are split into blocks of b = 6 bits; the first d = 1000 blocks initialize a
+// dictionary, and for each following block the distance D_i to its previous occurrence is
+// recorded. The probability p of the most likely block solves
+//
+//	X̄' = G(p) + (2^b - 1) G((1 - p) / (2^b - 1))
+//
+// where X̄' is the lower bound of the mean of log2(D_i), and G(z) the expected value of log2(D_i)
+// for a block of probability z. The estimate is returned per bit.
+func compression(s *Samples) (float64, error) {
+	const b, d = compressionBlock, compressionInit
+	blocks := s.Len() / b
+	v := blocks - d
+	if v < 2 {
+		return 0, fmt.Errorf("%w: %d blocks of %d bits, need more than %d", ErrNotEnoughSamples, blocks, b, d+1)
+	}
+
+	var dict [1 << b]int
+	var sum, sumSquares float64
+	for i := 1; i <= blocks; i++ {
+		block := 0
+		for _, bit := range s.Data[(i-1)*b : i*b] {
+			block = block<<1 | int(bit)
+		}
+		if i > d {
+			distance := i
+			if dict[block] != 0 {
+				distance = i - dict[block]
+			}
+			l := math.Log2(float64(distance))
+			sum += l
+			sumSquares += l * l
+		}
+		dict[block] = i
+	}
+
+	mean := sum / float64(v)
+	sigma := 0.5907 * math.Sqrt(math.Max(0, sumSquares/float64(v-1)-mean*mean))
+	bound := mean - z*sigma/math.Sqrt(float64(v))
+
+	// expected returns the expected value of the statistic for a block probability p
+	const n = 1 << b
+	logs := make([]float64, blocks+1)
+	for u := range logs {
+		logs[u] = math.Log2(float64(u))
+	}
+	expected := func(p float64) float64 {
+		return compressionG(p, logs, d) + (n-1)*compressionG((1-p)/(n-1), logs, d)
+	}
+
+	p, ok := solveDecreasing(expected, bound, 1.0/n, 1)
+	if !ok {
+		return 0.5, nil
+	}
+	return math.Pow(p, 1.0/b), nil
+}
+
+// compressionG is G(z) of section 6.3.4 for the blocks d+1 to N:
+//
+//	G(z) = 1/v Σ_{t=d+1}^{N} Σ_{u=1}^{t} log2(u) F(z, t, u)
+//
+// with F(z, t, u) = z² (1 - z)^(u-1) if u < t, and z (1 - z)^(t-1) if u = t. The terms
+// u < t are regrouped by u, so that G is computed in O(N). logs holds log2(u) for u up to N.
+func compressionG(zz float64, logs []float64, d int) float64 {
+	blocks := len(logs) - 1
+	sum := 0.0
+	power := 1.0 // (1 - z)^(u-1)
+	for u := 1; u <= blocks; u++ {
+		if u < blocks {
+			// the number of t in [d+1, N] with t > u
+			sum += logs[u] * zz * zz * power * float64(blocks-max(u, d))
+		}
+		if u > d {
+			sum += logs[u] * zz * power
+		}
+		power *= 1 - zz
+		if power < 1e-300 {
+			// the remaining terms are negligible, and slow to compute as subnormal numbers
+			break
+		}
+	}
+	return sum / float64(blocks-d)
+}
+
+// solveDecreasing finds by bisection the p in [lo, hi] such that f(p) = target, for a decreasing f.
+// It returns false when target is above f(lo), hi when it is below f(hi).
+func solveDecreasing(f func(float64) float64, target, lo, hi float64) (float64, bool) {
+	if target > f(lo) {
+		return 0, false
+	}
+	if target <= f(hi) {
+		return hi, true
+	}
+	for i := 0; i < 60; i++ {
+		mid := (lo + hi) / 2
+		if f(mid) > target {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2, true
+}
@@ -0,0 +1,86 @@
+package entropy
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMostCommonValue(t *testing.T) {
+	// the value 1 occurs 8 times out of 20
+	s := &Samples{Data: []byte{0, 1, 1, 2, 0, 1, 2, 2, 0, 1, 0, 1, 1, 0, 2, 2, 1, 0, 2, 1}, Bits: 2}
+	e, err := MostCommonValue.Estimate(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := 0.4 + 2.576*math.Sqrt(0.4*0.6/19)
+	if math.Abs(e.P-p) > 1e-12 || math.Abs(e.MinEntropy+math.Log2(p)) > 1e-12 {
+		t.Errorf("got p = %f and %f bits, expected p = %f", e.P, e.MinEntropy, p)
+	}
+}
+
+func TestMarkov(t *testing.T) {
+	// alternating bits: the sequence 0101... has probability 1/2
+	s := &Samples{Data: make([]byte, 1000), Bits: 1}
+	for i := range s.Data {
+		s.Data[i] = byte(i % 2)
+	}
+	e, err := Markov.Estimate(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(e.MinEntropy-1.0/128) > 1e-12 {
+		t.Errorf("got %f bits, expected 1/128", e.MinEntropy)
+	}
+}
+
+func TestCollision(t *testing.T) {
+	// alternating bits never collide after 2 samples: t_i = 3 for every i, the most favorable case
+	s := &Samples{Data: make([]byte, 3000), Bits: 1}
+	for i := range s.Data {
+		s.Data[i] = byte(i % 2)
+	}
+	e, err := Collision.Estimate(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.P != 0.5 {
+		t.Errorf("got p = %f, expected 0.5", e.P)
+	}
+
+	// 00 010 repeated: 200 collisions after 2 samples and 200 after 3, so X̄ = 2.5,
+	// σ̂ = sqrt(400 × 0.25 / 399) and X̄' = 2 + 2p(1 - p)
+	s = &Samples{Data: make([]byte, 1000), Bits: 1}
+	for i := range s.Data {
+		if i%5 == 3 {
+			s.Data[i] = 1
+		}
+	}
+	e, err = Collision.Estimate(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bound := 2.5 - 2.576*math.Sqrt(100.0/399)/20
+	p := 0.5 + math.Sqrt(0.25-(bound-2)/2)
+	if math.Abs(e.P-p) > 1e-12 || math.Abs(e.P-0.679556) > 1e-6 {
+		t.Errorf("got p = %f, expected %f", e.P, p)
+	}
+
+	if _, err := Collision.Estimate(&Samples{Data: []byte{0, 1, 0}, Bits: 1}); err == nil {
+		t.Error("expected an error for a single collision")
+	}
+}
+
+func TestCompression(t *testing.T) {
+	if _, err := Compression.Estimate(biasedBits(6000, 0.5, 1)); err == nil {
+		t.Error("expected an error for 1000 blocks")
+	}
+
+	// the probability of the most likely 6-bit block of unbiased bits is 2^-6
+	e, err := Compression.Estimate(biasedBits(600000, 0.5, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.MinEntropy < 0.8 || e.MinEntropy > 1 {
+		t.Errorf("got %f bits, expected between 0.8 and 1", e.MinEntropy)
+	}
+}
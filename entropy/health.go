@@ -0,0 +1,313 @@
+package entropy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	b "github.com/notJoon/drbg/bitstream"
+)
+
+// ErrHealthTestFailed is wrapped by the errors of the health tests.
+var ErrHealthTestFailed = errors.New("health test failed")
+
+// The names of the health tests of section 4.4.
+const (
+	RepetitionCountName    = "Repetition Count Test"
+	AdaptiveProportionName = "Adaptive Proportion Test"
+)
+
+// HealthError reports the sample that made a health test fail.
+type HealthError struct {
+	Test   string // RepetitionCountName or AdaptiveProportionName
+	Sample int64  // the index of the sample, from 0
+	Value  byte   // the value of the sample
+	Count  int    // the count of the value, which reached the cutoff
+	Cutoff int
+}
+
+func (e *HealthError) Error() string {
+	return fmt.Sprintf("%s failed at sample %d: the value %d occurred %d times (cutoff %d)", e.Test, e.Sample, e.Value, e.Count, e.Cutoff)
+}
+
+func (e *HealthError) Unwrap() error {
+	return ErrHealthTestFailed
+}
+
+// checkHealthParameters checks an assessed min-entropy per sample and a false positive
+// probability.
+func checkHealthParameters(h, alpha float64) error {
+	if !(h > 0) || h > 8 {
+		return fmt.Errorf("the min-entropy per sample must be in (0, 8], got %v", h)
+	}
+	if !(alpha > 0) || alpha >= 1 {
+		return fmt.Errorf("the false positive probability must be in (0, 1), got %v", alpha)
+	}
+	return nil
+}
+
+// RepetitionCountCutoff returns the cutoff of the Repetition Count Test for a min-entropy of h
+// bits per sample and a false positive probability alpha (section 4.4.1): C = 1 + ⌈-log2(α) / H⌉.
+func RepetitionCountCutoff(h, alpha float64) (int, error) {
+	if err := checkHealthParameters(h, alpha); err != nil {
+		return 0, err
+	}
+	return 1 + int(math.Ceil(-math.Log2(alpha)/h)), nil
+}
+
+// AdaptiveProportionWindow returns the window size W of the Adaptive Proportion Test:
+// 1024 for binary samples, 512 otherwise.
+func AdaptiveProportionWindow(bits int) int {
+	if bits == 1 {
+		return 1024
+	}
+	return 512
+}
+
+// AdaptiveProportionCutoff returns the cutoff of the Adaptive Proportion Test for a min-entropy
+// of h bits per sample, a false positive probability alpha and a window of w samples (section
+// 4.4.2): C = 1 + CRITBINOM(W, 2^-H, 1 - α).
+func AdaptiveProportionCutoff(h, alpha float64, w int) (int, error) {
+	if err := checkHealthParameters(h, alpha); err != nil {
+		return 0, err
+	}
+	if w < 2 {
+		return 0, fmt.Errorf("the window must hold at least 2 samples, got %d", w)
+	}
+	return 1 + critBinom(w, math.Exp2(-h), alpha), nil
+}
+
+// critBinom returns CRITBINOM(n, p, 1 - α), the smallest k such that a binomial variable of n
+// trials with probability p exceeds k with probability at most α. The upper tail is summed from
+// n down, so that it stays accurate for α far below the rounding error of 1 - α.
+func critBinom(n int, p, alpha float64) int {
+	lgN, _ := math.Lgamma(float64(n + 1))
+	pmf := func(i int) float64 {
+		lgI, _ := math.Lgamma(float64(i + 1))
+		lgNI, _ := math.Lgamma(float64(n - i + 1))
+		return math.Exp(lgN - lgI - lgNI + float64(i)*math.Log(p) + float64(n-i)*math.Log1p(-p))
+	}
+	tail := 0.0
+	for k := n - 1; k >= 0; k-- {
+		tail += pmf(k + 1)
+		if tail > alpha {
+			return k + 1
+		}
+	}
+	return 0
+}
+
+// RepetitionCountTest is the Repetition Count Test of section 4.4.1: it fails when a value
+// repeats C times in a row. Once failed, it keeps failing until Reset.
+type RepetitionCountTest struct {
+	cutoff int
+	last   byte
+	count  int   // the number of consecutive samples equal to last
+	n      int64 // the number of samples seen
+	err    error
+}
+
+// NewRepetitionCountTest returns a Repetition Count Test for a source of h bits of min-entropy
+// per sample, with a false positive probability alpha, e.g. 2^-20 to 2^-40.
+func NewRepetitionCountTest(h, alpha float64) (*RepetitionCountTest, error) {
+	cutoff, err := RepetitionCountCutoff(h, alpha)
+	if err != nil {
+		return nil, err
+	}
+	return &RepetitionCountTest{cutoff: cutoff}, nil
+}
+
+// Cutoff returns the number of repetitions that makes the test fail.
+func (t *RepetitionCountTest) Cutoff() int {
+	return t.cutoff
+}
+
+// Add feeds the next sample to the test. It returns a *HealthError when the test fails.
+func (t *RepetitionCountTest) Add(sample byte) error {
+	if t.err != nil {
+		return t.err
+	}
+	if t.n > 0 && sample == t.last {
+		t.count++
+	} else {
+		t.last, t.count = sample, 1
+	}
+	t.n++
+	if t.count >= t.cutoff {
+		t.err = &HealthError{Test: RepetitionCountName, Sample: t.n - 1, Value: sample, Count: t.count, Cutoff: t.cutoff}
+	}
+	return t.err
+}
+
+// Reset restarts the test, e.g. after the source was reinitialized.
+func (t *RepetitionCountTest) Reset() {
+	*t = RepetitionCountTest{cutoff: t.cutoff}
+}
+
+// AdaptiveProportionTest is the Adaptive Proportion Test of section 4.4.2: it fails when the
+// first value of a window of W samples occurs C times in the window. Once failed, it keeps
+// failing until Reset.
+type AdaptiveProportionTest struct {
+	cutoff, window int
+	first          byte  // the first value of the current window
+	count          int   // the occurrences of first in the window
+	i              int   // the position in the window
+	n              int64 // the number of samples seen
+	err            error
+}
+
+// NewAdaptiveProportionTest returns an Adaptive Proportion Test for a source of h bits of
+// min-entropy per sample of the given width, with a false positive probability alpha.
+func NewAdaptiveProportionTest(h, alpha float64, bits int) (*AdaptiveProportionTest, error) {
+	if bits < 1 || bits > 8 {
+		return nil, fmt.Errorf("%w, got %d", ErrInvalidSampleSize, bits)
+	}
+	if h > float64(bits) {
+		return nil, fmt.Errorf("the min-entropy per sample %v exceeds the %d bits of a sample", h, bits)
+	}
+	window := AdaptiveProportionWindow(bits)
+	cutoff, err := AdaptiveProportionCutoff(h, alpha, window)
+	if err != nil {
+		return nil, err
+	}
+	return &AdaptiveProportionTest{cutoff: cutoff, window: window}, nil
+}
+
+// Cutoff returns the number of occurrences in a window that makes the test fail.
+func (t *AdaptiveProportionTest) Cutoff() int {
+	return t.cutoff
+}
+
+// Window returns the number of samples of a window.
+func (t *AdaptiveProportionTest) Window() int {
+	return t.window
+}
+
+// Add feeds the next sample to the test. It returns a *HealthError when the test fails.
+func (t *AdaptiveProportionTest) Add(sample byte) error {
+	if t.err != nil {
+		return t.err
+	}
+	if t.i == 0 {
+		t.first, t.count = sample, 1
+	} else if sample == t.first {
+		t.count++
+	}
+	t.i = (t.i + 1) % t.window
+	t.n++
+	if t.count >= t.cutoff {
+		t.err = &HealthError{Test: AdaptiveProportionName, Sample: t.n - 1, Value: sample, Count: t.count, Cutoff: t.cutoff}
+	}
+	return t.err
+}
+
+// Reset restarts the test, e.g. after the source was reinitialized.
+func (t *AdaptiveProportionTest) Reset() {
+	*t = AdaptiveProportionTest{cutoff: t.cutoff, window: t.window}
+}
+
+// HealthTests runs the Repetition Count Test and the Adaptive Proportion Test on the same
+// samples, as an entropy source does continuously.
+type HealthTests struct {
+	RCT  *RepetitionCountTest
+	APT  *AdaptiveProportionTest
+	Bits int // the number of bits per sample
+}
+
+// NewHealthTests returns the health tests of a source of samples of bits bits with h bits of
+// min-entropy per sample, with a false positive probability alpha for each test.
+func NewHealthTests(h, alpha float64, bits int) (*HealthTests, error) {
+	apt, err := NewAdaptiveProportionTest(h, alpha, bits)
+	if err != nil {
+		return nil, err
+	}
+	rct, err := NewRepetitionCountTest(h, alpha)
+	if err != nil {
+		return nil, err
+	}
+	return &HealthTests{RCT: rct, APT: apt, Bits: bits}, nil
+}
+
+// Add feeds the next sample to both tests. It returns a *HealthError when one of them fails.
+func (t *HealthTests) Add(sample byte) error {
+	if int(sample) >= 1<<t.Bits {
+		return fmt.Errorf("sample %d does not fit in %d bits", sample, t.Bits)
+	}
+	if err := t.RCT.Add(sample); err != nil {
+		return err
+	}
+	return t.APT.Add(sample)
+}
+
+// Reset restarts both tests.
+func (t *HealthTests) Reset() {
+	t.RCT.Reset()
+	t.APT.Reset()
+}
+
+// CheckBits feeds the samples read from src, Bits bits each and most significant bit first, to
+// the tests until io.EOF or a failure. It returns the number of samples checked; trailing bits
+// that do not fill a sample are ignored.
+func (t *HealthTests) CheckBits(src b.BitSource) (int64, error) {
+	var n int64
+	for {
+		var sample byte
+		for j := 0; j < t.Bits; j++ {
+			bit, err := src.ReadBit()
+			if err == io.EOF {
+				return n, nil
+			}
+			if err != nil {
+				return n, err
+			}
+			sample = sample<<1 | bit
+		}
+		if err := t.Add(sample); err != nil {
+			return n, err
+		}
+		n++
+	}
+}
+
+// HealthReader checks the samples read through it with the health tests. The bytes of the
+// underlying reader are split into samples of Bits bits, most significant bit first; with 8-bit
+// samples every byte is a sample.
+type HealthReader struct {
+	r     io.Reader
+	tests *HealthTests
+	acc   uint16 // the bits of the sample being assembled
+	nacc  int    // their number
+	err   error
+}
+
+// NewHealthReader returns a reader checking the samples of r with the tests.
+func NewHealthReader(r io.Reader, tests *HealthTests) *HealthReader {
+	return &HealthReader{r: r, tests: tests}
+}
+
+// Read reads from the underlying reader and checks the samples. When a test fails, it returns
+// the bytes before the one completing the failing sample, and the *HealthError; every later
+// call returns the same error.
+func (h *HealthReader) Read(p []byte) (int, error) {
+	if h.err != nil {
+		return 0, h.err
+	}
+	n, err := h.r.Read(p)
+	for i, c := range p[:n] {
+		for j := 7; j >= 0; j-- {
+			h.acc = h.acc<<1 | uint16(c>>uint(j)&1)
+			h.nacc++
+			if h.nacc < h.tests.Bits {
+				continue
+			}
+			sample := byte(h.acc)
+			h.acc, h.nacc = 0, 0
+			if terr := h.tests.Add(sample); terr != nil {
+				h.err = terr
+				return i, terr
+			}
+		}
+	}
+	return n, err
+}
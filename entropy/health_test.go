@@ -0,0 +1,156 @@
+package entropy
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"testing"
+
+	b "github.com/notJoon/drbg/bitstream"
+)
+
+func TestHealthCutoffs(t *testing.T) {
+	alpha := math.Exp2(-20)
+	if c, _ := RepetitionCountCutoff(1, alpha); c != 21 {
+		t.Errorf("RCT cutoff for H = 1: %d, expected 21", c)
+	}
+	if c, _ := RepetitionCountCutoff(0.5, math.Exp2(-40)); c != 81 {
+		t.Errorf("RCT cutoff for H = 0.5 and α = 2^-40: %d, expected 81", c)
+	}
+
+	// table 2 of section 4.4.2, α = 2^-20
+	for _, tc := range []struct {
+		h    float64
+		w    int
+		want int
+	}{
+		{1, 1024, 589},
+		{0.5, 512, 410},
+		{1, 512, 311},
+		{2, 512, 177},
+		{4, 512, 62},
+		{8, 512, 13},
+	} {
+		if c, _ := AdaptiveProportionCutoff(tc.h, alpha, tc.w); c != tc.want {
+			t.Errorf("APT cutoff for H = %v and W = %d: %d, expected %d", tc.h, tc.w, c, tc.want)
+		}
+	}
+
+	for _, h := range []float64{0, -1, 9, math.NaN()} {
+		if _, err := RepetitionCountCutoff(h, alpha); err == nil {
+			t.Errorf("no error for H = %v", h)
+		}
+	}
+	if _, err := NewAdaptiveProportionTest(2, alpha, 1); err == nil {
+		t.Error("no error for 2 bits of min-entropy in binary samples")
+	}
+}
+
+func TestRepetitionCountTest(t *testing.T) {
+	rct, err := NewRepetitionCountTest(8, math.Exp2(-20)) // cutoff 4
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range []byte{1, 2, 2, 2, 3, 3, 3} {
+		if err := rct.Add(v); err != nil {
+			t.Fatalf("sample %d: %v", i, err)
+		}
+	}
+	err = rct.Add(3)
+	var he *HealthError
+	if !errors.Is(err, ErrHealthTestFailed) || !errors.As(err, &he) {
+		t.Fatalf("got %v, expected a health test failure", err)
+	}
+	if he.Test != RepetitionCountName || he.Sample != 7 || he.Value != 3 || he.Count != 4 {
+		t.Errorf("got %+v, expected the 4th repetition of 3 at sample 7", he)
+	}
+	if rct.Add(5) != err {
+		t.Error("the test does not keep failing")
+	}
+	rct.Reset()
+	if err := rct.Add(3); err != nil {
+		t.Errorf("after Reset: %v", err)
+	}
+}
+
+func TestAdaptiveProportionTest(t *testing.T) {
+	apt, err := NewAdaptiveProportionTest(8, math.Exp2(-20), 8) // cutoff 13, window 512
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first value of the window occurs 12 times, then the next window starts over
+	var samples []byte
+	for i := 0; i < 512; i++ {
+		v := byte(10 + i%200)
+		if i%43 == 0 {
+			v = 7
+		}
+		samples = append(samples, v)
+	}
+	samples = append(samples, bytes.Repeat([]byte{9}, 12)...)
+	for i, v := range samples {
+		if err := apt.Add(v); err != nil {
+			t.Fatalf("sample %d: %v", i, err)
+		}
+	}
+
+	err = apt.Add(9)
+	var he *HealthError
+	if !errors.As(err, &he) || he.Test != AdaptiveProportionName || he.Sample != 524 || he.Count != 13 {
+		t.Errorf("got %v, expected the 13th 9 of the second window at sample 524", err)
+	}
+}
+
+func TestHealthTestsRandom(t *testing.T) {
+	tests, err := NewHealthTests(8, math.Exp2(-30), 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := make([]byte, 1000000)
+	rand.New(rand.NewSource(1)).Read(data)
+	n, err := io.Copy(io.Discard, NewHealthReader(bytes.NewReader(data), tests))
+	if err != nil || n != int64(len(data)) {
+		t.Errorf("uniform bytes: %d bytes, %v", n, err)
+	}
+}
+
+func TestHealthReader(t *testing.T) {
+	// 1-bit samples: 3 bytes of alternating bits, then a run of ones
+	data := []byte{0x55, 0x55, 0x55, 0xff, 0xff, 0xff, 0xff}
+	newTests := func() *HealthTests {
+		tests, err := NewHealthTests(1, math.Exp2(-20), 1) // RCT cutoff 21
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tests
+	}
+
+	r := NewHealthReader(bytes.NewReader(data), newTests())
+	buf := make([]byte, 16)
+	n, err := r.Read(buf)
+	var he *HealthError
+	if !errors.As(err, &he) || he.Test != RepetitionCountName {
+		t.Fatalf("got %v, expected a Repetition Count Test failure", err)
+	}
+	// the run starts with the last bit of the third byte, sample 23: its 21st sample is 43,
+	// in the sixth byte
+	if he.Sample != 43 || n != 5 {
+		t.Errorf("failure at sample %d after %d bytes, expected sample 43 after 5 bytes", he.Sample, n)
+	}
+	if _, err2 := r.Read(buf); err2 != err {
+		t.Errorf("the reader does not keep failing: %v", err2)
+	}
+
+	// the same samples fed bit by bit
+	n64, err := newTests().CheckBits(b.NewBitStreamReader(b.NewBitStream(data)))
+	if !errors.As(err, &he) || he.Sample != 43 || n64 != 43 {
+		t.Errorf("CheckBits: %d samples, %v", n64, err)
+	}
+	n64, err = newTests().CheckBits(b.NewBitStreamReader(b.NewBitStream(data[:3])))
+	if err != nil || n64 != 24 {
+		t.Errorf("CheckBits without the run: %d samples, %v", n64, err)
+	}
+}
@@ -0,0 +1,398 @@
+package entropy
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// IIDOptions configures the permutation testing of section 5.1.
+type IIDOptions struct {
+	Permutations int   // the number of shuffles, 10000 in SP 800-90B
+	Seed         int64 // the seed of the shuffles: the same seed gives the same shuffles
+	Workers      int   // the number of shuffles tested concurrently, GOMAXPROCS if 0 or less
+}
+
+// DefaultIIDOptions returns the options of SP 800-90B.
+func DefaultIIDOptions() IIDOptions {
+	return IIDOptions{Permutations: 10000}
+}
+
+// permutationLags are the lags p of the periodicity and covariance statistics.
+var permutationLags = []int{1, 2, 8, 16, 32}
+
+// permutationStatistics are the names of the statistics of section 5.1, in the order of
+// the values computed by statisticsContext.compute.
+var permutationStatistics = func() []string {
+	names := []string{
+		"Excursion",
+		"Number of Directional Runs",
+		"Length of Directional Runs",
+		"Number of Increases and Decreases",
+		"Number of Runs Based on the Median",
+		"Length of Runs Based on the Median",
+		"Average Collision",
+		"Maximum Collision",
+	}
+	for _, p := range permutationLags {
+		names = append(names, fmt.Sprintf("Periodicity (lag %d)", p))
+	}
+	for _, p := range permutationLags {
+		names = append(names, fmt.Sprintf("Covariance (lag %d)", p))
+	}
+	return append(names, "Compression")
+}()
+
+// permutationBatch is the number of shuffles between two checks of the early stop. The
+// shuffles are tested in batches so that the counts do not depend on the number of workers.
+const permutationBatch = 100
+
+// PermutationResult is the outcome of one statistic of the permutation testing.
+type PermutationResult struct {
+	Name    string
+	T       float64 // the statistic of the original samples
+	Greater int     // C_0, the number of shuffles whose statistic is greater than T
+	Equal   int     // C_1, the number of shuffles whose statistic is equal to T
+	Pass    bool    // false if T is among the 0.05% largest or smallest statistics
+}
+
+// ChiSquareResult is the outcome of a chi-square test of section 5.2.
+type ChiSquareResult struct {
+	Name string
+	T    float64 // the χ² statistic
+	DF   int     // the degrees of freedom
+	P    float64 // the p-value
+	Pass bool    // P is at least 0.001
+	Err  error   // why the test could not be run; it then passes
+}
+
+// LRSResult is the outcome of the longest repeated substring test of section 5.2.5.
+type LRSResult struct {
+	W    int     // the length of the longest repeated substring
+	PCol float64 // the collision probability of the samples
+	P    float64 // the probability of a repeated substring of length W among IID samples
+	Pass bool    // P is at least 0.001
+}
+
+// IIDResult is the outcome of the IID tests of section 5.
+type IIDResult struct {
+	// Permutations is the number of shuffles tested. It is less than the requested number
+	// when every statistic had passed, as more shuffles cannot change the outcome.
+	Permutations int
+	Statistics   []*PermutationResult
+
+	Independence  *ChiSquareResult
+	GoodnessOfFit *ChiSquareResult
+	LRS           *LRSResult
+
+	IID bool // every test passed: the IID assumption is not rejected
+}
+
+// CheckIID runs the IID tests of section 5 on the samples: the permutation testing of section
+// 5.1, and the chi-square and longest repeated substring tests of section 5.2. The statistics
+// of the shuffles are computed concurrently by opts.Workers goroutines; each shuffle draws from
+// its own generator seeded by opts.Seed and its index, so the result does not depend on the
+// number of workers.
+func CheckIID(s *Samples, opts IIDOptions) (*IIDResult, error) {
+	if s.Bits < 1 || s.Bits > 8 {
+		return nil, fmt.Errorf("%w, got %d", ErrInvalidSampleSize, s.Bits)
+	}
+	if s.Len() < 2 {
+		return nil, ErrNotEnoughSamples
+	}
+	if opts.Permutations < 1 {
+		return nil, fmt.Errorf("the number of permutations must be positive, got %d", opts.Permutations)
+	}
+
+	res := &IIDResult{}
+	res.Permutations, res.Statistics = permutationTests(s, opts)
+	if s.Bits == 1 {
+		res.Independence = binaryIndependence(s)
+		res.GoodnessOfFit = binaryGoodnessOfFit(s)
+	} else {
+		res.Independence = independence(s)
+		res.GoodnessOfFit = goodnessOfFit(s)
+	}
+	res.LRS = lrsTest(s)
+
+	res.IID = res.Independence.Pass && res.GoodnessOfFit.Pass && res.LRS.Pass
+	for _, st := range res.Statistics {
+		res.IID = res.IID && st.Pass
+	}
+	return res, nil
+}
+
+// permutationTests runs the permutation testing of section 5.1. A statistic rejects the IID
+// assumption when C_0 + C_1 ≤ 5 or C_0 ≥ n - 5 for n shuffles; the shuffles stop early once
+// no statistic can be rejected any more.
+func permutationTests(s *Samples, opts IIDOptions) (int, []*PermutationResult) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	ctx := newStatisticsContext(s)
+	original := make([]float64, len(permutationStatistics))
+	ctx.compute(s.Data, original, &statisticsBuffers{})
+
+	results := make([]*PermutationResult, len(original))
+	for i, name := range permutationStatistics {
+		results[i] = &PermutationResult{Name: name, T: original[i]}
+	}
+	decided := func(done int) bool {
+		for _, r := range results {
+			if r.Greater+r.Equal <= 5 || done-r.Greater <= 5 {
+				return false
+			}
+		}
+		return true
+	}
+
+	buffers := make([]*statisticsBuffers, workers)
+	for i := range buffers {
+		buffers[i] = &statisticsBuffers{}
+	}
+	done := 0
+	for done < opts.Permutations && !decided(done) {
+		batch := min(permutationBatch, opts.Permutations-done)
+		stats := make([][]float64, batch)
+
+		var wg sync.WaitGroup
+		next := make(chan int)
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func(buf *statisticsBuffers) {
+				defer wg.Done()
+				for j := range next {
+					stats[j] = make([]float64, len(original))
+					ctx.compute(shuffle(s.Data, opts.Seed+int64(done+j), buf), stats[j], buf)
+				}
+			}(buffers[w])
+		}
+		for j := 0; j < batch; j++ {
+			next <- j
+		}
+		close(next)
+		wg.Wait()
+
+		for _, st := range stats {
+			for i, t := range st {
+				switch {
+				case t > original[i]:
+					results[i].Greater++
+				case t == original[i]:
+					results[i].Equal++
+				}
+			}
+		}
+		done += batch
+	}
+
+	for _, r := range results {
+		r.Pass = r.Greater+r.Equal > 5 && r.Greater < opts.Permutations-5
+	}
+	return done, results
+}
+
+// shuffle returns a Fisher-Yates shuffle of the samples drawn from a generator seeded with
+// seed, in the buffer of the worker.
+func shuffle(data []byte, seed int64, buf *statisticsBuffers) []byte {
+	buf.shuffled = append(buf.shuffled[:0], data...)
+	r := rand.New(rand.NewSource(seed))
+	r.Shuffle(len(buf.shuffled), func(i, j int) {
+		buf.shuffled[i], buf.shuffled[j] = buf.shuffled[j], buf.shuffled[i]
+	})
+	return buf.shuffled
+}
+
+// statisticsContext holds what the statistics of a shuffle share with the original samples:
+// the sum and the median are invariant under permutation.
+type statisticsContext struct {
+	binary bool
+	sum    int64
+	median float64
+}
+
+// statisticsBuffers are the buffers of a worker, reused from one shuffle to the next.
+type statisticsBuffers struct {
+	shuffled, weights, values, text []byte
+}
+
+func newStatisticsContext(s *Samples) *statisticsContext {
+	ctx := &statisticsContext{binary: s.Bits == 1}
+	var counts [256]int
+	for _, v := range s.Data {
+		ctx.sum += int64(v)
+		counts[v]++
+	}
+
+	if ctx.binary {
+		ctx.median = 0.5
+		return ctx
+	}
+	// the mean of the middle two values for an even number of samples
+	valueAt := func(rank int) int {
+		for v, c := range counts {
+			if rank < c {
+				return v
+			}
+			rank -= c
+		}
+		return 255
+	}
+	n := s.Len()
+	ctx.median = float64(valueAt((n-1)/2)+valueAt(n/2)) / 2
+	return ctx
+}
+
+// compute computes the statistics of section 5.1 on a permutation of the samples. For binary
+// samples, the directional runs, periodicity and covariance statistics use the Hamming weights
+// of the bytes (conversion I), and the collision statistics the values of the bytes
+// (conversion II).
+func (ctx *statisticsContext) compute(data []byte, out []float64, buf *statisticsBuffers) {
+	runsInput, collisionInput := data, data
+	if ctx.binary {
+		buf.weights, buf.values = buf.weights[:0], buf.values[:0]
+		for i := 0; i < len(data); i += 8 {
+			var weight, value byte
+			for j := 0; j < 8; j++ {
+				bit := byte(0)
+				if i+j < len(data) {
+					bit = data[i+j]
+				}
+				weight += bit
+				value = value<<1 | bit
+			}
+			buf.weights = append(buf.weights, weight)
+			buf.values = append(buf.values, value)
+		}
+		runsInput, collisionInput = buf.weights, buf.values
+	}
+
+	k := 0
+	put := func(v float64) {
+		out[k] = v
+		k++
+	}
+
+	put(excursion(data, ctx.sum))
+
+	// directional runs: -1 where the next value is smaller, +1 otherwise
+	runs, longest, decreases := directionalRuns(runsInput)
+	put(float64(runs))
+	put(float64(longest))
+	put(float64(max(decreases, max(len(runsInput)-1, 0)-decreases)))
+
+	runs, longest = medianRuns(data, ctx.median)
+	put(float64(runs))
+	put(float64(longest))
+
+	average, maximum := collisions(collisionInput)
+	put(average)
+	put(float64(maximum))
+
+	for _, p := range permutationLags {
+		periodicity := 0
+		for i := 0; i+p < len(runsInput); i++ {
+			if runsInput[i] == runsInput[i+p] {
+				periodicity++
+			}
+		}
+		put(float64(periodicity))
+	}
+	for _, p := range permutationLags {
+		var covariance int64
+		for i := 0; i+p < len(runsInput); i++ {
+			covariance += int64(runsInput[i]) * int64(runsInput[i+p])
+		}
+		put(float64(covariance))
+	}
+
+	// the samples written in decimal, separated by spaces, and compressed with bzip2 as the
+	// reference implementation does (block size 500 kB)
+	buf.text = buf.text[:0]
+	for i, v := range data {
+		if i > 0 {
+			buf.text = append(buf.text, ' ')
+		}
+		buf.text = strconv.AppendUint(buf.text, uint64(v), 10)
+	}
+	put(float64(len(bzip2Compress(buf.text, 5))))
+}
+
+// excursion returns the largest distance of the running sum from its expected value,
+// max_i |Σ_{j≤i} s_j - i X̄|, where X̄ is the mean of the samples. The distances are
+// compared as integers multiplied by the number of samples.
+func excursion(data []byte, sum int64) float64 {
+	n := int64(len(data))
+	var running, best int64
+	for i, v := range data {
+		running += int64(v)
+		d := running*n - int64(i+1)*sum
+		best = max(best, d, -d)
+	}
+	return float64(best) / float64(n)
+}
+
+// directionalRuns returns the number of runs and the length of the longest run of the
+// sequence s'_i = -1 if s_i > s_{i+1}, +1 otherwise, and the number of -1.
+func directionalRuns(data []byte) (runs, longest, decreases int) {
+	length := 0
+	for i := 0; i+1 < len(data); i++ {
+		down := data[i] > data[i+1]
+		if down {
+			decreases++
+		}
+		if i > 0 && down == (data[i-1] > data[i]) {
+			length++
+		} else {
+			runs++
+			length = 1
+		}
+		longest = max(longest, length)
+	}
+	return runs, longest, decreases
+}
+
+// medianRuns returns the number of runs and the length of the longest run of the sequence
+// s'_i = -1 if s_i < median, +1 otherwise.
+func medianRuns(data []byte, median float64) (runs, longest int) {
+	length := 0
+	for i, v := range data {
+		below := float64(v) < median
+		if i > 0 && below == (float64(data[i-1]) < median) {
+			length++
+		} else {
+			runs++
+			length = 1
+		}
+		longest = max(longest, length)
+	}
+	return runs, longest
+}
+
+// collisions splits the samples into consecutive segments ending at the first repeated value
+// of each, and returns the average and the largest length of the segments.
+func collisions(data []byte) (float64, int) {
+	var seen [256]int // the segment in which each value was last seen, from 1
+	segment, start := 1, 0
+	total, count, longest := 0, 0, 0
+	for i, v := range data {
+		if seen[v] == segment {
+			length := i - start + 1
+			total += length
+			count++
+			longest = max(longest, length)
+			segment++
+			start = i + 1
+			continue
+		}
+		seen[v] = segment
+	}
+	if count == 0 {
+		return 0, 0
+	}
+	return float64(total) / float64(count), longest
+}
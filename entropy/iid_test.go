@@ -0,0 +1,167 @@
+package entropy
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestPermutationStatistics(t *testing.T) {
+	// the examples of section 5.1
+	if got := excursion([]byte{2, 15, 4, 10, 9}, 40); got != 6 {
+		t.Errorf("excursion %f, expected 6", got)
+	}
+
+	runs, longest, decreases := directionalRuns([]byte{2, 2, 2, 5, 7, 7, 9, 3, 1, 4, 4})
+	if runs != 3 || longest != 6 || decreases != 2 {
+		t.Errorf("directional runs: %d runs, longest %d, %d decreases, expected 3, 6 and 2", runs, longest, decreases)
+	}
+
+	runs, longest = medianRuns([]byte{5, 15, 12, 1, 13, 9, 4}, 9)
+	if runs != 5 || longest != 2 {
+		t.Errorf("runs based on the median: %d runs, longest %d, expected 5 and 2", runs, longest)
+	}
+	if ctx := newStatisticsContext(&Samples{Data: []byte{5, 15, 12, 1, 13, 9, 4}, Bits: 4}); ctx.median != 9 {
+		t.Errorf("median %f, expected 9", ctx.median)
+	}
+	if ctx := newStatisticsContext(&Samples{Data: []byte{5, 15, 12, 1}, Bits: 4}); ctx.median != 8.5 {
+		t.Errorf("median %f, expected 8.5", ctx.median)
+	}
+
+	average, maximum := collisions([]byte{2, 1, 1, 2, 0, 1, 0, 1, 1, 2})
+	if average != 3 || maximum != 4 {
+		t.Errorf("collisions: average %f, maximum %d, expected 3 and 4", average, maximum)
+	}
+
+	s := &Samples{Data: []byte{2, 1, 2, 1, 0, 1, 0, 1, 1, 2}, Bits: 2}
+	out := make([]float64, len(permutationStatistics))
+	newStatisticsContext(s).compute(s.Data, out, &statisticsBuffers{})
+	for name, want := range map[string]float64{"Periodicity (lag 2)": 5, "Covariance (lag 2)": 9} {
+		for i, n := range permutationStatistics {
+			if n == name && out[i] != want {
+				t.Errorf("%s: %f, expected %f", name, out[i], want)
+			}
+		}
+	}
+}
+
+func TestCheckIID(t *testing.T) {
+	opts := IIDOptions{Permutations: 10000, Seed: 1}
+
+	res, err := CheckIID(uniformSamples(10000, 8, 5), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.IID {
+		for _, st := range res.Statistics {
+			t.Logf("%s: T = %f, C0 = %d, C1 = %d", st.Name, st.T, st.Greater, st.Equal)
+		}
+		t.Errorf("uniform samples rejected: %+v %+v %+v", res.Independence, res.GoodnessOfFit, res.LRS)
+	}
+	if res.Permutations >= opts.Permutations {
+		t.Errorf("%d permutations, expected an early stop", res.Permutations)
+	}
+
+	// a random walk: each sample is close to the previous one
+	r := rand.New(rand.NewSource(6))
+	walk := &Samples{Data: make([]byte, 10000), Bits: 8}
+	for i := 1; i < walk.Len(); i++ {
+		walk.Data[i] = walk.Data[i-1] + byte(r.Intn(9)) - 4
+	}
+	res, err = CheckIID(walk, IIDOptions{Permutations: 200, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.IID || res.Independence.Pass {
+		t.Error("random walk not rejected")
+	}
+	for _, st := range res.Statistics {
+		if st.Name == "Covariance (lag 1)" && st.Pass {
+			t.Errorf("%s passed: T = %f, C0 = %d", st.Name, st.T, st.Greater)
+		}
+	}
+}
+
+func TestCheckIIDBinary(t *testing.T) {
+	res, err := CheckIID(biasedBits(20000, 0.7, 7), DefaultIIDOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.IID {
+		t.Errorf("biased bits rejected: %+v %+v %+v", res.Independence, res.GoodnessOfFit, res.LRS)
+	}
+
+	// bits that repeat the previous one with probability 0.6
+	r := rand.New(rand.NewSource(8))
+	sticky := &Samples{Data: make([]byte, 20000), Bits: 1}
+	for i := 1; i < sticky.Len(); i++ {
+		sticky.Data[i] = sticky.Data[i-1]
+		if r.Float64() < 0.4 {
+			sticky.Data[i] ^= 1
+		}
+	}
+	res, err = CheckIID(sticky, IIDOptions{Permutations: 200})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.IID || res.Independence.Pass || !res.GoodnessOfFit.Pass {
+		t.Errorf("sticky bits: IID %v, independence %v, goodness of fit %v; expected the independence test to fail alone",
+			res.IID, res.Independence.Pass, res.GoodnessOfFit.Pass)
+	}
+}
+
+func TestCheckIIDDeterministic(t *testing.T) {
+	s := uniformSamples(2000, 3, 9)
+	one, err := CheckIID(s, IIDOptions{Permutations: 300, Seed: 42, Workers: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	four, err := CheckIID(s, IIDOptions{Permutations: 300, Seed: 42, Workers: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(one, four) {
+		t.Error("the results depend on the number of workers")
+	}
+}
+
+func TestChiSquare(t *testing.T) {
+	// the distribution drifts: the first half of the samples uses 4 values, the second half 8
+	r := rand.New(rand.NewSource(10))
+	drift := &Samples{Data: make([]byte, 20000), Bits: 3}
+	for i := range drift.Data {
+		drift.Data[i] = byte(r.Intn(4 + 4*(2*i/drift.Len())))
+	}
+	if res := goodnessOfFit(drift); res.Pass {
+		t.Errorf("drifting samples passed the goodness-of-fit test: %+v", res)
+	}
+	if res := goodnessOfFit(uniformSamples(20000, 3, 11)); !res.Pass || res.DF != 63 {
+		t.Errorf("uniform samples: %+v, expected a pass with 9 × 7 degrees of freedom", res)
+	}
+	if res := independence(uniformSamples(20000, 3, 12)); !res.Pass || res.DF != 64-8 {
+		t.Errorf("uniform samples: %+v, expected a pass with 64 - 8 degrees of freedom", res)
+	}
+
+	// a long repeated substring
+	s := uniformSamples(20000, 8, 13)
+	copy(s.Data[10000:], s.Data[:20])
+	if res := lrsTest(s); res.Pass || res.W < 20 {
+		t.Errorf("repeated substring of 20 samples: %+v", res)
+	}
+	if res := lrsTest(uniformSamples(20000, 8, 14)); !res.Pass {
+		t.Errorf("uniform samples: %+v", res)
+	}
+}
+
+func TestIID(t *testing.T) {
+	a, err := IID(uniformSamples(10000, 4, 15))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Original) != 1 || len(a.Bitstring) != 1 || a.Original[0].Name != MostCommonValue.Name {
+		t.Fatalf("estimates %v %v, expected the Most Common Value estimate alone", a.Original, a.Bitstring)
+	}
+	if a.MinEntropy < 3.5 || a.MinEntropy > 4 {
+		t.Errorf("min-entropy %f, expected between 3.5 and 4", a.MinEntropy)
+	}
+}
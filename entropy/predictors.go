@@ -0,0 +1,377 @@
+package entropy
+
+import (
+	"fmt"
+	"math"
+)
+
+// The prediction estimates (sections 6.3.7 to 6.3.10) run predictors over the samples, each
+// guessing the next sample from the previous ones, and bound the probability of the most likely
+// value by the global and local predictability of the samples.
+
+// predictionBound returns the upper bound on the probability of the most likely value from the
+// outcome of the N predictions, for samples with k possible values (section 6.3.7, steps 4 to 7):
+// the maximum of 1/k, the upper bound P'_global of the proportion of correct predictions, and
+// P_local, derived from the longest run of correct predictions.
+func predictionBound(correct []bool, k int) (float64, error) {
+	n := len(correct)
+	if n < 2 {
+		return 0, fmt.Errorf("%w: %d predictions", ErrNotEnoughSamples, n)
+	}
+
+	c, run, longest := 0, 0, 0
+	for _, ok := range correct {
+		if ok {
+			c++
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+
+	var pGlobal float64
+	if c == 0 {
+		pGlobal = 1 - math.Pow(0.01, 1/float64(n))
+	} else {
+		pGlobal = upperBound(float64(c)/float64(n), n)
+	}
+	return math.Max(math.Max(pGlobal, localBound(longest+1, n)), 1/float64(k)), nil
+}
+
+// localBound returns P_local, the probability p of a correct prediction for which the probability
+// of no run of r correct predictions in n predictions is 0.99:
+//
+//	0.99 = (1 - p x) / ((r + 1 - r x) q) · 1 / x^(n+1)
+//
+// with q = 1 - p, and x the root of 1 - x + q p^r x^(r+1) = 0 obtained by 10 iterations from x = 1.
+func localBound(r, n int) float64 {
+	// the logarithm of the probability of no run of r, which decreases with p
+	logNoRun := func(p float64) float64 {
+		q := 1 - p
+		x := 1.0
+		for i := 0; i < 10; i++ {
+			x = 1 + q*math.Pow(p, float64(r))*math.Pow(x, float64(r+1))
+		}
+		return math.Log(1-p*x) - math.Log((float64(r)+1-float64(r)*x)*q) - float64(n+1)*math.Log(x)
+	}
+
+	target := math.Log(0.99)
+	lo, hi := 0.0, 1.0
+	for i := 0; i < 60; i++ {
+		mid := (lo + hi) / 2
+		if logNoRun(mid) > target {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
+
+// Window sizes of the Multi Most Common in Window prediction estimate.
+var mcwWindows = [...]int{63, 255, 1023, 4095}
+
+// multiMCW is the MultiMCW Prediction Estimate (section 6.3.7). Four subpredictors predict the
+// most common value in the last 63, 255, 1023 and 4095 samples, the most recent one in case of a
+// tie; the prediction is that of the subpredictor with the most correct predictions so far.
+func multiMCW(s *Samples) (float64, error) {
+	L := s.Len()
+	if L <= mcwWindows[0]+1 {
+		return 0, fmt.Errorf("%w: %d samples, need more than %d", ErrNotEnoughSamples, L, mcwWindows[0]+1)
+	}
+	k := s.Alphabet()
+
+	counts := make([][]int, len(mcwWindows))
+	for j := range counts {
+		counts[j] = make([]int, k)
+	}
+	lastSeen := make([]int, k) // the last position of each value, 1-based
+	var scoreboard [len(mcwWindows)]int
+	winner := 0
+
+	correct := make([]bool, 0, L-mcwWindows[0])
+	for i := 0; i < L; i++ {
+		if i >= mcwWindows[0] {
+			var predictions [len(mcwWindows)]int
+			for j, w := range mcwWindows {
+				predictions[j] = -1
+				if i < w {
+					continue
+				}
+				best := 0
+				for v := 1; v < k; v++ {
+					c, cb := counts[j][v], counts[j][best]
+					if c > cb || c == cb && lastSeen[v] > lastSeen[best] {
+						best = v
+					}
+				}
+				predictions[j] = best
+			}
+			correct = append(correct, predictions[winner] == int(s.Data[i]))
+
+			for j, p := range predictions {
+				if p == int(s.Data[i]) {
+					scoreboard[j]++
+					if scoreboard[j] >= scoreboard[winner] {
+						winner = j
+					}
+				}
+			}
+		}
+
+		// slide the windows
+		v := s.Data[i]
+		lastSeen[v] = i + 1
+		for j, w := range mcwWindows {
+			counts[j][v]++
+			if i >= w {
+				counts[j][s.Data[i-w]]--
+			}
+		}
+	}
+	return predictionBound(correct, k)
+}
+
+// lagDepth is D, the number of subpredictors of the Lag prediction estimate.
+const lagDepth = 128
+
+// lag is the Lag Prediction Estimate (section 6.3.8). The subpredictor d predicts the sample
+// seen d samples earlier, for d from 1 to 128; the prediction is that of the subpredictor with
+// the most correct predictions so far.
+func lag(s *Samples) (float64, error) {
+	L := s.Len()
+	var scoreboard [lagDepth + 1]int
+	winner := 1
+
+	correct := make([]bool, 0, L-1)
+	for i := 1; i < L; i++ {
+		v := s.Data[i]
+		correct = append(correct, s.Data[i-winner] == v)
+		for d := 1; d <= lagDepth && d <= i; d++ {
+			if s.Data[i-d] == v {
+				scoreboard[d]++
+				if scoreboard[d] >= scoreboard[winner] {
+					winner = d
+				}
+			}
+		}
+	}
+	return predictionBound(correct, s.Alphabet())
+}
+
+// Parameters of the MultiMMC and LZ78Y prediction estimates.
+const (
+	mmcDepth          = 16     // D, the orders of the Markov models
+	mmcMaxEntries     = 100000 // the number of (context, value) entries of each model
+	lzDepth           = 16     // B, the longest context of LZ78Y
+	lzMaxDictionary   = 65536  // the number of contexts of LZ78Y
+	denseContextLimit = 20     // contexts of at most this many bits (and value) are stored in arrays
+)
+
+// multiMMC is the MultiMMC Prediction Estimate (section 6.3.9). The subpredictor d is a Markov
+// model of order d, from 1 to 16, counting the values that followed each context of d samples;
+// it predicts the value that most often followed the current context, the largest one in case
+// of a tie. The prediction is that of the subpredictor with the most correct predictions so far.
+func multiMMC(s *Samples) (float64, error) {
+	L := s.Len()
+	if L < 3 {
+		return 0, fmt.Errorf("%w: %d samples", ErrNotEnoughSamples, L)
+	}
+
+	models := make([]*contextTable, mmcDepth+1)
+	for d := 1; d <= mmcDepth; d++ {
+		models[d] = newContextTable(d, s.Bits)
+	}
+	var scoreboard [mmcDepth + 1]int
+	winner := 1
+
+	correct := make([]bool, 0, L-2)
+	for i := 2; i < L; i++ {
+		// count the transition from the contexts ending at i-2 to the sample i-1
+		ctx := contextKey{}
+		for d := 1; d <= mmcDepth && d < i; d++ {
+			ctx = ctx.extend(s.Data[i-1-d], d, s.Bits)
+			m := models[d]
+			if m.count(ctx, s.Data[i-1]) > 0 {
+				m.increment(ctx, s.Data[i-1])
+			} else if m.entries < mmcMaxEntries {
+				m.increment(ctx, s.Data[i-1])
+				m.entries++
+			}
+		}
+
+		// predict the sample i from the contexts ending at i-1
+		var predictions [mmcDepth + 1]int
+		ctx = contextKey{}
+		for d := 1; d <= mmcDepth; d++ {
+			predictions[d] = -1
+			if d > i {
+				continue
+			}
+			ctx = ctx.extend(s.Data[i-d], d, s.Bits)
+			if y, c := models[d].best(ctx); c > 0 {
+				predictions[d] = int(y)
+			}
+		}
+
+		v := int(s.Data[i])
+		correct = append(correct, predictions[winner] == v)
+		for d := 1; d <= mmcDepth; d++ {
+			if predictions[d] == v {
+				scoreboard[d]++
+				if scoreboard[d] >= scoreboard[winner] {
+					winner = d
+				}
+			}
+		}
+	}
+	return predictionBound(correct, s.Alphabet())
+}
+
+// lz78y is the LZ78Y Prediction Estimate (section 6.3.10). A dictionary of at most 65536 contexts
+// of 1 to 16 samples counts the values that followed each context; the prediction is the value
+// that most often followed one of the current contexts, the largest value in case of a tie and
+// the shortest context in case of equal counts.
+func lz78y(s *Samples) (float64, error) {
+	L := s.Len()
+	if L < lzDepth+2 {
+		return 0, fmt.Errorf("%w: %d samples, need at least %d", ErrNotEnoughSamples, L, lzDepth+2)
+	}
+
+	tables := make([]*contextTable, lzDepth+1)
+	for j := 1; j <= lzDepth; j++ {
+		tables[j] = newContextTable(j, s.Bits)
+	}
+	contexts := 0 // the number of contexts in the dictionary
+
+	correct := make([]bool, 0, L-lzDepth-1)
+	for i := lzDepth + 1; i < L; i++ {
+		// add the contexts ending at i-2, from the longest, and count the sample i-1 that followed them
+		var keys [lzDepth + 1]contextKey
+		for j := 1; j <= lzDepth; j++ {
+			keys[j] = keys[j-1].extend(s.Data[i-1-j], j, s.Bits)
+		}
+		for j := lzDepth; j >= 1; j-- {
+			t := tables[j]
+			if _, c := t.best(keys[j]); c > 0 {
+				t.increment(keys[j], s.Data[i-1])
+			} else if contexts < lzMaxDictionary {
+				contexts++
+				t.increment(keys[j], s.Data[i-1])
+			}
+		}
+
+		// predict from the contexts ending at i-1, from the longest
+		for j := 1; j <= lzDepth; j++ {
+			keys[j] = keys[j-1].extend(s.Data[i-j], j, s.Bits)
+		}
+		prediction, maxCount := -1, uint32(0)
+		for j := lzDepth; j >= 1; j-- {
+			if y, c := tables[j].best(keys[j]); c > 0 && c >= maxCount {
+				prediction, maxCount = int(y), c
+			}
+		}
+		correct = append(correct, prediction == int(s.Data[i]))
+	}
+	return predictionBound(correct, s.Alphabet())
+}
+
+// contextKey holds a context of up to 16 samples of 8 bits, the most recent sample in the low bits.
+type contextKey struct {
+	lo, hi uint64
+}
+
+// extend returns the context preceded by the sample v, the context having length-1 samples of bits bits.
+func (c contextKey) extend(v byte, length, bits int) contextKey {
+	shift := (length - 1) * bits
+	x := uint64(v)
+	switch {
+	case shift >= 64:
+		c.hi |= x << uint(shift-64)
+	case shift+bits > 64:
+		c.lo |= x << uint(shift)
+		c.hi |= x >> uint(64-shift)
+	default:
+		c.lo |= x << uint(shift)
+	}
+	return c
+}
+
+// contextTable counts the values that followed each context of a given length, keeping track
+// of the most frequent value of each context. Short contexts are stored in arrays.
+type contextTable struct {
+	k       int
+	entries int // the number of entries, as counted by the estimator
+
+	// arrays indexed by context, for contexts of at most denseContextLimit bits with the value
+	denseCounts []uint32
+	denseBest   []bestValue
+
+	sparseCounts map[contextValue]uint32
+	sparseBest   map[contextKey]bestValue
+}
+
+type bestValue struct {
+	value byte
+	count uint32
+}
+
+type contextValue struct {
+	ctx   contextKey
+	value byte
+}
+
+func newContextTable(length, bits int) *contextTable {
+	t := &contextTable{k: 1 << bits}
+	if length*bits+bits <= denseContextLimit {
+		contexts := 1 << (length * bits)
+		t.denseCounts = make([]uint32, contexts*t.k)
+		t.denseBest = make([]bestValue, contexts)
+	} else {
+		t.sparseCounts = make(map[contextValue]uint32)
+		t.sparseBest = make(map[contextKey]bestValue)
+	}
+	return t
+}
+
+func (t *contextTable) count(ctx contextKey, v byte) uint32 {
+	if t.denseCounts != nil {
+		return t.denseCounts[int(ctx.lo)*t.k+int(v)]
+	}
+	return t.sparseCounts[contextValue{ctx, v}]
+}
+
+// best returns the value that most often followed the context, the largest in case of a tie,
+// and its count; the count is 0 for contexts not in the table.
+func (t *contextTable) best(ctx contextKey) (byte, uint32) {
+	var b bestValue
+	if t.denseCounts != nil {
+		b = t.denseBest[ctx.lo]
+	} else {
+		b = t.sparseBest[ctx]
+	}
+	return b.value, b.count
+}
+
+func (t *contextTable) increment(ctx contextKey, v byte) {
+	var count uint32
+	var best bestValue
+	if t.denseCounts != nil {
+		i := int(ctx.lo)*t.k + int(v)
+		t.denseCounts[i]++
+		count, best = t.denseCounts[i], t.denseBest[ctx.lo]
+	} else {
+		key := contextValue{ctx, v}
+		t.sparseCounts[key]++
+		count, best = t.sparseCounts[key], t.sparseBest[ctx]
+	}
+	if count < best.count || count == best.count && v < best.value {
+		return
+	}
+	if t.denseCounts != nil {
+		t.denseBest[ctx.lo] = bestValue{v, count}
+	} else {
+		t.sparseBest[ctx] = bestValue{v, count}
+	}
+}
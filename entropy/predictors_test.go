@@ -0,0 +1,67 @@
+package entropy
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPredictorsPeriodic(t *testing.T) {
+	// a period of 7 bytes is predicted by every predictor after a while
+	s := &Samples{Data: make([]byte, 20000), Bits: 8}
+	period := []byte{3, 141, 59, 26, 5, 35, 89}
+	for i := range s.Data {
+		s.Data[i] = period[i%len(period)]
+	}
+	for _, e := range []Estimator{Lag, MultiMMC, LZ78Y} {
+		est, err := e.Estimate(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if est.MinEntropy > 0.01 {
+			t.Errorf("%s: %f bits, expected about 0", e.Name, est.MinEntropy)
+		}
+	}
+
+	// the most common value in a window of a periodic sequence is not the next one
+	est, err := MultiMCW.Estimate(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if est.MinEntropy < 2 {
+		t.Errorf("%s: %f bits, expected more than 2", est.Name, est.MinEntropy)
+	}
+}
+
+func TestPredictorsUniform(t *testing.T) {
+	s := uniformSamples(50000, 2, 4)
+	for _, e := range []Estimator{MultiMCW, Lag, MultiMMC, LZ78Y} {
+		est, err := e.Estimate(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if est.MinEntropy < 1.8 || est.MinEntropy > 2 {
+			t.Errorf("%s: %f bits, expected between 1.8 and 2", e.Name, est.MinEntropy)
+		}
+	}
+}
+
+func TestLocalBound(t *testing.T) {
+	// P_local solves 0.99 = (1 - p x) / ((r + 1 - r x) q) / x^(N+1)
+	for _, tc := range []struct{ r, n int }{{2, 1000}, {10, 100000}, {30, 1000000}} {
+		p := localBound(tc.r, tc.n)
+		q := 1 - p
+		x := 1.0
+		for i := 0; i < 10; i++ {
+			x = 1 + q*math.Pow(p, float64(tc.r))*math.Pow(x, float64(tc.r+1))
+		}
+		got := (1 - p*x) / ((float64(tc.r) + 1 - float64(tc.r)*x) * q) / math.Pow(x, float64(tc.n+1))
+		if math.Abs(got-0.99) > 1e-6 {
+			t.Errorf("r = %d, N = %d: p = %f gives %f, expected 0.99", tc.r, tc.n, p, got)
+		}
+	}
+
+	// longer runs need a larger probability of correct predictions
+	if localBound(5, 1000) >= localBound(10, 1000) {
+		t.Error("P_local does not increase with the longest run")
+	}
+}
@@ -0,0 +1,135 @@
+package entropy
+
+import (
+	"fmt"
+	"math"
+)
+
+// The dimensions of the restart matrix of section 3.1.4: 1000 restarts of 1000 samples.
+const (
+	RestartCount   = 1000
+	RestartSamples = 1000
+)
+
+// RestartResult is the outcome of the restart tests of section 3.1.4.
+type RestartResult struct {
+	Rows, Columns int     // the number of restarts and of samples per restart
+	HInitial      float64 // H_I, the min-entropy assessed on the sequential samples
+
+	// the sanity check: the most common value of each row (column) may occur at most
+	// RowCutoff (ColumnCutoff) times
+	Alpha        float64 // the significance level of each row and column, 0.01 / (2000 k)
+	RowMax       int     // the largest count of the most common value of a row
+	RowCutoff    int
+	ColumnMax    int
+	ColumnCutoff int
+	SanityPass   bool
+
+	// the estimates on the rows and on the columns, when the sanity check passes
+	RowAssessment    *Assessment
+	ColumnAssessment *Assessment
+
+	Pass       bool    // the sanity check passed and min(H_r, H_c) is at least H_I / 2
+	MinEntropy float64 // the final min-entropy per sample min(H_I, H_r, H_c), when Pass
+}
+
+// Restart runs the restart tests of section 3.1.4 on a matrix of samples, with one row of
+// samples per restart of the entropy source, and the min-entropy hInitial assessed on the
+// sequential samples. assess is the track of the sequential assessment, IID or NonIID: it
+// is run again on the rows and on the columns concatenated.
+func Restart(rows []*Samples, hInitial float64, assess func(*Samples) (*Assessment, error)) (*RestartResult, error) {
+	if len(rows) < 2 || rows[0].Len() < 2 {
+		return nil, fmt.Errorf("%w: the restart matrix needs at least 2 rows and 2 columns", ErrNotEnoughSamples)
+	}
+	bits, columns := rows[0].Bits, rows[0].Len()
+	for i, r := range rows {
+		if r.Bits != bits || r.Len() != columns {
+			return nil, fmt.Errorf("restart %d has %d samples of %d bits, expected %d samples of %d bits", i, r.Len(), r.Bits, columns, bits)
+		}
+	}
+	if !(hInitial > 0) || hInitial > float64(bits) {
+		return nil, fmt.Errorf("the initial min-entropy must be in (0, %d], got %v", bits, hInitial)
+	}
+
+	res := &RestartResult{Rows: len(rows), Columns: columns, HInitial: hInitial}
+
+	// the rows concatenated, and the columns concatenated
+	rowData := make([]byte, 0, len(rows)*columns)
+	for _, r := range rows {
+		rowData = append(rowData, r.Data...)
+	}
+	columnData := make([]byte, 0, len(rows)*columns)
+	for j := 0; j < columns; j++ {
+		for _, r := range rows {
+			columnData = append(columnData, r.Data[j])
+		}
+	}
+
+	// section 3.1.4.3: the frequency of the most common value of each row and column, against
+	// the binomial critical value for p = 2^-H_I with a Bonferroni correction over the 2000
+	// rows and columns of the k possible values
+	p := math.Exp2(-hInitial)
+	res.Alpha = 0.01 / (2000 * float64(int(1)<<bits))
+	res.RowCutoff = critBinom(columns, p, res.Alpha)
+	res.ColumnCutoff = critBinom(len(rows), p, res.Alpha)
+	res.RowMax = maxFrequency(rowData, columns)
+	res.ColumnMax = maxFrequency(columnData, len(rows))
+	res.SanityPass = res.RowMax <= res.RowCutoff && res.ColumnMax <= res.ColumnCutoff
+	if !res.SanityPass {
+		return res, nil
+	}
+
+	// section 3.1.4.2: the entropy estimates of the rows and of the columns
+	var err error
+	if res.RowAssessment, err = assess(&Samples{Data: rowData, Bits: bits}); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+	if res.ColumnAssessment, err = assess(&Samples{Data: columnData, Bits: bits}); err != nil {
+		return nil, fmt.Errorf("columns: %w", err)
+	}
+	hr, hc := res.RowAssessment.MinEntropy, res.ColumnAssessment.MinEntropy
+	res.Pass = math.Min(hr, hc) >= hInitial/2
+	if res.Pass {
+		res.MinEntropy = math.Min(hInitial, math.Min(hr, hc))
+	}
+	return res, nil
+}
+
+// maxFrequency returns the largest number of occurrences of the most common value among the
+// consecutive vectors of n samples of data.
+func maxFrequency(data []byte, n int) int {
+	best := 0
+	for i := 0; i+n <= len(data); i += n {
+		var counts [256]int
+		for _, v := range data[i : i+n] {
+			counts[v]++
+		}
+		for _, c := range counts {
+			best = max(best, c)
+		}
+	}
+	return best
+}
+
+// RestartRows splits samples holding a whole restart matrix into its rows, one per restart.
+// The samples of each restart are consecutive, or, if columnMajor, the first samples of every
+// restart come first, then their second samples, and so on.
+func RestartRows(s *Samples, restarts int, columnMajor bool) ([]*Samples, error) {
+	if restarts < 1 || s.Len()%restarts != 0 {
+		return nil, fmt.Errorf("%d samples cannot be split into %d restarts", s.Len(), restarts)
+	}
+	columns := s.Len() / restarts
+	rows := make([]*Samples, restarts)
+	for i := range rows {
+		data := make([]byte, columns)
+		for j := range data {
+			if columnMajor {
+				data[j] = s.Data[j*restarts+i]
+			} else {
+				data[j] = s.Data[i*columns+j]
+			}
+		}
+		rows[i] = &Samples{Data: data, Bits: s.Bits}
+	}
+	return rows, nil
+}
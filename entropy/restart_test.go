@@ -0,0 +1,65 @@
+package entropy
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRestart(t *testing.T) {
+	s := uniformSamples(300*300, 4, 16)
+	rows, err := RestartRows(s, 300, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := Restart(rows, 3.8, IID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.SanityPass || !res.Pass {
+		t.Fatalf("uniform samples rejected: %+v", res)
+	}
+	if res.RowCutoff != res.ColumnCutoff || res.RowMax > res.RowCutoff {
+		t.Errorf("row cutoff %d, column cutoff %d, largest count %d", res.RowCutoff, res.ColumnCutoff, res.RowMax)
+	}
+	hr, hc := res.RowAssessment.MinEntropy, res.ColumnAssessment.MinEntropy
+	if want := math.Min(3.8, math.Min(hr, hc)); res.MinEntropy != want {
+		t.Errorf("min-entropy %f, expected min(3.8, %f, %f)", res.MinEntropy, hr, hc)
+	}
+
+	// every restart starts with the same samples: the columns are nearly constant
+	for _, r := range rows[1:] {
+		copy(r.Data[:50], rows[0].Data[:50])
+	}
+	res, err = Restart(rows, 3.8, IID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.SanityPass || res.Pass || res.ColumnMax != 300 {
+		t.Errorf("repeated restarts passed the sanity check: %+v", res)
+	}
+
+	if _, err := Restart(rows[:1], 3.8, IID); err == nil {
+		t.Error("no error for a single restart")
+	}
+	if _, err := Restart(rows, 5, IID); err == nil {
+		t.Error("no error for 5 bits of min-entropy in 4-bit samples")
+	}
+}
+
+func TestRestartRows(t *testing.T) {
+	s := &Samples{Data: []byte{0, 1, 2, 3, 4, 5}, Bits: 3}
+	rows, err := RestartRows(s, 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(rows[0].Data) != "\x00\x01\x02" || string(rows[1].Data) != "\x03\x04\x05" {
+		t.Errorf("rows %v %v", rows[0].Data, rows[1].Data)
+	}
+	rows, _ = RestartRows(s, 2, true)
+	if string(rows[0].Data) != "\x00\x02\x04" || string(rows[1].Data) != "\x01\x03\x05" {
+		t.Errorf("column-major rows %v %v", rows[0].Data, rows[1].Data)
+	}
+	if _, err := RestartRows(s, 4, false); err == nil {
+		t.Error("no error for 6 samples in 4 restarts")
+	}
+}
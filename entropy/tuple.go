@@ -0,0 +1,230 @@
+package entropy
+
+import (
+	"fmt"
+	"math"
+)
+
+// tupleThreshold is the number of occurrences that bounds the tuple lengths of the t-Tuple and
+// LRS estimates: t-Tuple uses the tuples whose most common value occurs at least 35 times,
+// and LRS the longer ones.
+const tupleThreshold = 35
+
+// tupleStats holds, for each tuple length W from 1 to the length of the longest repeated
+// substring, the number of occurrences of the most common W-tuple and the number of pairs of
+// equal W-tuples (the overlapping tuples of the samples).
+type tupleStats struct {
+	maxCount []int     // maxCount[W], 1 for tuples that do not repeat
+	pairs    []float64 // pairs[W] = Σ_i C(C_i, 2) over the distinct W-tuples
+}
+
+// longest returns the length of the longest repeated substring.
+func (st *tupleStats) longest() int {
+	return len(st.maxCount) - 1
+}
+
+// newTupleStats computes the tuple counts from the suffix array of the samples: the suffixes
+// starting with the same W-tuple are adjacent in the suffix array, and form the intervals whose
+// longest common prefix is at least W.
+func newTupleStats(data []byte) *tupleStats {
+	sa := suffixArray(data)
+	lcp := lcpArray(data, sa)
+
+	longest := 0
+	for _, l := range lcp {
+		longest = max(longest, int(l))
+	}
+	best := make([]int, longest+2)
+	pairs := make([]float64, longest+2) // difference array over W
+
+	// bottom-up traversal of the lcp intervals: an interval of size n with longest common prefix
+	// h, nested in an interval with longest common prefix parent, is the set of suffixes starting
+	// with the same W-tuple for every W in (parent, h]
+	type interval struct{ h, lb int }
+	stack := []interval{{0, 0}}
+	for i := 1; i <= len(data); i++ {
+		h := 0
+		if i < len(data) {
+			h = int(lcp[i])
+		}
+		lb := i - 1
+		for stack[len(stack)-1].h > h {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			parent := max(h, stack[len(stack)-1].h)
+
+			n := i - top.lb
+			best[top.h] = max(best[top.h], n)
+			c := float64(n) * float64(n-1) / 2
+			pairs[parent+1] += c
+			pairs[top.h+1] -= c
+			lb = top.lb
+		}
+		if stack[len(stack)-1].h < h {
+			stack = append(stack, interval{h, lb})
+		}
+	}
+
+	st := &tupleStats{maxCount: make([]int, longest+1), pairs: make([]float64, longest+1)}
+	count, sum := 1, 0.0
+	for w := longest; w >= 1; w-- {
+		count = max(count, best[w])
+		st.maxCount[w] = count
+	}
+	for w := 1; w <= longest; w++ {
+		sum += pairs[w]
+		st.pairs[w] = sum
+	}
+	return st
+}
+
+// tTuple is the t-Tuple Estimate (section 6.3.5): for every tuple length i up to the largest t
+// whose most common t-tuple occurs at least 35 times, the proportion P_i of the most common i-tuple
+// gives the bound P_i^(1/i) on the probability of the most likely value.
+func tTuple(s *Samples) (float64, error) {
+	st := newTupleStats(s.Data)
+	if st.longest() < 1 || st.maxCount[1] < tupleThreshold {
+		return 0, fmt.Errorf("%w: no value occurs %d times", ErrNotEnoughSamples, tupleThreshold)
+	}
+
+	pMax := 0.0
+	L := s.Len()
+	for i := 1; i <= st.longest() && st.maxCount[i] >= tupleThreshold; i++ {
+		p := float64(st.maxCount[i]) / float64(L-i+1)
+		pMax = math.Max(pMax, math.Pow(p, 1/float64(i)))
+	}
+	return upperBound(pMax, L), nil
+}
+
+// longestRepeatedSubstring is the Longest Repeated Substring Estimate (section 6.3.6): for the
+// tuple lengths W from u, the smallest whose most common tuple occurs less than 35 times, to v,
+// the length of the longest repeated substring, the collision probability P_W of the W-tuples
+// gives the bound P_W^(1/W). It does not apply when u > v.
+func longestRepeatedSubstring(s *Samples) (float64, error) {
+	st := newTupleStats(s.Data)
+	u := 1
+	for u <= st.longest() && st.maxCount[u] >= tupleThreshold {
+		u++
+	}
+	v := st.longest()
+	if u > v {
+		return 0, fmt.Errorf("%w: u = %d is greater than the longest repeated substring, %d", ErrNotApplicable, u, v)
+	}
+
+	pMax := 0.0
+	L := s.Len()
+	for w := u; w <= v; w++ {
+		n := float64(L - w + 1)
+		p := st.pairs[w] / (n * (n - 1) / 2)
+		pMax = math.Max(pMax, math.Pow(p, 1/float64(w)))
+	}
+	return upperBound(pMax, L), nil
+}
+
+// suffixArray returns the starting positions of the suffixes of data in lexicographic order,
+// sorted by prefix doubling with radix sorts.
+func suffixArray(data []byte) []int32 {
+	n := len(data)
+	sa := make([]int32, n)
+	if n == 0 {
+		return sa
+	}
+	rank := make([]int32, n)
+	next := make([]int32, n)
+	tmp := make([]int32, n)
+
+	// sort by the first sample
+	var counts [257]int
+	for _, c := range data {
+		counts[int(c)+1]++
+	}
+	for i := 1; i < len(counts); i++ {
+		counts[i] += counts[i-1]
+	}
+	for i, c := range data {
+		sa[counts[c]] = int32(i)
+		counts[c]++
+	}
+	rank[sa[0]] = 0
+	for i := 1; i < n; i++ {
+		rank[sa[i]] = rank[sa[i-1]]
+		if data[sa[i]] != data[sa[i-1]] {
+			rank[sa[i]]++
+		}
+	}
+
+	bucket := make([]int, n+1)
+	for k := 1; int(rank[sa[n-1]]) < n-1; k <<= 1 {
+		// order by the rank of the second half: the suffixes shorter than k first
+		j := 0
+		for i := n - k; i < n; i++ {
+			tmp[j] = int32(i)
+			j++
+		}
+		for _, p := range sa {
+			if int(p) >= k {
+				tmp[j] = p - int32(k)
+				j++
+			}
+		}
+
+		// stable counting sort by the rank of the first half
+		clear(bucket)
+		for _, r := range rank {
+			bucket[r+1]++
+		}
+		for i := 1; i <= n; i++ {
+			bucket[i] += bucket[i-1]
+		}
+		for _, p := range tmp {
+			sa[bucket[rank[p]]] = p
+			bucket[rank[p]]++
+		}
+
+		// rank the pairs (rank[i], rank[i+k])
+		second := func(p int32) int32 {
+			if int(p)+k < n {
+				return rank[int(p)+k]
+			}
+			return -1
+		}
+		next[sa[0]] = 0
+		for i := 1; i < n; i++ {
+			a, b := sa[i-1], sa[i]
+			next[b] = next[a]
+			if rank[a] != rank[b] || second(a) != second(b) {
+				next[b]++
+			}
+		}
+		rank, next = next, rank
+	}
+	return sa
+}
+
+// lcpArray returns the length of the longest common prefix of each suffix of the suffix array
+// with the previous one (Kasai's algorithm); lcp[0] is 0.
+func lcpArray(data []byte, sa []int32) []int32 {
+	n := len(data)
+	rank := make([]int32, n)
+	for i, p := range sa {
+		rank[p] = int32(i)
+	}
+
+	lcp := make([]int32, n)
+	h := 0
+	for i := 0; i < n; i++ {
+		if rank[i] == 0 {
+			h = 0
+			continue
+		}
+		j := int(sa[rank[i]-1])
+		for i+h < n && j+h < n && data[i+h] == data[j+h] {
+			h++
+		}
+		lcp[rank[i]] = int32(h)
+		if h > 0 {
+			h--
+		}
+	}
+	return lcp
+}
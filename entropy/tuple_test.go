@@ -0,0 +1,110 @@
+package entropy
+
+import (
+	"bytes"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestSuffixArray(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, data := range [][]byte{
+		[]byte("banana"),
+		make([]byte, 100),
+		{1},
+		{},
+	} {
+		checkSuffixArray(t, data)
+	}
+	for i := 0; i < 20; i++ {
+		data := make([]byte, 1+r.Intn(500))
+		for j := range data {
+			data[j] = byte(r.Intn(1 + i%4))
+		}
+		checkSuffixArray(t, data)
+	}
+}
+
+func checkSuffixArray(t *testing.T, data []byte) {
+	t.Helper()
+	want := make([]int32, len(data))
+	for i := range want {
+		want[i] = int32(i)
+	}
+	sort.Slice(want, func(i, j int) bool { return bytes.Compare(data[want[i]:], data[want[j]:]) < 0 })
+
+	got := suffixArray(data)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("suffix array of %v: got %v, expected %v", data, got, want)
+		}
+	}
+
+	lcp := lcpArray(data, got)
+	for i := 1; i < len(data); i++ {
+		a, b := data[got[i-1]:], data[got[i]:]
+		h := 0
+		for h < len(a) && h < len(b) && a[h] == b[h] {
+			h++
+		}
+		if int(lcp[i]) != h {
+			t.Fatalf("lcp[%d] of %v: got %d, expected %d", i, data, lcp[i], h)
+		}
+	}
+}
+
+func TestTupleStats(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for i := 0; i < 20; i++ {
+		data := make([]byte, 1+r.Intn(2000))
+		for j := range data {
+			data[j] = byte(r.Intn(1 + i%3))
+		}
+		st := newTupleStats(data)
+
+		// count the tuples of every length with a map
+		for w := 1; ; w++ {
+			counts := make(map[string]int)
+			for j := 0; j+w <= len(data); j++ {
+				counts[string(data[j:j+w])]++
+			}
+			maxCount, pairs := 0, 0.0
+			for _, c := range counts {
+				maxCount = max(maxCount, c)
+				pairs += float64(c) * float64(c-1) / 2
+			}
+			if maxCount < 2 {
+				if st.longest() != w-1 {
+					t.Fatalf("longest repeated substring %d, expected %d", st.longest(), w-1)
+				}
+				break
+			}
+			if st.maxCount[w] != maxCount || st.pairs[w] != pairs {
+				t.Fatalf("%d-tuples: got %d and %f pairs, expected %d and %f", w, st.maxCount[w], st.pairs[w], maxCount, pairs)
+			}
+		}
+	}
+}
+
+func TestLongestRepeatedSubstring(t *testing.T) {
+	// 8-bit samples without repeated values: no u-tuple occurs 35 times and v = 0
+	s := &Samples{Data: make([]byte, 256), Bits: 8}
+	for i := range s.Data {
+		s.Data[i] = byte(i)
+	}
+	if _, err := LongestRepeatedSubstring.Estimate(s); err == nil {
+		t.Error("expected an error without repeated substrings")
+	}
+	if _, err := TTuple.Estimate(s); err == nil {
+		t.Error("expected an error without values occurring 35 times")
+	}
+
+	e, err := TTuple.Estimate(uniformSamples(100000, 8, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.MinEntropy < 7 || e.MinEntropy > 8 {
+		t.Errorf("t-Tuple on uniform bytes: %f bits, expected between 7 and 8", e.MinEntropy)
+	}
+}
@@ -0,0 +1,151 @@
+// Package expansion computes the binary expansions of the mathematical constants
+// used as reference data by NIST SP 800-22 (data.e, data.pi, data.sqrt2 and data.sqrt3
+// of the reference implementation). The expansions are computed with math/big, so that
+// the published results of the tests can be checked without shipping the data files.
+package expansion
+
+import (
+	"math"
+	"math/big"
+
+	b "github.com/notJoon/drbg/bitstream"
+)
+
+// guardBits is the number of extra bits of precision used in the computations,
+// so that the rounding errors do not reach the returned bits.
+const guardBits = 64
+
+// E returns the first n bits of the binary expansion of e = 10.1011011111100001...,
+// starting with the two bits of the integer part as data.e does.
+func E(n int) *b.BitStream {
+	prec := uint(n + guardBits)
+
+	// e = 2 + Σ_{k>=2} 1/k!, summed by binary splitting. Enough terms are needed
+	// for k! to exceed 2^prec.
+	terms := 2
+	for logFact := 0.0; logFact < float64(prec); terms++ {
+		logFact += math.Log2(float64(terms))
+	}
+	p, q := eSplit(1, int64(terms))
+
+	e := new(big.Float).SetPrec(prec).SetInt(p)
+	e.Quo(e, new(big.Float).SetPrec(prec).SetInt(q))
+	e.Add(e, big.NewFloat(2))
+
+	// shift the integer part 10 behind the binary point
+	e.SetMantExp(e, -2)
+	return fraction(e, n)
+}
+
+// eSplit returns P and Q such that P/Q = Σ_{k=a+1}^{b} a!/k!.
+func eSplit(a, b int64) (*big.Int, *big.Int) {
+	if b-a == 1 {
+		return big.NewInt(1), big.NewInt(b)
+	}
+	m := (a + b) / 2
+	p1, q1 := eSplit(a, m)
+	p2, q2 := eSplit(m, b)
+
+	// P/Q = P1/Q1 + P2/(Q1 Q2)
+	p := new(big.Int).Mul(p1, q2)
+	p.Add(p, p2)
+	return p, q1.Mul(q1, q2)
+}
+
+// Pi returns the first n bits of the binary expansion of π = 11.0010010000111111...,
+// starting with the two bits of the integer part as the examples of SP 800-22 do.
+func Pi(n int) *b.BitStream {
+	pi := pi(uint(n + guardBits))
+
+	// shift the integer part 11 behind the binary point
+	pi.SetMantExp(pi, -2)
+	return fraction(pi, n)
+}
+
+// pi computes π with the Chudnovsky series, summed by binary splitting.
+func pi(prec uint) *big.Float {
+	// each term of the series adds about 47.11 bits
+	terms := int64(float64(prec)/47.11) + 2
+	_, q, t := piSplit(0, terms)
+
+	// π = 426880 sqrt(10005) Q / T
+	x := new(big.Float).SetPrec(prec).SetInt64(10005)
+	x.Sqrt(x)
+	x.Mul(x, new(big.Float).SetPrec(prec).SetInt64(426880))
+	x.Mul(x, new(big.Float).SetPrec(prec).SetInt(q))
+	return x.Quo(x, new(big.Float).SetPrec(prec).SetInt(t))
+}
+
+// piSplit returns the P, Q and T terms of the Chudnovsky series over [a, b).
+func piSplit(a, b int64) (p, q, t *big.Int) {
+	if b-a == 1 {
+		if a == 0 {
+			p, q = big.NewInt(1), big.NewInt(1)
+		} else {
+			p = big.NewInt(6*a - 5)
+			p.Mul(p, big.NewInt(2*a-1))
+			p.Mul(p, big.NewInt(6*a-1))
+			q = big.NewInt(a)
+			q.Mul(q, q).Mul(q, big.NewInt(a))
+			q.Mul(q, big.NewInt(10939058860032000)) // 640320^3 / 24
+		}
+		t = big.NewInt(545140134)
+		t.Mul(t, big.NewInt(a))
+		t.Add(t, big.NewInt(13591409))
+		t.Mul(t, p)
+		if a%2 == 1 {
+			t.Neg(t)
+		}
+		return p, q, t
+	}
+
+	m := (a + b) / 2
+	p1, q1, t1 := piSplit(a, m)
+	p2, q2, t2 := piSplit(m, b)
+
+	// T = T1 Q2 + P1 T2
+	t = new(big.Int).Mul(t1, q2)
+	t.Add(t, new(big.Int).Mul(p1, t2))
+	return p1.Mul(p1, p2), q1.Mul(q1, q2), t
+}
+
+// Sqrt2 returns the first n bits of the binary expansion of √2 = 1.0110101000001001...,
+// starting with the bit of the integer part.
+func Sqrt2(n int) *b.BitStream {
+	return sqrt(2, n)
+}
+
+// Sqrt3 returns the first n bits of the binary expansion of √3 = 1.1011101101100111...,
+// starting with the bit of the integer part.
+func Sqrt3(n int) *b.BitStream {
+	return sqrt(3, n)
+}
+
+// sqrt returns the first n bits of √x for 1 <= x < 4, whose integer part is a single bit.
+func sqrt(x int64, n int) *b.BitStream {
+	prec := uint(n + guardBits)
+	root := new(big.Float).SetPrec(prec).SetInt64(x)
+	root.Sqrt(root)
+
+	// shift the integer part behind the binary point
+	root.SetMantExp(root, -1)
+	return fraction(root, n)
+}
+
+// fraction returns the first n bits after the binary point of x, which must be
+// computed with more than n bits of precision.
+func fraction(x *big.Float, n int) *b.BitStream {
+	frac := new(big.Float).SetPrec(x.Prec()).Set(x)
+	whole, _ := frac.Int(nil)
+	frac.Sub(frac, new(big.Float).SetInt(whole))
+
+	// the first n bits of the fraction are the integer part of frac * 2^n
+	bits, _ := frac.SetMantExp(frac, n).Int(nil)
+
+	data := make([]byte, (n+7)/8)
+	// align the n bits to the most significant bit of data
+	bits.Lsh(bits, uint(len(data)*8-n))
+	bits.FillBytes(data)
+	bs, _ := b.NewBitStream(data).Slice(0, n)
+	return bs
+}
@@ -0,0 +1,48 @@
+package expansion
+
+import (
+	"strings"
+	"testing"
+
+	b "github.com/notJoon/drbg/bitstream"
+)
+
+func bitString(bs *b.BitStream) string {
+	var sb strings.Builder
+	for i := 0; i < bs.Len(); i++ {
+		bit, _ := bs.Bit(i)
+		sb.WriteByte('0' + bit)
+	}
+	return sb.String()
+}
+
+func TestExpansions(t *testing.T) {
+	tests := []struct {
+		name     string
+		expand   func(n int) *b.BitStream
+		expected string
+	}{
+		{"e", E, "1010110111111000010101000101100010100010101110110"},
+		{"pi", Pi, "11001001000011111101101010100010001000010110100011"},
+		{"sqrt2", Sqrt2, "1011010100000100111100110011001111111001110111100110"},
+		{"sqrt3", Sqrt3, "1101110110110011110101110100001011000010011001010101"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bs := tt.expand(len(tt.expected))
+			if bs.Len() != len(tt.expected) {
+				t.Fatalf("len = %d, expected %d", bs.Len(), len(tt.expected))
+			}
+			if got := bitString(bs); got != tt.expected {
+				t.Errorf("got %s, expected %s", got, tt.expected)
+			}
+
+			// a longer expansion starts with the same bits
+			longer := bitString(tt.expand(1000))
+			if !strings.HasPrefix(longer, tt.expected) {
+				t.Errorf("1000 bits start with %s, expected %s", longer[:len(tt.expected)], tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jedib0t/go-pretty/table"
+
+	"github.com/notJoon/drbg/fips140"
+)
+
+// fipsTests implements the fips140 subcommand: it runs the statistical tests of FIPS 140-2
+// section 4.9.1 on consecutive samples of 20,000 bits of a file or a generator.
+func fipsTests(args []string) {
+	flags := flag.NewFlagSet("fips140", flag.ExitOnError)
+	in := addInputFlags(flags, fips140.SampleBits)
+	samples := flags.Int("samples", 0, "Number of samples of 20000 bits to test. 0 tests every whole sample of the input")
+	flags.Parse(args)
+
+	bs, source, err := in.read()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	n := bs.Len() / fips140.SampleBits
+	if *samples > 0 {
+		n = *samples
+	}
+	seqs, err := bs.Split(fips140.SampleBits, n)
+	if err != nil {
+		fmt.Printf("Error: cannot split %d bits into %d samples of %d bits: %v\n", bs.Len(), n, fips140.SampleBits, err)
+		os.Exit(1)
+	}
+
+	results := make([][]*fips140.Result, len(seqs))
+	for i, seq := range seqs {
+		if results[i], err = fips140.Run(seq); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Printf("%s: %d samples of %d bits\n", source, len(seqs), fips140.SampleBits)
+	if len(results) == 1 {
+		writeFIPSResults(os.Stdout, results[0])
+	} else {
+		writeFIPSSummary(os.Stdout, results)
+	}
+}
+
+// writeFIPSResults renders every statistic of the tests of a single sample.
+func writeFIPSResults(w io.Writer, results []*fips140.Result) {
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.AppendHeader(table.Row{"FIPS 140-2 Test", "Statistic", "Value", "Acceptance", "Result"})
+	passed := 0
+	for _, res := range results {
+		for _, s := range res.Statistics {
+			t.AppendRow(table.Row{res.Name, s.Label, s.Value, s.Interval, passFail(s.Pass())})
+		}
+		if res.Passed() {
+			passed++
+		}
+	}
+	t.AppendFooter(table.Row{"Passed", "", "", fmt.Sprintf("%d/%d", passed, len(results)), passFail(passed == len(results))})
+	t.Render()
+}
+
+// writeFIPSSummary renders the number of samples passing each test, and the first samples
+// failing it.
+func writeFIPSSummary(w io.Writer, results [][]*fips140.Result) {
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+	t.AppendHeader(table.Row{"FIPS 140-2 Test", "Passed", "First Failing Samples", "Result"})
+	allPassed := 0
+	for _, sample := range results {
+		ok := true
+		for _, res := range sample {
+			ok = ok && res.Passed()
+		}
+		if ok {
+			allPassed++
+		}
+	}
+	for i, test := range fips140.Tests {
+		passed := 0
+		var failing []int
+		for s, sample := range results {
+			if sample[i].Passed() {
+				passed++
+			} else if len(failing) < 5 {
+				failing = append(failing, s)
+			}
+		}
+		first := "-"
+		if len(failing) > 0 {
+			first = fmt.Sprint(failing)
+		}
+		t.AppendRow(table.Row{test.Name, fmt.Sprintf("%d/%d", passed, len(results)), first, passFail(passed == len(results))})
+	}
+	t.AppendFooter(table.Row{"Samples", fmt.Sprintf("%d/%d", allPassed, len(results)), "", passFail(allPassed == len(results))})
+	t.Render()
+}
@@ -0,0 +1,243 @@
+// Package fips140 implements the statistical random number generator tests of FIPS 140-2
+// section 4.9.1: the monobit, poker, runs and long run tests, run on a sample of 20,000
+// consecutive bits.
+//
+// Unlike the tests of SP 800-22, they do not compute p-values: each test passes when its
+// statistics fall within fixed acceptance intervals.
+package fips140
+
+import (
+	"errors"
+	"fmt"
+
+	b "github.com/notJoon/drbg/bitstream"
+)
+
+// SampleBits is the number of bits of the sample tested by every test.
+const SampleBits = 20000
+
+// LongRun is the length of the shortest run that makes the long run test fail.
+const LongRun = 26
+
+var ErrSampleLength = fmt.Errorf("the sample must hold exactly %d bits", SampleBits)
+
+// ErrFailed is wrapped by the errors of Check.
+var ErrFailed = errors.New("FIPS 140-2 statistical test failed")
+
+// Interval is the acceptance interval of a statistic.
+type Interval struct {
+	Low, High float64
+	Exclusive bool // the bounds themselves are rejected
+}
+
+// Contains reports whether x lies in the interval.
+func (i Interval) Contains(x float64) bool {
+	if i.Exclusive {
+		return i.Low < x && x < i.High
+	}
+	return i.Low <= x && x <= i.High
+}
+
+func (i Interval) String() string {
+	if i.Exclusive {
+		return fmt.Sprintf("%g < X < %g", i.Low, i.High)
+	}
+	return fmt.Sprintf("%g <= X <= %g", i.Low, i.High)
+}
+
+// Statistic is a value computed by a test, with its acceptance interval.
+type Statistic struct {
+	Label    string
+	Value    float64
+	Interval Interval
+}
+
+// Pass reports whether the value lies in the acceptance interval.
+func (s Statistic) Pass() bool {
+	return s.Interval.Contains(s.Value)
+}
+
+// Result is the outcome of a test: it passes when every statistic passes.
+type Result struct {
+	Name       string
+	Statistics []Statistic
+}
+
+// Passed reports whether every statistic of the test passed.
+func (r *Result) Passed() bool {
+	for _, s := range r.Statistics {
+		if !s.Pass() {
+			return false
+		}
+	}
+	return true
+}
+
+// Test is a test of section 4.9.1.
+type Test struct {
+	ID   string // e.g. "monobit"
+	Name string
+	Run  func(bs *b.BitStream) (*Result, error)
+}
+
+// Tests holds the four tests in the order of the standard.
+var Tests = []Test{
+	{"monobit", "Monobit Test", Monobit},
+	{"poker", "Poker Test", Poker},
+	{"runs", "Runs Test", Runs},
+	{"long-run", "Long Run Test", LongRunTest},
+}
+
+// Run runs every test on a sample of 20,000 bits.
+func Run(bs *b.BitStream) ([]*Result, error) {
+	results := make([]*Result, len(Tests))
+	for i, t := range Tests {
+		res, err := t.Run(bs)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = res
+	}
+	return results, nil
+}
+
+// Check runs every test on a sample of 20,000 bits, as a module does at power-up, and returns
+// an error naming the first test that failed.
+func Check(bs *b.BitStream) error {
+	results, err := Run(bs)
+	if err != nil {
+		return err
+	}
+	for _, res := range results {
+		if !res.Passed() {
+			return fmt.Errorf("%w: %s", ErrFailed, res.Name)
+		}
+	}
+	return nil
+}
+
+// bits returns the bits of a sample, checking its length.
+func bits(bs *b.BitStream) ([]byte, error) {
+	if bs.Len() != SampleBits {
+		return nil, fmt.Errorf("%w, got %d", ErrSampleLength, bs.Len())
+	}
+	out := make([]byte, SampleBits)
+	for i := range out {
+		out[i], _ = bs.Bit(i)
+	}
+	return out, nil
+}
+
+// Monobit counts the ones of the sample. The test passes if 9725 < X < 10275.
+func Monobit(bs *b.BitStream) (*Result, error) {
+	sample, err := bits(bs)
+	if err != nil {
+		return nil, err
+	}
+	ones := 0
+	for _, bit := range sample {
+		ones += int(bit)
+	}
+	return &Result{
+		Name:       "Monobit Test",
+		Statistics: []Statistic{{"ones", float64(ones), Interval{9725, 10275, true}}},
+	}, nil
+}
+
+// Poker divides the sample into 5000 contiguous 4-bit segments and counts the occurrences f(i)
+// of each of the 16 values. The test passes if 2.16 < X < 46.17, where
+//
+//	X = (16 / 5000) * Σ f(i)² - 5000
+func Poker(bs *b.BitStream) (*Result, error) {
+	sample, err := bits(bs)
+	if err != nil {
+		return nil, err
+	}
+	var f [16]int
+	for i := 0; i < SampleBits; i += 4 {
+		f[sample[i]<<3|sample[i+1]<<2|sample[i+2]<<1|sample[i+3]]++
+	}
+	sum := 0
+	for _, c := range f {
+		sum += c * c
+	}
+	// computed over the integers, so that X is exact at the bounds
+	x := float64(16*sum-5000*5000) / 5000
+	return &Result{
+		Name:       "Poker Test",
+		Statistics: []Statistic{{"X", x, Interval{2.16, 46.17, true}}},
+	}, nil
+}
+
+// runIntervals are the acceptance intervals of the number of runs of lengths 1 to 5 and 6 or
+// more, the same for runs of zeros and runs of ones.
+var runIntervals = [6]Interval{
+	{Low: 2315, High: 2685},
+	{Low: 1114, High: 1386},
+	{Low: 527, High: 723},
+	{Low: 240, High: 384},
+	{Low: 103, High: 209},
+	{Low: 103, High: 209},
+}
+
+// Runs counts the maximal runs of zeros and of ones of each length in the sample, with the
+// runs of 6 or more bits counted together. The test passes if the 12 counts fall in their
+// intervals.
+func Runs(bs *b.BitStream) (*Result, error) {
+	sample, err := bits(bs)
+	if err != nil {
+		return nil, err
+	}
+	var counts [2][6]int
+	for _, r := range runs(sample) {
+		counts[r.bit][min(r.length, 6)-1]++
+	}
+
+	res := &Result{Name: "Runs Test"}
+	for bit := 0; bit < 2; bit++ {
+		for l, c := range counts[bit] {
+			label := fmt.Sprintf("runs of %ds of length %d", bit, l+1)
+			if l == 5 {
+				label = fmt.Sprintf("runs of %ds of length 6+", bit)
+			}
+			res.Statistics = append(res.Statistics, Statistic{label, float64(c), runIntervals[l]})
+		}
+	}
+	return res, nil
+}
+
+// LongRunTest finds the longest run of zeros or ones of the sample. The test passes if no run
+// is LongRun bits or longer.
+func LongRunTest(bs *b.BitStream) (*Result, error) {
+	sample, err := bits(bs)
+	if err != nil {
+		return nil, err
+	}
+	longest := 0
+	for _, r := range runs(sample) {
+		longest = max(longest, r.length)
+	}
+	return &Result{
+		Name:       "Long Run Test",
+		Statistics: []Statistic{{"longest run", float64(longest), Interval{1, LongRun - 1, false}}},
+	}, nil
+}
+
+type run struct {
+	bit    byte
+	length int
+}
+
+// runs returns the maximal runs of identical bits of the sample.
+func runs(sample []byte) []run {
+	var out []run
+	for i := 0; i < len(sample); {
+		j := i + 1
+		for j < len(sample) && sample[j] == sample[i] {
+			j++
+		}
+		out = append(out, run{sample[i], j - i})
+		i = j
+	}
+	return out
+}
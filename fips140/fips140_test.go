@@ -0,0 +1,133 @@
+package fips140
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+
+	b "github.com/notJoon/drbg/bitstream"
+)
+
+const sampleBytes = SampleBits / 8
+
+func randomSample(seed int64) *b.BitStream {
+	data := make([]byte, sampleBytes)
+	rand.New(rand.NewSource(seed)).Read(data)
+	return b.NewBitStream(data)
+}
+
+func TestRandomSamplesPass(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		if err := Check(randomSample(seed)); err != nil {
+			t.Errorf("seed %d: %v", seed, err)
+		}
+	}
+}
+
+func TestDegenerateSamples(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		pass [4]bool // monobit, poker, runs, long run
+	}{
+		{"All zeros", make([]byte, sampleBytes), [4]bool{false, false, false, false}},
+		{"All ones", bytes.Repeat([]byte{0xff}, sampleBytes), [4]bool{false, false, false, false}},
+		{"Alternating", bytes.Repeat([]byte{0xaa}, sampleBytes), [4]bool{true, false, false, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := Run(b.NewBitStream(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, res := range results {
+				if res.Passed() != tt.pass[i] {
+					t.Errorf("%s passed = %v, expected %v: %+v", res.Name, res.Passed(), tt.pass[i], res.Statistics)
+				}
+			}
+		})
+	}
+}
+
+func TestPokerStatistic(t *testing.T) {
+	// 0x0f 0xf0 repeated: the segments are 0000 and 1111 equally often, f = 2500 each
+	data := bytes.Repeat([]byte{0x0f, 0xf0}, sampleBytes/2)
+	res, err := Poker(b.NewBitStream(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x := res.Statistics[0].Value; x != 16.0*2*2500*2500/5000-5000 {
+		t.Errorf("X = %v, expected 35000", x)
+	}
+}
+
+func TestRunsCounts(t *testing.T) {
+	// 11010000 repeated: per byte a run of two ones, a zero, a one and four zeros
+	data := bytes.Repeat([]byte{0xd0}, sampleBytes)
+	res, err := Runs(b.NewBitStream(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]float64{
+		"runs of 0s of length 1": 2500,
+		"runs of 0s of length 4": 2500,
+		"runs of 1s of length 1": 2500,
+		"runs of 1s of length 2": 2500,
+	}
+	for _, s := range res.Statistics {
+		if s.Value != expected[s.Label] {
+			t.Errorf("%s = %v, expected %v", s.Label, s.Value, expected[s.Label])
+		}
+	}
+
+	longest, err := LongRunTest(b.NewBitStream(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := longest.Statistics[0].Value; v != 4 {
+		t.Errorf("longest run %v, expected 4", v)
+	}
+}
+
+func TestLongRunBoundary(t *testing.T) {
+	for _, length := range []int{LongRun - 1, LongRun} {
+		bs := randomSample(1)
+		// a run of ones of the given length, delimited by zeros
+		for i := 100; i < 100+length; i++ {
+			bs.SetBit(i, 1)
+		}
+		bs.SetBit(99, 0)
+		bs.SetBit(100+length, 0)
+
+		res, err := LongRunTest(bs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Passed() != (length < LongRun) {
+			t.Errorf("run of %d: passed = %v", length, res.Passed())
+		}
+	}
+}
+
+func TestInterval(t *testing.T) {
+	open := Interval{2.16, 46.17, true}
+	closed := Interval{2315, 2685, false}
+	if open.Contains(2.16) || open.Contains(46.17) || !open.Contains(2.17) {
+		t.Error("exclusive bounds")
+	}
+	if !closed.Contains(2315) || !closed.Contains(2685) || closed.Contains(2686) {
+		t.Error("inclusive bounds")
+	}
+}
+
+func TestSampleLength(t *testing.T) {
+	for _, test := range Tests {
+		if _, err := test.Run(b.NewBitStream(make([]byte, sampleBytes+1))); !errors.Is(err, ErrSampleLength) {
+			t.Errorf("%s: error %v, expected ErrSampleLength", test.Name, err)
+		}
+	}
+	if err := Check(b.NewBitStream(make([]byte, sampleBytes))); !errors.Is(err, ErrFailed) {
+		t.Errorf("Check on zeros: %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/notJoon/drbg/generator"
+)
+
+// defaultSeedSize is the length in bytes of the random seed drawn when -seed is not given.
+// It is enough for the entropy input of every DRBG of the registry.
+const defaultSeedSize = 48
+
+// generate implements the generate subcommand: it writes the output of a generator
+// to a file as raw bytes or ASCII '0' and '1' characters.
+func generate(args []string) {
+	flags := flag.NewFlagSet("generate", flag.ExitOnError)
+	gen := flags.String("gen", "", "Name of the generator (see -list)")
+	seedHex := flags.String("seed", "", "Seed of the generator in hexadecimal. If empty, the reference seed of the generator, or a random seed that is printed")
+	bits := flags.Int("bits", 1000000, "Number of bits to generate")
+	out := flags.String("out", "", "Output file. Standard output if empty")
+	format := flags.String("format", "raw", "Output format: raw (binary) or ascii ('0' and '1' characters)")
+	list := flags.Bool("list", false, "List the available generators")
+	flags.Parse(args)
+
+	if *list {
+		listGenerators(os.Stdout)
+		return
+	}
+	if *format != "raw" && *format != "ascii" {
+		fmt.Printf("Error: unknown format %q (expected raw or ascii)\n", *format)
+		os.Exit(1)
+	}
+	if *bits <= 0 {
+		fmt.Printf("Error: -bits must be positive, got %d\n", *bits)
+		os.Exit(1)
+	}
+
+	g, _, err := seededGenerator(*gen, *seedHex)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	buf := bufio.NewWriter(w)
+	if err := generator.Write(buf, g, *bits, *format == "ascii"); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := buf.Flush(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// seededGenerator builds the generator registered under id and seeds it from seedHex,
+// or, if seedHex is empty, from the reference seed of the generator or a random seed printed
+// on the standard error. It also returns a description of the generator and its seed for
+// the reports.
+func seededGenerator(id, seedHex string) (generator.Generator, string, error) {
+	if id == "" {
+		return nil, "", fmt.Errorf("no generator specified (see -list)")
+	}
+
+	var seed []byte
+	if seedHex == "" {
+		// generators with a reference seed, such as the sample generators of the NIST suite,
+		// accept an empty seed
+		if g, err := generator.NewSeeded(id, nil); err == nil {
+			return g, fmt.Sprintf("%s (reference seed)", id), nil
+		} else if errors.Is(err, generator.ErrUnknownGenerator) {
+			return nil, "", err
+		}
+
+		seed = make([]byte, defaultSeedSize)
+		if _, err := rand.Read(seed); err != nil {
+			return nil, "", err
+		}
+		fmt.Fprintf(os.Stderr, "seed: %x\n", seed)
+	} else {
+		var err error
+		if seed, err = hex.DecodeString(seedHex); err != nil {
+			return nil, "", fmt.Errorf("invalid seed: %w", err)
+		}
+	}
+
+	g, err := generator.NewSeeded(id, seed)
+	if err != nil {
+		return nil, "", err
+	}
+	return g, fmt.Sprintf("%s (seed %x)", id, seed), nil
+}
+
+// listGenerators writes the id and name of every registered generator.
+func listGenerators(w io.Writer) {
+	for _, id := range generator.IDs() {
+		g, err := generator.New(id)
+		if err != nil {
+			fmt.Fprintf(w, "%-20s %s\n", id, err)
+			continue
+		}
+		fmt.Fprintf(w, "%-20s %s\n", id, g.Name())
+	}
+}
@@ -0,0 +1,77 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+
+	"github.com/notJoon/drbg/drbg"
+)
+
+// drbgGenerator adapts a DRBG of SP 800-90A to the Generator interface. The seed is used
+// as the entropy input, without nonce nor personalization string, and the DRBG is never
+// reseeded: it generates at most 2^48 requests of drbg.MaxRequestBytes.
+type drbgGenerator struct {
+	name        string
+	instantiate func(seed []byte) (*drbg.DRBG, error)
+	d           *drbg.DRBG
+}
+
+func (g *drbgGenerator) Name() string {
+	return g.name
+}
+
+func (g *drbgGenerator) Seed(seed []byte) error {
+	d, err := g.instantiate(seed)
+	if err != nil {
+		return err
+	}
+	if g.d != nil {
+		g.d.Uninstantiate()
+	}
+	g.d = d
+	return nil
+}
+
+func (g *drbgGenerator) Read(p []byte) (int, error) {
+	if g.d == nil {
+		return 0, ErrNotSeeded
+	}
+	return g.d.Read(p)
+}
+
+func hashGenerator(name string, h func() hash.Hash) Factory {
+	return func() Generator {
+		return &drbgGenerator{name: "Hash_DRBG " + name, instantiate: func(seed []byte) (*drbg.DRBG, error) {
+			return drbg.NewHash(h, seed, nil, nil, drbg.Config{})
+		}}
+	}
+}
+
+func hmacGenerator(name string, h func() hash.Hash) Factory {
+	return func() Generator {
+		return &drbgGenerator{name: "HMAC_DRBG " + name, instantiate: func(seed []byte) (*drbg.DRBG, error) {
+			return drbg.NewHMAC(h, seed, nil, nil, drbg.Config{})
+		}}
+	}
+}
+
+func ctrGenerator(name string, keyLen int) Factory {
+	return func() Generator {
+		return &drbgGenerator{name: "CTR_DRBG " + name, instantiate: func(seed []byte) (*drbg.DRBG, error) {
+			return drbg.NewCTR(keyLen, true, seed, nil, nil, drbg.Config{})
+		}}
+	}
+}
+
+func init() {
+	Register("hash-sha256", hashGenerator("SHA-256", sha256.New))
+	Register("hash-sha384", hashGenerator("SHA-384", sha512.New384))
+	Register("hash-sha512", hashGenerator("SHA-512", sha512.New))
+	Register("hmac-sha256", hmacGenerator("SHA-256", sha256.New))
+	Register("hmac-sha384", hmacGenerator("SHA-384", sha512.New384))
+	Register("hmac-sha512", hmacGenerator("SHA-512", sha512.New))
+	Register("ctr-aes128", ctrGenerator("AES-128", 16))
+	Register("ctr-aes192", ctrGenerator("AES-192", 24))
+	Register("ctr-aes256", ctrGenerator("AES-256", 32))
+}
@@ -0,0 +1,69 @@
+// Package generator gives a common interface to the random bit generators of this module,
+// so that they can be selected by name and their output fed to the tests without
+// intermediate files.
+package generator
+
+import (
+	"errors"
+	"io"
+
+	b "github.com/notJoon/drbg/bitstream"
+	"github.com/notJoon/drbg/drbg"
+)
+
+var (
+	ErrNotSeeded   = errors.New("generator not seeded")
+	ErrInvalidSeed = errors.New("invalid seed")
+)
+
+// Generator is a deterministic random bit generator: the same seed always yields the same bits.
+type Generator interface {
+	// Name returns the name of the generator, e.g. "HMAC_DRBG SHA-256".
+	Name() string
+
+	// Seed resets the state of the generator from seed. Generators return an error
+	// if the seed is too short or invalid for them. Generators with a reference seed,
+	// such as the sample generators of the NIST suite, use it for an empty seed.
+	Seed(seed []byte) error
+
+	// Read fills p with the next bytes of output, most significant bit first.
+	// It returns ErrNotSeeded before the first call to Seed.
+	Read(p []byte) (int, error)
+}
+
+// ReadBits reads the next n bits of g into a BitStream that can be given to the nist tests.
+func ReadBits(g Generator, n int) (*b.BitStream, error) {
+	return drbg.ReadBits(g, n)
+}
+
+// Write writes the next n bits of g to w, as raw bytes or as the ASCII characters '0' and '1'.
+// Raw output needs a whole number of bytes.
+func Write(w io.Writer, g Generator, n int, ascii bool) error {
+	if !ascii && n%8 != 0 {
+		return errors.New("raw output needs a multiple of 8 bits")
+	}
+
+	// generate the output in chunks, so that long sequences do not need to fit in memory
+	const chunkBits = 1 << 20
+	for n > 0 {
+		size := min(n, chunkBits)
+		bs, err := ReadBits(g, size)
+		if err != nil {
+			return err
+		}
+
+		data := bs.Bytes()
+		if ascii {
+			data = make([]byte, size)
+			for i := range data {
+				bit, _ := bs.Bit(i)
+				data[i] = '0' + bit
+			}
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		n -= size
+	}
+	return nil
+}
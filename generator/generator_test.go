@@ -0,0 +1,106 @@
+package generator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/notJoon/drbg/drbg"
+)
+
+var testSeed = bytes.Repeat([]byte{0x5a}, 32)
+
+func TestDefaultRegistry(t *testing.T) {
+	for _, id := range IDs() {
+		// generators with a small state reject long seeds
+		seed := testSeed
+		g, err := NewSeeded(id, seed)
+		if errors.Is(err, ErrInvalidSeed) {
+			seed = testSeed[:8]
+			g, err = NewSeeded(id, seed)
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+		if g.Name() == "" {
+			t.Errorf("%s: empty name", id)
+		}
+
+		// the same seed yields the same bits
+		first, _ := ReadBits(g, 1000)
+		g.Seed(seed)
+		second, _ := ReadBits(g, 1000)
+		if !bytes.Equal(first.Bytes(), second.Bytes()) {
+			t.Errorf("%s: reseeding with the same seed changed the output", id)
+		}
+	}
+
+	if _, err := New("no-such-generator"); !errors.Is(err, ErrUnknownGenerator) {
+		t.Errorf("got %v, expected ErrUnknownGenerator", err)
+	}
+	if err := DefaultRegistry.Register("hmac-sha256", func() Generator { return nil }); !errors.Is(err, ErrDuplicateGenerator) {
+		t.Errorf("got %v, expected ErrDuplicateGenerator", err)
+	}
+}
+
+func TestNotSeeded(t *testing.T) {
+	g, err := New("hash-sha256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Read(make([]byte, 1)); !errors.Is(err, ErrNotSeeded) {
+		t.Errorf("got %v, expected ErrNotSeeded", err)
+	}
+	if err := g.Seed([]byte{1}); err == nil {
+		t.Error("expected an error for a 1-byte seed")
+	}
+}
+
+func TestDRBGOutput(t *testing.T) {
+	// the hmac-sha256 generator is HMAC_DRBG instantiated with the seed as entropy input
+	g, err := NewSeeded("hmac-sha256", testSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := drbg.NewHMAC(sha256.New, testSeed, nil, nil, drbg.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, want := make([]byte, 100), make([]byte, 100)
+	g.Read(got)
+	d.Read(want)
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %x, expected %x", got, want)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	g, _ := NewSeeded("ctr-aes128", testSeed)
+	var raw bytes.Buffer
+	if err := Write(&raw, g, 64, false); err != nil {
+		t.Fatal(err)
+	}
+
+	g.Seed(testSeed)
+	var ascii bytes.Buffer
+	if err := Write(&ascii, g, 64, true); err != nil {
+		t.Fatal(err)
+	}
+
+	var expected strings.Builder
+	for _, c := range raw.Bytes() {
+		for i := 7; i >= 0; i-- {
+			expected.WriteByte('0' + c>>uint(i)&1)
+		}
+	}
+	if ascii.String() != expected.String() {
+		t.Errorf("ascii output %s, expected %s", ascii.String(), expected.String())
+	}
+
+	if err := Write(&raw, g, 12, false); err == nil {
+		t.Error("expected an error for raw output of 12 bits")
+	}
+}
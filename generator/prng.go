@@ -0,0 +1,337 @@
+package generator
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+)
+
+// Non-cryptographic and stream cipher generators commonly used as baselines. Their seed is mapped
+// directly onto their state, so that the output of other implementations can be reproduced:
+// the seed is read as big-endian words, missing bytes being zero, and seeds longer than the
+// state are rejected. An empty seed selects the reference seed of the generator.
+//
+// Output words are written most significant byte first.
+
+// wordGenerator adapts a generator of 32 or 64-bit words to the Generator interface.
+type wordGenerator struct {
+	name  string
+	size  int // bytes per word, 4 or 8
+	start func(seed []byte) (func() uint64, error)
+
+	next    func() uint64
+	pending []byte // bytes of the last word not read yet
+	buf     [8]byte
+}
+
+func (g *wordGenerator) Name() string {
+	return g.name
+}
+
+func (g *wordGenerator) Seed(seed []byte) error {
+	next, err := g.start(seed)
+	if err != nil {
+		return err
+	}
+	g.next, g.pending = next, nil
+	return nil
+}
+
+func (g *wordGenerator) Read(p []byte) (int, error) {
+	if g.next == nil {
+		return 0, ErrNotSeeded
+	}
+	n := 0
+	for n < len(p) {
+		if len(g.pending) == 0 {
+			binary.BigEndian.PutUint64(g.buf[:], g.next())
+			g.pending = g.buf[8-g.size:]
+		}
+		c := copy(p[n:], g.pending)
+		g.pending = g.pending[c:]
+		n += c
+	}
+	return n, nil
+}
+
+func wordFactory(name string, size int, start func(seed []byte) (func() uint64, error)) Factory {
+	return func() Generator {
+		return &wordGenerator{name: name, size: size, start: start}
+	}
+}
+
+// seedWords returns the seed as n big-endian words of size bytes, padded with zeros.
+func seedWords(seed []byte, n, size int) ([]uint64, error) {
+	if len(seed) > n*size {
+		return nil, fmt.Errorf("%w: seed of %d bytes, at most %d", ErrInvalidSeed, len(seed), n*size)
+	}
+	padded := make([]byte, n*size)
+	copy(padded, seed)
+
+	words := make([]uint64, n)
+	for i := range words {
+		for _, c := range padded[i*size : (i+1)*size] {
+			words[i] = words[i]<<8 | uint64(c)
+		}
+	}
+	return words, nil
+}
+
+func init() {
+	Register("pcg32", wordFactory("PCG32", 4, pcg32))
+	Register("xorshift128plus", wordFactory("xorshift128+", 8, xorshift128Plus))
+	Register("xoshiro256starstar", wordFactory("xoshiro256**", 8, xoshiro256StarStar))
+	Register("mt19937", wordFactory("MT19937", 4, mt19937))
+	Register("splitmix64", wordFactory("SplitMix64", 8, splitMix64))
+	Register("chacha20", func() Generator { return &chacha20Generator{} })
+}
+
+// pcg32 is pcg32_random_r of the PCG reference implementation: a 64-bit LCG with the XSH RR output
+// function. The seed is the initial state followed by the stream, by default 42 and 54 as in pcg32-demo.
+func pcg32(seed []byte) (func() uint64, error) {
+	words := []uint64{42, 54}
+	if len(seed) > 0 {
+		var err error
+		if words, err = seedWords(seed, 2, 8); err != nil {
+			return nil, err
+		}
+	}
+
+	// pcg32_srandom_r
+	var state uint64
+	inc := words[1]<<1 | 1
+	step := func() uint64 {
+		old := state
+		state = old*6364136223846793005 + inc
+		return old
+	}
+	step()
+	state += words[0]
+	step()
+
+	return func() uint64 {
+		old := step()
+		xorShifted := uint32((old>>18 ^ old) >> 27)
+		return uint64(bits.RotateLeft32(xorShifted, -int(old>>59)))
+	}, nil
+}
+
+// splitMix64 is the SplitMix64 generator of Steele, Lea and Flood. The seed is the 64-bit state, by default 0.
+func splitMix64(seed []byte) (func() uint64, error) {
+	words, err := seedWords(seed, 1, 8)
+	if err != nil {
+		return nil, err
+	}
+	return splitMix64Words(words[0]), nil
+}
+
+func splitMix64Words(state uint64) func() uint64 {
+	return func() uint64 {
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+		z = (z ^ z>>27) * 0x94d049bb133111eb
+		return z ^ z>>31
+	}
+}
+
+// xorState returns the n words of state of a xorshift generator: the seed, or by default
+// the output of SplitMix64 seeded with 0, as recommended by the authors. The state must not be zero.
+func xorState(seed []byte, n int) ([]uint64, error) {
+	if len(seed) == 0 {
+		next := splitMix64Words(0)
+		s := make([]uint64, n)
+		for i := range s {
+			s[i] = next()
+		}
+		return s, nil
+	}
+
+	s, err := seedWords(seed, n, 8)
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range s {
+		if w != 0 {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: the state must not be zero", ErrInvalidSeed)
+}
+
+// xorshift128Plus is Vigna's xorshift128+ with the shifts 23, 18 and 5.
+func xorshift128Plus(seed []byte) (func() uint64, error) {
+	s, err := xorState(seed, 2)
+	if err != nil {
+		return nil, err
+	}
+	return func() uint64 {
+		s1, s0 := s[0], s[1]
+		result := s0 + s1
+		s[0] = s0
+		s1 ^= s1 << 23
+		s[1] = s1 ^ s0 ^ s1>>18 ^ s0>>5
+		return result
+	}, nil
+}
+
+// xoshiro256StarStar is xoshiro256** of Blackman and Vigna.
+func xoshiro256StarStar(seed []byte) (func() uint64, error) {
+	s, err := xorState(seed, 4)
+	if err != nil {
+		return nil, err
+	}
+	return func() uint64 {
+		result := bits.RotateLeft64(s[1]*5, 7) * 9
+		t := s[1] << 17
+		s[2] ^= s[0]
+		s[3] ^= s[1]
+		s[1] ^= s[2]
+		s[0] ^= s[3]
+		s[2] ^= t
+		s[3] = bits.RotateLeft64(s[3], 45)
+		return result
+	}, nil
+}
+
+// mt19937 is the 32-bit Mersenne Twister of Matsumoto and Nishimura (mt19937ar). An empty seed is
+// init_genrand(5489), the default of the reference implementation; otherwise the seed, read as
+// big-endian 32-bit words, is the key of init_by_array.
+func mt19937(seed []byte) (func() uint64, error) {
+	const n, m = 624, 397
+	var mt [n]uint32
+	initGenrand := func(s uint32) {
+		mt[0] = s
+		for i := 1; i < n; i++ {
+			mt[i] = 1812433253*(mt[i-1]^mt[i-1]>>30) + uint32(i)
+		}
+	}
+
+	if len(seed) == 0 {
+		initGenrand(5489)
+	} else {
+		words, _ := seedWords(seed, (len(seed)+3)/4, 4)
+
+		// init_by_array
+		initGenrand(19650218)
+		i, j := 1, 0
+		for k := max(n, len(words)); k > 0; k-- {
+			mt[i] = (mt[i] ^ (mt[i-1]^mt[i-1]>>30)*1664525) + uint32(words[j]) + uint32(j)
+			i++
+			j++
+			if i >= n {
+				mt[0] = mt[n-1]
+				i = 1
+			}
+			if j >= len(words) {
+				j = 0
+			}
+		}
+		for k := n - 1; k > 0; k-- {
+			mt[i] = (mt[i] ^ (mt[i-1]^mt[i-1]>>30)*1566083941) - uint32(i)
+			i++
+			if i >= n {
+				mt[0] = mt[n-1]
+				i = 1
+			}
+		}
+		mt[0] = 0x80000000
+	}
+
+	index := n
+	return func() uint64 {
+		if index >= n {
+			for k := 0; k < n; k++ {
+				y := mt[k]&0x80000000 | mt[(k+1)%n]&0x7fffffff
+				mt[k] = mt[(k+m)%n] ^ y>>1
+				if y&1 != 0 {
+					mt[k] ^= 0x9908b0df
+				}
+			}
+			index = 0
+		}
+
+		y := mt[index]
+		index++
+		y ^= y >> 11
+		y ^= y << 7 & 0x9d2c5680
+		y ^= y << 15 & 0xefc60000
+		y ^= y >> 18
+		return uint64(y)
+	}, nil
+}
+
+// chacha20Generator is the keystream of ChaCha20 (RFC 8439). The seed is the 32-byte key followed
+// by the 16 bytes of the last row of the initial state: the 32-bit block counter, little-endian,
+// and the 96-bit nonce, as the IV of the OpenSSL chacha20 cipher. Missing bytes are zero, and an
+// empty seed is the all-zero key, counter and nonce.
+type chacha20Generator struct {
+	state   *[16]uint32
+	block   [64]byte
+	pending []byte
+}
+
+func (g *chacha20Generator) Name() string {
+	return "ChaCha20"
+}
+
+func (g *chacha20Generator) Seed(seed []byte) error {
+	if len(seed) > 48 {
+		return fmt.Errorf("%w: seed of %d bytes, at most 48", ErrInvalidSeed, len(seed))
+	}
+	var padded [48]byte
+	copy(padded[:], seed)
+
+	g.state = &[16]uint32{0x61707865, 0x3320646e, 0x79622d32, 0x6b206574}
+	for i := 0; i < 12; i++ {
+		g.state[4+i] = binary.LittleEndian.Uint32(padded[4*i:])
+	}
+	g.pending = nil
+	return nil
+}
+
+func (g *chacha20Generator) Read(p []byte) (int, error) {
+	if g.state == nil {
+		return 0, ErrNotSeeded
+	}
+	n := 0
+	for n < len(p) {
+		if len(g.pending) == 0 {
+			chachaBlock(&g.block, g.state)
+			g.state[12]++ // the counter wraps after 256 GiB, as in OpenSSL the nonce is not incremented
+			g.pending = g.block[:]
+		}
+		c := copy(p[n:], g.pending)
+		g.pending = g.pending[c:]
+		n += c
+	}
+	return n, nil
+}
+
+// chachaBlock writes the ChaCha20 block function of state to out (RFC 8439, section 2.3).
+func chachaBlock(out *[64]byte, state *[16]uint32) {
+	x := *state
+	quarterRound := func(a, b, c, d int) {
+		x[a] += x[b]
+		x[d] = bits.RotateLeft32(x[d]^x[a], 16)
+		x[c] += x[d]
+		x[b] = bits.RotateLeft32(x[b]^x[c], 12)
+		x[a] += x[b]
+		x[d] = bits.RotateLeft32(x[d]^x[a], 8)
+		x[c] += x[d]
+		x[b] = bits.RotateLeft32(x[b]^x[c], 7)
+	}
+	for i := 0; i < 10; i++ {
+		quarterRound(0, 4, 8, 12)
+		quarterRound(1, 5, 9, 13)
+		quarterRound(2, 6, 10, 14)
+		quarterRound(3, 7, 11, 15)
+		quarterRound(0, 5, 10, 15)
+		quarterRound(1, 6, 11, 12)
+		quarterRound(2, 7, 8, 13)
+		quarterRound(3, 4, 9, 14)
+	}
+	for i := range x {
+		binary.LittleEndian.PutUint32(out[4*i:], x[i]+state[i])
+	}
+}
@@ -0,0 +1,120 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// readWords reads n words of size bytes from the generator registered under id.
+func readWords(t *testing.T, id, seedHex string, n, size int) []uint64 {
+	t.Helper()
+	seed, err := hex.DecodeString(seedHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewSeeded(id, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, n*size)
+	if _, err := g.Read(buf); err != nil {
+		t.Fatal(err)
+	}
+	words := make([]uint64, n)
+	for i := range words {
+		var padded [8]byte
+		copy(padded[8-size:], buf[i*size:(i+1)*size])
+		words[i] = binary.BigEndian.Uint64(padded[:])
+	}
+	return words
+}
+
+func TestPRNGVectors(t *testing.T) {
+	for _, tc := range []struct {
+		id, seed string
+		size     int
+		want     []uint64
+	}{
+		// pcg32-demo of the PCG reference implementation, with initial state 42 and stream 54
+		{"pcg32", "", 4, []uint64{0xa15c02b7, 0x7b47f409, 0xba1d3330, 0x83d2f293, 0xbfa4784b, 0xcbed606e}},
+		{"pcg32", "000000000000002a0000000000000036", 4, []uint64{0xa15c02b7, 0x7b47f409}},
+		// mt19937ar with init_genrand(5489), and mt19937ar.out with init_by_array({0x123, 0x234, 0x345, 0x456})
+		{"mt19937", "", 4, []uint64{3499211612, 581869302, 3890346734}},
+		{"mt19937", "00000123000002340000034500000456", 4, []uint64{1067595299, 955945823, 477289528, 4107218783, 4228976476}},
+		{"splitmix64", "", 8, []uint64{0xe220a8397b1dcdaf, 0x6e789e6aa1b965f4, 0x06c45d188009454f}},
+		{"splitmix64", "000000000012d687", 8, []uint64{6457827717110365317, 3203168211198807973, 9817491932198370423}},
+		{"xoshiro256starstar", "0000000000000001000000000000000200000000000000030000000000000004", 8, []uint64{11520, 0, 1509978240, 1215971899390074240}},
+		{"xorshift128plus", "00000000000000010000000000000002", 8, []uint64{3, 0x800025}},
+	} {
+		got := readWords(t, tc.id, tc.seed, len(tc.want), tc.size)
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("%s with seed %q: output %d is %d, expected %d", tc.id, tc.seed, i, got[i], tc.want[i])
+			}
+		}
+	}
+}
+
+func TestMT19937Default(t *testing.T) {
+	// the 10000th output of a default-constructed std::mt19937 is 4123659995
+	got := readWords(t, "mt19937", "", 10000, 4)
+	if got[9999] != 4123659995 {
+		t.Errorf("10000th output %d, expected 4123659995", got[9999])
+	}
+}
+
+func TestChaCha20(t *testing.T) {
+	// RFC 8439, section 2.3.2: the block with counter 1 and nonce 00:00:00:09:00:00:00:4a:00:00:00:00
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f" +
+		"01000000" + "000000090000004a00000000")
+	want, _ := hex.DecodeString("10f1e7e4d13b5915500fdd1fa32071c4c7d1f4c733c068030422aa9ac3d46c4e" +
+		"d2826446079faa0914c2d705d98b02a2b5129cd1de164eb9cbd083e8a2503c4e")
+	g, err := NewSeeded("chacha20", seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]byte, 64)
+	g.Read(got)
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %x, expected %x", got, want)
+	}
+
+	// RFC 8439, appendix A.1, test vector #1: the all-zero key and nonce
+	want, _ = hex.DecodeString("76b8e0ada0f13d90405d6ae55386bd28bdd219b8a08ded1aa836efcc8b770dc7" +
+		"da41597c5157488d7724e03fb8d84a376a43b8f41518a11cc387b669b2ee6586")
+	g.Seed(nil)
+	g.Read(got)
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %x, expected %x", got, want)
+	}
+
+	// reads that do not end on a block boundary continue the keystream
+	g.Seed(nil)
+	split := make([]byte, 200)
+	g.Read(split[:7])
+	g.Read(split[7:130])
+	g.Read(split[130:])
+	g.Seed(nil)
+	whole := make([]byte, 200)
+	g.Read(whole)
+	if !bytes.Equal(split, whole) {
+		t.Error("split reads differ from a single read")
+	}
+}
+
+func TestPRNGSeeds(t *testing.T) {
+	for id, seed := range map[string][]byte{
+		"pcg32":              make([]byte, 17),
+		"xoshiro256starstar": make([]byte, 32),
+		"xorshift128plus":    make([]byte, 8),
+		"chacha20":           make([]byte, 49),
+	} {
+		if _, err := NewSeeded(id, seed); !errors.Is(err, ErrInvalidSeed) {
+			t.Errorf("%s with %d-byte seed: got %v, expected ErrInvalidSeed", id, len(seed), err)
+		}
+	}
+}
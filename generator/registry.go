@@ -0,0 +1,102 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	ErrUnknownGenerator   = errors.New("unknown generator")
+	ErrDuplicateGenerator = errors.New("generator already registered")
+)
+
+// Factory builds a new, unseeded Generator.
+type Factory func() Generator
+
+// Registry maps generator ids (e.g. "hmac-sha256") to the factories building them.
+// It is safe for concurrent use.
+type Registry struct {
+	mu        sync.RWMutex
+	ids       []string // ids in registration order
+	factories map[string]Factory
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]Factory)}
+}
+
+// Register adds a generator to the registry under the given id.
+// It returns an error if the id is empty or already in use.
+func (r *Registry) Register(id string, factory Factory) error {
+	if id == "" || factory == nil {
+		return fmt.Errorf("invalid registration for generator %q", id)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.factories[id]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateGenerator, id)
+	}
+	r.ids = append(r.ids, id)
+	r.factories[id] = factory
+	return nil
+}
+
+// IDs returns the ids of every registered generator in registration order.
+func (r *Registry) IDs() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]string, len(r.ids))
+	copy(ids, r.ids)
+	return ids
+}
+
+// New builds the generator registered under the given id.
+func (r *Registry) New(id string) (Generator, error) {
+	r.mu.RLock()
+	factory, ok := r.factories[id]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownGenerator, id)
+	}
+	return factory(), nil
+}
+
+// DefaultRegistry holds the generators of this module.
+var DefaultRegistry = NewRegistry()
+
+// Register adds a generator to the DefaultRegistry. It panics if the id is already in use,
+// so that third-party generators can register themselves from an init function.
+func Register(id string, factory Factory) {
+	if err := DefaultRegistry.Register(id, factory); err != nil {
+		panic(err)
+	}
+}
+
+// IDs returns the ids of every generator in the DefaultRegistry.
+func IDs() []string {
+	return DefaultRegistry.IDs()
+}
+
+// New builds the generator registered under the given id in the DefaultRegistry.
+func New(id string) (Generator, error) {
+	return DefaultRegistry.New(id)
+}
+
+// NewSeeded builds the generator registered under the given id in the DefaultRegistry
+// and seeds it.
+func NewSeeded(id string, seed []byte) (Generator, error) {
+	g, err := New(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.Seed(seed); err != nil {
+		return nil, fmt.Errorf("%s: %w", id, err)
+	}
+	return g, nil
+}
@@ -0,0 +1,289 @@
+package generator
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"math/bits"
+)
+
+// The sample generators of the NIST reference implementation (SP 800-22, Appendix D.3).
+// Some of them are deliberately weak, and serve as baselines for the tests: their output
+// should fail, or pass, the same tests as in the reference implementation.
+//
+// An empty seed selects the starting values of the reference implementation; otherwise
+// the seed, read as a big-endian integer, gives the starting value of the generator.
+
+// bitBuffer packs the bits produced by a sample generator into bytes.
+type bitBuffer struct {
+	fill func(bits []byte) []byte // appends the next bits of the generator, one per byte
+	bits []byte                   // bits produced but not read yet
+}
+
+func (b *bitBuffer) Read(p []byte) (int, error) {
+	if b.fill == nil {
+		return 0, ErrNotSeeded
+	}
+	for i := range p {
+		for len(b.bits) < 8 {
+			// move the pending bits to the front so that the buffer does not grow
+			b.bits = b.fill(append(b.bits[:0:0], b.bits...))
+		}
+		var c byte
+		for _, bit := range b.bits[:8] {
+			c = c<<1 | bit
+		}
+		b.bits = b.bits[8:]
+		p[i] = c
+	}
+	return len(p), nil
+}
+
+// appendBits appends the n low bits of x to dst, most significant bit first.
+func appendBits(dst []byte, x *big.Int, n int) []byte {
+	for i := n - 1; i >= 0; i-- {
+		dst = append(dst, byte(x.Bit(i)))
+	}
+	return dst
+}
+
+func hexInt(s string) *big.Int {
+	x, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("invalid hexadecimal constant " + s)
+	}
+	return x
+}
+
+// seedInt returns the seed as a big-endian integer, or def for an empty seed.
+func seedInt(seed []byte, def *big.Int) *big.Int {
+	if len(seed) == 0 {
+		return new(big.Int).Set(def)
+	}
+	return new(big.Int).SetBytes(seed)
+}
+
+var (
+	// the 512-bit prime modulus and starting value of the Quadratic Congruential Generator I
+	// and of the Modular Exponentiation Generator
+	stsPrime = hexInt("987b6a6bf2c56a97291c445409920032499f9ee7ad128301b5d0254aa1a9633fdbd378d40149f1e23a13849f3d45992f5c4c6b7104099bc301f6005f9d8115e1")
+	stsG     = hexInt("3844506a9456c564b8b8538e0cc15aff46c95e69600f084f0657c2401b3c244734b62ea9bb95be4923b9b7e84eeaf1a224894ef0328d44bc3eb3e983644da3f5")
+
+	// the starting value of the Quadratic Congruential Generator II and the Cubic Congruential Generator
+	stsG2 = hexInt("7844506a9456c564b8b8538e0cc15aff46c95e69600f084f0657c2401b3c244734b62ea9bb95be4923b9b7e84eeaf1a224894ef0328d44bc3eb3e983644da3f5")
+
+	// the starting exponent of the Modular Exponentiation Generator
+	stsY = hexInt("7ab36982ce1adf832019cdfeb2393cabdf0214ec")
+
+	mod512 = new(big.Int).Lsh(big.NewInt(1), 512)
+)
+
+// stsGenerator is a sample generator whose state is rebuilt from the seed by start.
+type stsGenerator struct {
+	bitBuffer
+	name  string
+	start func(seed []byte) (func(bits []byte) []byte, error)
+}
+
+func (g *stsGenerator) Name() string {
+	return g.name
+}
+
+func (g *stsGenerator) Seed(seed []byte) error {
+	fill, err := g.start(seed)
+	if err != nil {
+		return err
+	}
+	g.fill, g.bits = fill, nil
+	return nil
+}
+
+func stsFactory(name string, start func(seed []byte) (func(bits []byte) []byte, error)) Factory {
+	return func() Generator {
+		return &stsGenerator{name: name, start: start}
+	}
+}
+
+func init() {
+	Register("sts-lcg", stsFactory("Linear Congruential", lcg))
+	Register("sts-qcg1", stsFactory("Quadratic Congruential I", quadratic1))
+	Register("sts-qcg2", stsFactory("Quadratic Congruential II", quadratic2))
+	Register("sts-ccg", stsFactory("Cubic Congruential", cubic))
+	Register("sts-xor", stsFactory("Exclusive OR", exclusiveOR))
+	Register("sts-modexp", stsFactory("Modular Exponentiation", modExp))
+	Register("sts-bbs", stsFactory("Blum-Blum-Shub", blumBlumShub))
+	Register("sts-ms", stsFactory("Micali-Schnorr", micaliSchnorr))
+	Register("sts-gsha1", stsFactory("G Using SHA-1", gSHA1))
+}
+
+// lcg is the Linear Congruential Generator: z_i = 950706376 z_{i-1} mod (2^31 - 1),
+// each z_i giving one bit, 1 if z_i / (2^31 - 1) >= 1/2. The reference seed is 23482349.
+func lcg(seed []byte) (func([]byte) []byte, error) {
+	const m = 1<<31 - 1
+	z := seedInt(seed, big.NewInt(23482349))
+	z.Mod(z, big.NewInt(m))
+	if z.Sign() == 0 {
+		return nil, fmt.Errorf("%w: the seed must not be a multiple of 2^31 - 1", ErrInvalidSeed)
+	}
+
+	state := z.Uint64()
+	return func(bits []byte) []byte {
+		state = state * 950706376 % m
+		if state >= 1<<30 { // state / m >= 1/2
+			return append(bits, 1)
+		}
+		return append(bits, 0)
+	}, nil
+}
+
+// quadratic1 is the Quadratic Congruential Generator I: x_i = x_{i-1}^2 mod p for a 512-bit prime p,
+// each x_i giving 512 bits.
+func quadratic1(seed []byte) (func([]byte) []byte, error) {
+	x := seedInt(seed, stsG)
+	x.Mod(x, stsPrime)
+	if x.Sign() == 0 {
+		return nil, fmt.Errorf("%w: the seed must not be a multiple of the modulus", ErrInvalidSeed)
+	}
+
+	return func(bits []byte) []byte {
+		x.Mul(x, x).Mod(x, stsPrime)
+		return appendBits(bits, x, 512)
+	}, nil
+}
+
+// quadratic2 is the Quadratic Congruential Generator II: x_i = 2 x_{i-1}^2 + 3 x_{i-1} + 1 mod 2^512,
+// each x_i giving 512 bits.
+func quadratic2(seed []byte) (func([]byte) []byte, error) {
+	x := seedInt(seed, stsG2)
+	x.Mod(x, mod512)
+
+	t := new(big.Int)
+	return func(bits []byte) []byte {
+		// x = (2x + 3) x + 1
+		t.Lsh(x, 1).Add(t, big.NewInt(3))
+		x.Mul(x, t).Add(x, big.NewInt(1)).Mod(x, mod512)
+		return appendBits(bits, x, 512)
+	}, nil
+}
+
+// cubic is the Cubic Congruential Generator: x_i = x_{i-1}^3 mod 2^512, each x_i giving 512 bits.
+func cubic(seed []byte) (func([]byte) []byte, error) {
+	x := seedInt(seed, stsG2)
+	x.Mod(x, mod512)
+
+	t := new(big.Int)
+	return func(bits []byte) []byte {
+		t.Mul(x, x)
+		x.Mul(x, t).Mod(x, mod512)
+		return appendBits(bits, x, 512)
+	}, nil
+}
+
+// xorSeed holds the 127 starting bits of the Exclusive OR Generator of the reference implementation.
+const xorSeed = "0001011011011001000101111001001010011011101101000100000010101111111010100100001010110110000000000100110000101110011111111100111"
+
+// exclusiveOR is the Exclusive OR Generator: x_i = x_{i-1} XOR x_{i-127}, from 127 starting bits.
+// A seed gives its first 127 bits, padded with zeros.
+func exclusiveOR(seed []byte) (func([]byte) []byte, error) {
+	var x [127]byte
+	if len(seed) == 0 {
+		for i, c := range xorSeed {
+			x[i] = byte(c - '0')
+		}
+	} else {
+		nonZero := false
+		for i := range x {
+			if i/8 < len(seed) {
+				x[i] = seed[i/8] >> uint(7-i%8) & 1
+				nonZero = nonZero || x[i] == 1
+			}
+		}
+		if !nonZero {
+			return nil, fmt.Errorf("%w: the first 127 bits of the seed must not all be zero", ErrInvalidSeed)
+		}
+	}
+
+	// x[i%127] holds x_{i-127} until it is replaced by x_i
+	i := 127
+	return func(bits []byte) []byte {
+		x[i%127] ^= x[(i-1)%127]
+		bits = append(bits, x[i%127])
+		i++
+		return bits
+	}, nil
+}
+
+// modExp is the Modular Exponentiation Generator: x_i = g^{y_{i-1}} mod p for the 512-bit prime p,
+// each x_i giving 512 bits, and y_i being the 160 least significant bits of x_i.
+func modExp(seed []byte) (func([]byte) []byte, error) {
+	y := seedInt(seed, stsY)
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 160), big.NewInt(1))
+	y.And(y, mask)
+
+	x := new(big.Int)
+	return func(bits []byte) []byte {
+		x.Exp(stsG, y, stsPrime)
+		y.And(x, mask)
+		return appendBits(bits, x, 512)
+	}, nil
+}
+
+// gSHA1Key is the starting value of XKEY of the G Using SHA-1 Generator of the reference implementation.
+var gSHA1Key = hexInt("ec822a619d6ed5d9492218a7a4c5b15d57c61601")
+
+// gSHA1 is the G Using SHA-1 Generator, the pseudorandom generator of FIPS 186-2 Appendix 3.1
+// with b = 160 and no optional input: x_j = G(t, XKEY), XKEY = 1 + XKEY + x_j mod 2^160,
+// each x_j giving 160 bits. G is the SHA-1 compression function applied to XKEY padded with zeros.
+func gSHA1(seed []byte) (func([]byte) []byte, error) {
+	mod := new(big.Int).Lsh(big.NewInt(1), 160)
+	key := seedInt(seed, gSHA1Key)
+	key.Mod(key, mod)
+
+	x := new(big.Int)
+	one := big.NewInt(1)
+	return func(bits []byte) []byte {
+		var block [64]byte
+		key.FillBytes(block[:20])
+		var out [20]byte
+		sha1Block(&out, &block)
+
+		x.SetBytes(out[:])
+		key.Add(key, one).Add(key, x).Mod(key, mod)
+		return appendBits(bits, x, 160)
+	}, nil
+}
+
+// sha1Block writes to out the SHA-1 compression function of the single block, starting from
+// the initial hash value of SHA-1, without the padding of SHA-1.
+func sha1Block(out *[20]byte, block *[64]byte) {
+	h := [5]uint32{0x67452301, 0xefcdab89, 0x98badcfe, 0x10325476, 0xc3d2e1f0}
+
+	var w [80]uint32
+	for i := 0; i < 16; i++ {
+		w[i] = binary.BigEndian.Uint32(block[4*i:])
+	}
+	for i := 16; i < 80; i++ {
+		w[i] = bits.RotateLeft32(w[i-3]^w[i-8]^w[i-14]^w[i-16], 1)
+	}
+
+	a, b, c, d, e := h[0], h[1], h[2], h[3], h[4]
+	for i := 0; i < 80; i++ {
+		var f, k uint32
+		switch {
+		case i < 20:
+			f, k = b&c|^b&d, 0x5a827999
+		case i < 40:
+			f, k = b^c^d, 0x6ed9eba1
+		case i < 60:
+			f, k = b&c|b&d|c&d, 0x8f1bbcdc
+		default:
+			f, k = b^c^d, 0xca62c1d6
+		}
+		t := bits.RotateLeft32(a, 5) + f + e + k + w[i]
+		a, b, c, d, e = t, a, bits.RotateLeft32(b, 30), c, d
+	}
+
+	for i, v := range [5]uint32{a, b, c, d, e} {
+		binary.BigEndian.PutUint32(out[4*i:], h[i]+v)
+	}
+}
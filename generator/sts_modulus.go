@@ -0,0 +1,114 @@
+package generator
+
+import (
+	"crypto/sha512"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// The Blum-Blum-Shub and Micali-Schnorr generators need an RSA-like modulus n = pq. The primes of
+// the reference implementation are not reproduced here: both generators use the 512-bit primes
+// found by derivedPrime, which are fixed so that their output is deterministic.
+
+// derivedPrime returns the smallest 512-bit prime p >= SHA-512(label) with p = 3 mod 4,
+// and p - 1 not divisible by any of coprime.
+func derivedPrime(label string, coprime ...int64) *big.Int {
+	sum := sha512.Sum512([]byte(label))
+	p := new(big.Int).SetBytes(sum[:])
+	p.SetBit(p, 511, 1)
+	p.SetBit(p, 510, 1) // so that the product of two primes has 1024 bits
+	p.SetBit(p, 1, 1)
+	p.SetBit(p, 0, 1)
+
+	four := big.NewInt(4)
+	pm1, r := new(big.Int), new(big.Int)
+search:
+	for ; ; p.Add(p, four) {
+		pm1.Sub(p, big.NewInt(1))
+		for _, e := range coprime {
+			if r.Mod(pm1, big.NewInt(e)).Sign() == 0 {
+				continue search
+			}
+		}
+		if p.ProbablyPrime(32) {
+			return p
+		}
+	}
+}
+
+var (
+	bbsOnce    sync.Once
+	bbsModulus *big.Int
+	bbsSeed    *big.Int
+
+	msOnce    sync.Once
+	msModulus *big.Int
+	msSeed    *big.Int
+)
+
+func bbsParameters() (n, seed *big.Int) {
+	bbsOnce.Do(func() {
+		bbsModulus = new(big.Int).Mul(derivedPrime("blum-blum-shub p"), derivedPrime("blum-blum-shub q"))
+		sum := sha512.Sum512([]byte("blum-blum-shub seed"))
+		bbsSeed = new(big.Int).SetBytes(sum[:])
+	})
+	return bbsModulus, bbsSeed
+}
+
+// blumBlumShub is the Blum-Blum-Shub Generator: x_0 = s^2 mod n, x_i = x_{i-1}^2 mod n
+// for the product n of two 512-bit primes congruent to 3 modulo 4, each x_i giving its
+// least significant bit. The seed s must be coprime to n.
+func blumBlumShub(seed []byte) (func([]byte) []byte, error) {
+	n, def := bbsParameters()
+	x := seedInt(seed, def)
+	x.Mod(x, n)
+	if x.Cmp(big.NewInt(1)) <= 0 || new(big.Int).GCD(nil, nil, x, n).Cmp(big.NewInt(1)) != 0 {
+		return nil, fmt.Errorf("%w: the seed must be coprime to the modulus and greater than 1", ErrInvalidSeed)
+	}
+	x.Mul(x, x).Mod(x, n)
+
+	return func(bits []byte) []byte {
+		x.Mul(x, x).Mod(x, n)
+		return append(bits, byte(x.Bit(0)))
+	}, nil
+}
+
+// Parameters of the Micali-Schnorr Generator as in the reference implementation: a modulus of
+// N = 1024 bits, the exponent e = 11 (80e <= N), k = floor(N(1 - 2/e)) = 837 output bits and
+// r = N - k = 187 bits of state per step.
+const (
+	msExponent = 11
+	msOutBits  = 837
+	msBits     = 1024 - msOutBits
+)
+
+func msParameters() (n, seed *big.Int) {
+	msOnce.Do(func() {
+		// gcd(e, (p-1)(q-1)) = 1 for the RSA-like exponentiation
+		msModulus = new(big.Int).Mul(derivedPrime("micali-schnorr p", msExponent), derivedPrime("micali-schnorr q", msExponent))
+		sum := sha512.Sum512([]byte("micali-schnorr seed"))
+		msSeed = new(big.Int).SetBytes(sum[:])
+	})
+	return msModulus, msSeed
+}
+
+// micaliSchnorr is the Micali-Schnorr Generator: y_i = x_{i-1}^e mod n, x_i being the r most
+// significant bits of y_i, and each y_i giving its k least significant bits. The seed gives
+// x_0 modulo 2^r.
+func micaliSchnorr(seed []byte) (func([]byte) []byte, error) {
+	n, def := msParameters()
+	x := seedInt(seed, def)
+	x.Mod(x, new(big.Int).Lsh(big.NewInt(1), msBits))
+	if x.Cmp(big.NewInt(1)) <= 0 {
+		return nil, fmt.Errorf("%w: the seed must be greater than 1 modulo 2^%d", ErrInvalidSeed, msBits)
+	}
+
+	e := big.NewInt(msExponent)
+	y := new(big.Int)
+	return func(bits []byte) []byte {
+		y.Exp(x, e, n)
+		x.Rsh(y, msOutBits)
+		return appendBits(bits, y, msOutBits)
+	}, nil
+}
@@ -0,0 +1,215 @@
+package generator
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"errors"
+	"math"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/notJoon/drbg/nist"
+)
+
+func TestSTSWeakGenerators(t *testing.T) {
+	// the single-sequence tests of the reference implementation, with its parameters
+	ones := []uint8{1, 1, 1, 1, 1, 1, 1, 1, 1}
+	battery := []nist.Test{
+		nist.NewFrequencyTest(),
+		nist.NewBlockFrequencyTest(128),
+		nist.NewCumulativeSumsTest(nist.CusumBoth),
+		nist.NewRunsTest(),
+		nist.NewLongestRunOfOnesTest(),
+		nist.NewRankTest(),
+		nist.NewDFTTest(),
+		nist.NewOverlappingTemplateTest(ones, 1032),
+		nist.NewUniversalTest(7, 1280),
+		nist.NewApproximateEntropyTest(10),
+		nist.NewSerialTest(16),
+		nist.NewLinearComplexityTest(500),
+	}
+
+	// with the reference seeds, the tests each generator fails on 1,000,000 bits
+	for _, tc := range []struct {
+		id     string
+		failed []string
+	}{
+		// the output is that of lcg_rand (TestSTSLCGReference), and 950706376 is a primitive root
+		// modulo 2^31 - 1 with a good lattice structure: its leading bit passes every test
+		{"sts-lcg", nil},
+		// the low bits of x^3 mod 2^512 have short periods
+		{"sts-ccg", []string{"Runs Test", "Approximate Entropy Test"}},
+		// the output of a 127-bit LFSR has a linear complexity of at most 127 in every block
+		{"sts-xor", []string{"Linear Complexity Test"}},
+	} {
+		g, err := NewSeeded(tc.id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bs, err := ReadBits(g, 1000000)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var failed []string
+		for _, test := range battery {
+			res, err := test.Run(bs)
+			if err != nil {
+				t.Fatalf("%s: %s: %v", tc.id, test.Name(), err)
+			}
+			if !res.Passed() {
+				failed = append(failed, test.Name())
+			}
+		}
+		if !slices.Equal(failed, tc.failed) {
+			t.Errorf("%s failed %q, expected %q", tc.id, failed, tc.failed)
+		}
+	}
+}
+
+func TestSTSStrongGenerators(t *testing.T) {
+	tests := []nist.Test{nist.NewFrequencyTest(), nist.NewBlockFrequencyTest(128), nist.NewRunsTest(), nist.NewLongestRunOfOnesTest()}
+	for _, id := range []string{"sts-qcg1", "sts-qcg2", "sts-modexp", "sts-bbs", "sts-ms", "sts-gsha1"} {
+		g, err := NewSeeded(id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Blum-Blum-Shub gives one bit per modular squaring: keep the sequences short
+		bs, err := ReadBits(g, 100000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, test := range tests {
+			res, err := test.Run(bs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !res.Passed() {
+				t.Errorf("%s failed the %s with p-values %v", id, test.Name(), res.PValues)
+			}
+		}
+	}
+}
+
+// lcgRand is lcg_rand of the reference implementation, in the same floating-point arithmetic:
+// it fills dunif with z_i / (2^31 - 1) and returns the last z_i.
+func lcgRand(seed float64, dunif []float64) float64 {
+	const (
+		two31 = 2147483648.0
+		mdls  = 2147483647.0
+		a1    = 41160.0     // 950706376 mod 2^16
+		a2    = 950665216.0 // 950706376 - a1
+	)
+	z := seed
+	for i := range dunif {
+		z = math.Floor(z)
+		z1, z2 := z*a1, z*a2
+		over1, over2 := math.Floor(z1/two31), math.Floor(z2/two31)
+		z1 -= over1 * two31
+		z2 -= over2 * two31
+		z = z1 + z2 + over1 + over2
+		z -= math.Floor(z/mdls) * mdls
+		dunif[i] = z / mdls
+	}
+	return z
+}
+
+func TestSTSLCGReference(t *testing.T) {
+	g, err := NewSeeded("sts-lcg", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const n = 100000
+	bs, err := ReadBits(g, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the reference implementation draws the bits of consecutive sequences from the same stream
+	dunif := make([]float64, n/2)
+	seed := 23482349.0
+	for part := 0; part < 2; part++ {
+		seed = lcgRand(seed, dunif)
+		for i, u := range dunif {
+			bit, _ := bs.Bit(part*len(dunif) + i)
+			if want := u >= 0.5; (bit == 1) != want {
+				t.Fatalf("bit %d is %d, lcg_rand gives %v", part*len(dunif)+i, bit, u)
+			}
+		}
+	}
+}
+
+func TestSTSQuadratic2(t *testing.T) {
+	g, err := NewSeeded("sts-qcg2", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := ReadBits(g, 512*64)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2x^2 + 3x + 1 = x + 1 mod 2: the least significant bit of x_i alternates
+	first, _ := bs.Bit(511)
+	for i := 1; i < 64; i++ {
+		if bit, _ := bs.Bit(512*i + 511); bit != first^uint8(i%2) {
+			t.Fatalf("least significant bit of x_%d is %d", i+1, bit)
+		}
+	}
+
+	// x_1 = 2 x_0^2 + 3 x_0 + 1 mod 2^512 from the reference starting value
+	x := new(big.Int).Mul(stsG2, stsG2)
+	x.Lsh(x, 1).Add(x, new(big.Int).Mul(stsG2, big.NewInt(3))).Add(x, big.NewInt(1)).Mod(x, mod512)
+	if got := new(big.Int).SetBytes(bs.Bytes()[:64]); got.Cmp(x) != 0 {
+		t.Errorf("x_1 = %x, expected %x", got, x)
+	}
+}
+
+func TestSTSSeeds(t *testing.T) {
+	for _, id := range IDs() {
+		g, err := New(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := g.(*stsGenerator); !ok {
+			continue
+		}
+		if _, err := g.Read(make([]byte, 1)); !errors.Is(err, ErrNotSeeded) {
+			t.Errorf("%s: got %v, expected ErrNotSeeded", id, err)
+		}
+
+		// a seed changes the starting value
+		if err := g.Seed(nil); err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+		reference, _ := ReadBits(g, 1024)
+		g.Seed(testSeed)
+		seeded, _ := ReadBits(g, 1024)
+		if bytes.Equal(reference.Bytes(), seeded.Bytes()) {
+			t.Errorf("%s: the seed did not change the output", id)
+		}
+	}
+
+	for id, seed := range map[string][]byte{
+		"sts-lcg":  {0x7f, 0xff, 0xff, 0xff},
+		"sts-xor":  make([]byte, 16),
+		"sts-bbs":  {1},
+		"sts-qcg1": stsPrime.Bytes(),
+	} {
+		if _, err := NewSeeded(id, seed); !errors.Is(err, ErrInvalidSeed) {
+			t.Errorf("%s with seed %x: got %v, expected ErrInvalidSeed", id, seed, err)
+		}
+	}
+}
+
+func TestSHA1Block(t *testing.T) {
+	// SHA-1 of the empty message is the compression of its padding, a single block 0x80 0x00...
+	var block [64]byte
+	block[0] = 0x80
+	var got [20]byte
+	sha1Block(&got, &block)
+	if want := sha1.Sum(nil); got != want {
+		t.Errorf("got %x, expected %x", got, want)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	stream "github.com/notJoon/drbg/bitstream"
+	"github.com/notJoon/drbg/generator"
+)
+
+// bitInput holds the flags selecting the bits tested by a battery: a file, or the output of a
+// generator.
+type bitInput struct {
+	filename    *string
+	inputFormat *string
+	wordSize    *int
+	endian      *string
+	lowBits     *int
+	gen         *string
+	seedHex     *string
+	bits        *int
+}
+
+// addInputFlags defines the input flags on flags. defaultBits is the default of -bits, the
+// number of bits drawn from -gen; batteries reading a source may default to 0, which draws
+// as many bits as the tests read.
+func addInputFlags(flags *flag.FlagSet, defaultBits int) *bitInput {
+	bitsUsage := "Number of bits to draw from -gen"
+	if defaultBits == 0 {
+		bitsUsage += ". 0 draws as many bits as the tests read"
+	}
+	return &bitInput{
+		filename:    flags.String("file", "", "File containing the random bits"),
+		inputFormat: flags.String("input-format", "decimal", "Encoding of the file: decimal (one integer per line), raw (binary), ascii ('0' and '1' characters) or hex"),
+		wordSize:    flags.Int("word-size", 8, "Width in bits of each integer of a decimal file: 8, 16, 32 or 64"),
+		endian:      flags.String("endian", "big", "Byte order of the integers of a decimal file: big or little"),
+		lowBits:     flags.Int("low-bits", 0, "Use only the low bits of each integer of a decimal file. 0 uses the whole word"),
+		gen:         flags.String("gen", "", "Test the output of this generator instead of a file (see generate -list)"),
+		seedHex:     flags.String("seed", "", "Seed of -gen in hexadecimal. If empty, the reference seed of the generator, or a random seed that is printed"),
+		bits:        flags.Int("bits", defaultBits, bitsUsage),
+	}
+}
+
+// read returns the selected bits and the name of their source.
+func (in *bitInput) read() (*stream.BitStream, string, error) {
+	switch {
+	case *in.filename == "" && *in.gen == "":
+		return nil, "", errors.New("no file specified")
+	case *in.filename != "" && *in.gen != "":
+		return nil, "", errors.New("-file cannot be combined with -gen")
+	}
+
+	if *in.gen != "" {
+		if *in.bits <= 0 {
+			return nil, "", fmt.Errorf("-bits must be positive, got %d", *in.bits)
+		}
+		g, source, err := seededGenerator(*in.gen, *in.seedHex)
+		if err != nil {
+			return nil, "", err
+		}
+		bs, err := generator.ReadBits(g, *in.bits)
+		return bs, source, err
+	}
+
+	format, err := stream.ParseFormat(*in.inputFormat)
+	if err != nil {
+		return nil, "", err
+	}
+	if format != stream.FormatDecimal {
+		bs, err := stream.FromFileFormat(*in.filename, format)
+		return bs, *in.filename, err
+	}
+	if *in.endian != "big" && *in.endian != "little" {
+		return nil, "", fmt.Errorf("unknown byte order %q (expected big or little)", *in.endian)
+	}
+	bs, err := stream.FromFileIntegers(*in.filename, stream.IntegerOptions{
+		WordSize:     *in.wordSize,
+		LittleEndian: *in.endian == "little",
+		LowBits:      *in.lowBits,
+	})
+	return bs, *in.filename, err
+}
+
+// source returns the selected bits as a BitSource and the name of their source. With -gen and
+// -bits 0, the output of the generator is read as it is consumed.
+func (in *bitInput) source() (stream.BitSource, string, error) {
+	if *in.gen != "" && *in.filename == "" && *in.bits == 0 {
+		g, source, err := seededGenerator(*in.gen, *in.seedHex)
+		if err != nil {
+			return nil, "", err
+		}
+		src, err := stream.NewStreamReader(g, stream.FormatRaw)
+		return src, source, err
+	}
+	bs, source, err := in.read()
+	if err != nil {
+		return nil, "", err
+	}
+	return stream.NewBitStreamReader(bs), source, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/notJoon/drbg/knuth"
+	nist "github.com/notJoon/drbg/nist"
+)
+
+// knuthTests implements the knuth subcommand: it runs the empirical tests of Knuth, The Art of
+// Computer Programming, Vol. 2, section 3.3.2, on the integers of a file or a generator.
+func knuthTests(args []string) {
+	flags := flag.NewFlagSet("knuth", flag.ExitOnError)
+	in := addInputFlags(flags, 1000000)
+	testList := flags.String("tests", "", "Comma-separated list of test ids to run (see -list). Empty runs every test")
+	list := flags.Bool("list", false, "List the tests")
+	width := flags.Int("width", 32, "Width in bits of the integers read from the input, 1 to 32")
+	d := flags.Int("d", 16, fmt.Sprintf("Number of categories of the equidistribution, serial, poker and coupon collector tests, 2 to %d", knuth.MaxD))
+	format := flags.String("format", "table", "Output format: table, json, csv or junit")
+	flags.Parse(args)
+
+	opts := knuth.Options{Width: *width, D: *d}
+	newTest := func(id string) (nist.Test, error) { return knuth.New(id, opts) }
+	if *list {
+		listTests(os.Stdout, knuth.IDs(), newTest)
+		return
+	}
+
+	switch *format {
+	case "table", "json", "csv", "junit":
+	default:
+		fmt.Printf("Error: unknown format %q (expected table, json, csv or junit)\n", *format)
+		os.Exit(1)
+	}
+
+	ids := appendIDs(nil, *testList)
+	if len(ids) == 0 {
+		ids = knuth.IDs()
+	}
+	tests := make([]nist.Test, 0, len(ids))
+	for _, id := range ids {
+		test, err := newTest(id)
+		if err != nil {
+			fmt.Printf("Error (%s): %v\n", id, err)
+			os.Exit(1)
+		}
+		tests = append(tests, test)
+	}
+
+	bs, source, err := in.read()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	rep := runTests(source, ids, tests, bs)
+	if err := writeReport(os.Stdout, *format, rep); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if _, _, _, errored := rep.Counts(); errored > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,49 @@
+package knuth
+
+import (
+	"math"
+
+	b "github.com/notJoon/drbg/bitstream"
+	nist "github.com/notJoon/drbg/nist"
+)
+
+const correlationN = 1000 // the smallest number of values
+
+type serialCorrelationTest struct{ opts Options }
+
+// NewSerialCorrelationTest returns the Serial Correlation Test (section 3.3.2 K): the serial
+// correlation coefficient C of U_0, ..., U_n-1 and U_1, ..., U_n-1, U_0 is approximately normal
+// with mean μ_n = -1/(n-1) and standard deviation σ_n = √(n(n-3)/(n+1)) / (n-1).
+func NewSerialCorrelationTest(opts Options) nist.Test { return serialCorrelationTest{opts} }
+
+func (serialCorrelationTest) Name() string    { return "Serial Correlation Test" }
+func (serialCorrelationTest) Section() string { return "3.3.2 K" }
+func (t serialCorrelationTest) Params() map[string]any {
+	return map[string]any{"width": t.opts.Width}
+}
+
+// MinLength returns the length of 1000 integers.
+func (t serialCorrelationTest) MinLength() int { return correlationN * t.opts.Width }
+
+func (t serialCorrelationTest) Run(bs *b.BitStream) (*nist.Result, error) {
+	s, err := newSequence(bs, t.opts.Width, correlationN)
+	if err != nil {
+		return nil, err
+	}
+	n := len(s.x)
+
+	var sum, squares, products float64
+	for i := range s.x {
+		u := s.u(i)
+		sum += u
+		squares += u * u
+		products += u * s.u((i+1)%n)
+	}
+	fn := float64(n)
+	c := (fn*products - sum*sum) / (fn*squares - sum*sum)
+
+	mean := -1 / (fn - 1)
+	sigma := math.Sqrt(fn*(fn-3)/(fn+1)) / (fn - 1)
+	z := (c - mean) / sigma
+	return result(t, s, nil, []float64{normalP(z)}, []float64{c}), nil
+}
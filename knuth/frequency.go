@@ -0,0 +1,95 @@
+package knuth
+
+import (
+	"math"
+	"slices"
+
+	b "github.com/notJoon/drbg/bitstream"
+	nist "github.com/notJoon/drbg/nist"
+)
+
+type equidistributionTest struct{ opts Options }
+
+// NewEquidistributionTest returns the Equidistribution Test (section 3.3.2 A): the counts of the
+// d categories are compared with their uniform distribution by a χ² test, and the integers
+// with the uniform distribution by the Kolmogorov-Smirnov statistics K+ and K-.
+func NewEquidistributionTest(opts Options) nist.Test { return equidistributionTest{opts} }
+
+func (equidistributionTest) Name() string    { return "Equidistribution Test" }
+func (equidistributionTest) Section() string { return "3.3.2 A" }
+func (t equidistributionTest) Params() map[string]any {
+	return map[string]any{"width": t.opts.Width, "d": t.opts.D}
+}
+
+// MinLength returns the length of 5 integers per category.
+func (t equidistributionTest) MinLength() int { return t.minimum() * t.opts.Width }
+
+func (t equidistributionTest) minimum() int { return 5 * t.opts.D }
+
+func (t equidistributionTest) Run(bs *b.BitStream) (*nist.Result, error) {
+	s, err := newSequence(bs, t.opts.Width, t.minimum())
+	if err != nil {
+		return nil, err
+	}
+	d, n := t.opts.D, len(s.x)
+
+	counts := make([]int, d)
+	for i := range s.x {
+		counts[s.category(i, d)]++
+	}
+	probs := make([]float64, d)
+	for i := range probs {
+		probs[i] = 1 / float64(d)
+	}
+	x, df := chiSquare(counts, probs, n)
+
+	sorted := slices.Clone(s.x)
+	slices.Sort(sorted)
+	scale := math.Exp2(float64(s.width))
+	upper, lower := make([]float64, n), make([]float64, n)
+	for i, v := range sorted {
+		upper[i] = (float64(v) + 1) / scale
+		lower[i] = float64(v) / scale
+	}
+	kPlus, kMinus := ks(upper, lower)
+
+	return result(t, s, []string{"chi-square", "K+", "K-"},
+		[]float64{chiSquareP(x, df), ksP(kPlus, n), ksP(kMinus, n)},
+		[]float64{x, kPlus, kMinus}), nil
+}
+
+type serialTest struct{ opts Options }
+
+// NewSerialTest returns the Serial Test (section 3.3.2 B): the counts of the d² pairs of
+// categories (Y_2j, Y_2j+1) are compared with their uniform distribution by a χ² test.
+func NewSerialTest(opts Options) nist.Test { return serialTest{opts} }
+
+func (serialTest) Name() string    { return "Serial Test" }
+func (serialTest) Section() string { return "3.3.2 B" }
+func (t serialTest) Params() map[string]any {
+	return map[string]any{"width": t.opts.Width, "d": t.opts.D}
+}
+
+// MinLength returns the length of 5 pairs per pair of categories.
+func (t serialTest) MinLength() int { return t.minimum() * t.opts.Width }
+
+func (t serialTest) minimum() int { return 10 * t.opts.D * t.opts.D }
+
+func (t serialTest) Run(bs *b.BitStream) (*nist.Result, error) {
+	s, err := newSequence(bs, t.opts.Width, t.minimum())
+	if err != nil {
+		return nil, err
+	}
+	d, n := t.opts.D, len(s.x)/2
+
+	counts := make([]int, d*d)
+	for j := 0; j < n; j++ {
+		counts[s.category(2*j, d)*d+s.category(2*j+1, d)]++
+	}
+	probs := make([]float64, d*d)
+	for i := range probs {
+		probs[i] = 1 / float64(d*d)
+	}
+	x, df := chiSquare(counts, probs, n)
+	return result(t, s, nil, []float64{chiSquareP(x, df)}, []float64{x}), nil
+}
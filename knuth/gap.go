@@ -0,0 +1,62 @@
+package knuth
+
+import (
+	"math"
+
+	b "github.com/notJoon/drbg/bitstream"
+	nist "github.com/notJoon/drbg/nist"
+)
+
+const (
+	gapBeta = 0.5 // the gaps are the runs of values outside [0, 1/2)
+	gapMax  = 64  // the gaps of gapMax or more values are counted together
+	gaps    = 100 // the smallest number of gaps tested
+)
+
+type gapTest struct{ opts Options }
+
+// NewGapTest returns the Gap Test (section 3.3.2 C) with α = 0 and β = 1/2: the lengths of the
+// gaps between the values falling in [0, 1/2), that is the runs above the mean, have the
+// geometric probabilities p (1 - p)^r with p = 1/2, and their counts are compared with them by a
+// χ² test.
+func NewGapTest(opts Options) nist.Test { return gapTest{opts} }
+
+func (gapTest) Name() string    { return "Gap Test" }
+func (gapTest) Section() string { return "3.3.2 C" }
+func (t gapTest) Params() map[string]any {
+	return map[string]any{"width": t.opts.Width, "alpha": 0.0, "beta": gapBeta}
+}
+
+// MinLength returns the length of 100 gaps on average.
+func (t gapTest) MinLength() int { return t.minimum() * t.opts.Width }
+
+func (gapTest) minimum() int { return int(gaps / gapBeta) }
+
+func (t gapTest) Run(bs *b.BitStream) (*nist.Result, error) {
+	s, err := newSequence(bs, t.opts.Width, t.minimum())
+	if err != nil {
+		return nil, err
+	}
+
+	// U < 1/2 exactly when the top bit of the integer is 0; the values after the last one
+	// falling in [0, 1/2) do not end a gap
+	counts := make([]int, gapMax+1)
+	n, r := 0, 0
+	for _, x := range s.x {
+		if x>>(s.width-1) != 0 {
+			r++
+			continue
+		}
+		counts[min(r, gapMax)]++
+		n++
+		r = 0
+	}
+
+	probs := make([]float64, gapMax+1)
+	for r := range probs {
+		probs[r] = gapBeta * math.Pow(1-gapBeta, float64(r))
+	}
+	probs[gapMax] = math.Pow(1-gapBeta, gapMax)
+	x, df := chiSquare(counts, probs, n)
+	return result(t, s, nil, []float64{chiSquareP(x, df)}, []float64{x}), nil
+}
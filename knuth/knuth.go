@@ -0,0 +1,188 @@
+// Package knuth implements the empirical tests of Knuth, The Art of Computer Programming, Vol. 2,
+// section 3.3.2, on the integers of a chosen width read from a bitstream, most significant bit
+// first.
+//
+// An integer X of w bits stands for the uniform value U = X / 2^w, and for the category
+// Y = ⌊dU⌋ in the tests counting d categories. Every test is a nist.Test, so that the tests can
+// be run and reported like the tests of SP 800-22. They report the p-values of χ² tests, whose
+// rare categories are counted together with their neighbours, or of the Kolmogorov-Smirnov
+// statistics K+ and K-.
+package knuth
+
+import (
+	"fmt"
+	"math"
+
+	b "github.com/notJoon/drbg/bitstream"
+	nist "github.com/notJoon/drbg/nist"
+)
+
+var ErrNotEnoughBits = b.ErrNotEnoughBits
+
+// MaxD is the largest number of categories, the serial test counting d² pairs.
+const MaxD = 1024
+
+// Options holds the parameters of the tests.
+type Options struct {
+	Width int // the width in bits of the integers, 1 to 32
+	D     int // the number of categories d of the equidistribution, serial, poker and coupon collector tests
+}
+
+// DefaultOptions returns the parameters used when none are given: 32-bit integers and 16
+// categories.
+func DefaultOptions() Options {
+	return Options{Width: 32, D: 16}
+}
+
+func (o Options) validate() error {
+	if o.Width < 1 || o.Width > 32 {
+		return fmt.Errorf("integer width must be between 1 and 32 bits, got %d", o.Width)
+	}
+	if o.D < 2 || o.D > MaxD || uint64(o.D) > 1<<o.Width {
+		return fmt.Errorf("number of categories must be between 2 and %d, got %d", min(MaxD, 1<<o.Width), o.D)
+	}
+	return nil
+}
+
+// tests lists the tests in the order of section 3.3.2.
+var tests = []struct {
+	id    string
+	build func(Options) nist.Test
+}{
+	{"equidistribution", NewEquidistributionTest},
+	{"serial", NewSerialTest},
+	{"gap", NewGapTest},
+	{"poker", NewPokerTest},
+	{"coupon-collector", NewCouponCollectorTest},
+	{"permutation", NewPermutationTest},
+	{"runs-up", NewRunsUpTest},
+	{"maximum-of-t", NewMaximumTest},
+	{"serial-correlation", NewSerialCorrelationTest},
+}
+
+// IDs returns the ids of every test, in the order of section 3.3.2.
+func IDs() []string {
+	ids := make([]string, len(tests))
+	for i, t := range tests {
+		ids[i] = t.id
+	}
+	return ids
+}
+
+// New builds the test of the given id with the given options.
+func New(id string, opts Options) (nist.Test, error) {
+	for _, t := range tests {
+		if t.id != id {
+			continue
+		}
+		if err := opts.validate(); err != nil {
+			return nil, err
+		}
+		return t.build(opts), nil
+	}
+	return nil, fmt.Errorf("%w: %s", nist.ErrUnknownTest, id)
+}
+
+// sequence holds the integers read from a bitstream.
+type sequence struct {
+	x     []uint32
+	width int
+}
+
+// newSequence returns the integers of width bits of bs. It returns ErrNotEnoughBits if bs holds
+// fewer than n integers.
+func newSequence(bs *b.BitStream, width, n int) (*sequence, error) {
+	count := bs.Len() / width
+	if count < n {
+		return nil, fmt.Errorf("%w: %d integers of %d bits needed, got %d", ErrNotEnoughBits, n, width, count)
+	}
+	data := bs.Bytes()
+	x := make([]uint32, count)
+	var acc uint64
+	have, j := 0, 0
+	for i := range x {
+		for have < width {
+			acc = acc<<8 | uint64(data[j])
+			have += 8
+			j++
+		}
+		have -= width
+		x[i] = uint32(acc >> have & (1<<width - 1))
+	}
+	return &sequence{x: x, width: width}, nil
+}
+
+// bits returns the number of bits of the integers.
+func (s *sequence) bits() int { return len(s.x) * s.width }
+
+// u returns the i-th integer as a uniform value, the middle of the interval it stands for.
+func (s *sequence) u(i int) float64 {
+	return (float64(s.x[i]) + 0.5) / math.Exp2(float64(s.width))
+}
+
+// category returns ⌊dU⌋ for the i-th integer.
+func (s *sequence) category(i, d int) int {
+	return int(uint64(s.x[i]) * uint64(d) >> s.width)
+}
+
+// chiSquare returns the χ² statistic of the counts of n observations in categories of the given
+// probabilities, and its degrees of freedom. Consecutive categories are counted together until
+// they expect at least 5 observations, the remaining tail being added to the last ones.
+func chiSquare(counts []int, probs []float64, n int) (x float64, df int) {
+	observed, expected := 0, 0.0
+	rest := float64(n) // the expected count of the categories not yet added
+	cells := 0
+	for i, p := range probs {
+		observed += counts[i]
+		expected += p * float64(n)
+		rest -= p * float64(n)
+		if expected >= 5 && rest >= 5 || i == len(probs)-1 {
+			d := float64(observed) - expected
+			x += d * d / expected
+			cells++
+			observed, expected = 0, 0
+		}
+	}
+	return x, cells - 1
+}
+
+// chiSquareP returns the p-value of a χ² statistic with df degrees of freedom.
+func chiSquareP(x float64, df int) float64 {
+	if df < 1 {
+		return 1
+	}
+	return nist.Igamc(float64(df)/2, x/2)
+}
+
+// normalP returns the two-sided p-value of a standard normal statistic.
+func normalP(z float64) float64 {
+	return math.Erfc(math.Abs(z) / math.Sqrt2)
+}
+
+// ks returns the Kolmogorov-Smirnov statistics K+ and K- of n sorted observations, given the
+// distribution function F at each observation. Integers stand for intervals of values: upper
+// holds F at the top of the interval of each observation and lower at its bottom, so that ties
+// make the statistics smaller rather than larger.
+func ks(upper, lower []float64) (kPlus, kMinus float64) {
+	n := float64(len(upper))
+	for j := range upper {
+		kPlus = max(kPlus, float64(j+1)/n-upper[j])
+		kMinus = max(kMinus, lower[j]-float64(j)/n)
+	}
+	return math.Sqrt(n) * kPlus, math.Sqrt(n) * kMinus
+}
+
+// ksP returns the p-value P(K_n >= k) of K+ or K- from its asymptotic distribution,
+// 1 - exp(-2k²) (1 - 2k / (3√n)) (section 3.3.1, eq. (27)).
+func ksP(k float64, n int) float64 {
+	if k <= 0 {
+		return 1
+	}
+	p := math.Exp(-2*k*k) * (1 - 2*k/(3*math.Sqrt(float64(n))))
+	return min(max(p, 0), 1)
+}
+
+// result builds the result of a test from the p-value and the statistic of each sub-test.
+func result(t nist.Test, s *sequence, labels []string, pValues, statistics []float64) *nist.Result {
+	return &nist.Result{Name: t.Name(), N: s.bits(), PValues: pValues, Labels: labels, Statistics: statistics}
+}
@@ -0,0 +1,163 @@
+package knuth
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	b "github.com/notJoon/drbg/bitstream"
+)
+
+func randomBits(bytes int, seed int64) *b.BitStream {
+	data := make([]byte, bytes)
+	rand.New(rand.NewSource(seed)).Read(data)
+	return b.NewBitStream(data)
+}
+
+func TestSequence(t *testing.T) {
+	bs := b.NewBitStream([]byte{0xab, 0xcd, 0xef, 0x12})
+	tests := []struct {
+		width    int
+		expected []uint32
+	}{
+		{4, []uint32{0xa, 0xb, 0xc, 0xd, 0xe, 0xf, 0x1, 0x2}},
+		{12, []uint32{0xabc, 0xdef}},
+		{32, []uint32{0xabcdef12}},
+		{5, []uint32{0b10101, 0b01111, 0b00110, 0b11110, 0b11110, 0b00100}},
+	}
+	for _, tt := range tests {
+		s, err := newSequence(bs, tt.width, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(s.x, tt.expected[:len(s.x)]) || len(s.x) != 32/tt.width {
+			t.Errorf("width %d: %x, expected %x", tt.width, s.x, tt.expected)
+		}
+	}
+
+	s := &sequence{x: []uint32{0, 1 << 31, 1<<32 - 1}, width: 32}
+	for i, expected := range []int{0, 8, 15} {
+		if y := s.category(i, 16); y != expected {
+			t.Errorf("category of %x = %d, expected %d", s.x[i], y, expected)
+		}
+	}
+}
+
+func TestCouponProbabilities(t *testing.T) {
+	for _, d := range []int{2, 5, 16, 64} {
+		probs := couponProbabilities(d)
+		total, mean, h := 0.0, 0.0, 0.0
+		for i, p := range probs {
+			total += p
+			mean += float64(d+i) * p
+		}
+		for i := 1; i <= d; i++ {
+			h += 1 / float64(i)
+		}
+		// the mean length is d H_d, short of the tail
+		if math.Abs(total-1) > 1e-12 || math.Abs(mean-float64(d)*h) > 1e-3*float64(d) {
+			t.Errorf("d = %d: the probabilities sum to %v, with mean %v, expected %v", d, total, mean, float64(d)*h)
+		}
+	}
+	// d = 2: a segment of length r is r - 1 equal values and a different one
+	if probs := couponProbabilities(2); math.Abs(probs[0]-0.5) > 1e-15 || math.Abs(probs[1]-0.25) > 1e-15 {
+		t.Errorf("d = 2: %v", probs[:2])
+	}
+}
+
+func TestOrdering(t *testing.T) {
+	seen := make([]bool, orderings)
+	values := []uint32{10, 20, 30, 40, 50}
+	var permute func(k int)
+	permute = func(k int) {
+		if k == len(values) {
+			f, ok := ordering(values)
+			if !ok || f < 0 || f >= orderings || seen[f] {
+				t.Fatalf("ordering of %v = %d, %v", values, f, ok)
+			}
+			seen[f] = true
+			return
+		}
+		for i := k; i < len(values); i++ {
+			values[k], values[i] = values[i], values[k]
+			permute(k + 1)
+			values[k], values[i] = values[i], values[k]
+		}
+	}
+	permute(0)
+	if _, ok := ordering([]uint32{1, 2, 3, 2, 5}); ok {
+		t.Error("ordering of values with ties")
+	}
+}
+
+func TestRandom(t *testing.T) {
+	bs := randomBits(1000000/8, 1)
+	for _, opts := range []Options{DefaultOptions(), {Width: 8, D: 64}} {
+		for _, id := range IDs() {
+			test, err := New(id, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := test.Run(bs)
+			if err != nil {
+				t.Fatalf("%s: %v", id, err)
+			}
+			for i, p := range res.PValues {
+				if p < 0.001 {
+					t.Errorf("%s %+v: p-value %d = %v on random data", id, opts, i, p)
+				}
+			}
+		}
+	}
+}
+
+func TestCounter(t *testing.T) {
+	// the integers of a counter wrapping around every 1000 steps are equidistributed, but
+	// always increase between the wraps
+	data := make([]byte, 4*40000)
+	for i := 0; i < len(data); i += 4 {
+		binary.BigEndian.PutUint32(data[i:], uint32(i/4%1000)*4294967)
+	}
+	bs := b.NewBitStream(data)
+	for _, id := range []string{"permutation", "runs-up", "maximum-of-t", "serial-correlation"} {
+		test, _ := New(id, DefaultOptions())
+		res, err := test.Run(bs)
+		if err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+		if res.Passed() {
+			t.Errorf("%s: p-values %v for a counter", id, res.PValues)
+		}
+	}
+	test, _ := New("equidistribution", DefaultOptions())
+	if res, _ := test.Run(bs); !res.Pass(0) {
+		t.Errorf("equidistribution: p-values %v for a counter", res.PValues)
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, opts := range []Options{{Width: 0, D: 2}, {Width: 33, D: 2}, {Width: 32, D: 1}, {Width: 4, D: 32}, {Width: 32, D: MaxD + 1}} {
+		if _, err := New("serial", opts); err == nil {
+			t.Errorf("no error for %+v", opts)
+		}
+	}
+	if _, err := New("spectral", DefaultOptions()); err == nil {
+		t.Error("no error for an unknown test")
+	}
+}
+
+func TestNotEnoughBits(t *testing.T) {
+	bs := randomBits(100, 1)
+	for _, id := range IDs() {
+		test, _ := New(id, DefaultOptions())
+		if _, err := test.Run(bs); !errors.Is(err, ErrNotEnoughBits) {
+			t.Errorf("%s: error %v, expected ErrNotEnoughBits", id, err)
+		}
+		if test.MinLength() <= bs.Len() {
+			t.Errorf("%s: minimum length %d", id, test.MinLength())
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package knuth
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	b "github.com/notJoon/drbg/bitstream"
+	nist "github.com/notJoon/drbg/nist"
+)
+
+const (
+	permutationT = 5    // the values of a group of the permutation test
+	permutations = 600  // the smallest number of groups, 5 per ordering
+	maximumT     = 5    // the values of a group of the maximum-of-t test
+	maxima       = 1000 // the smallest number of groups of the maximum-of-t test
+	runsUpMax    = 6    // the runs of runsUpMax or more values are counted together
+	runsUp       = 1000 // the smallest number of runs
+	orderings    = 120  // t!, the orderings of a group
+)
+
+type permutationTest struct{ opts Options }
+
+// NewPermutationTest returns the Permutation Test (section 3.3.2 F): the t! relative orderings
+// of groups of t = 5 values are equally likely, and their counts are compared with them by a χ²
+// test. The groups holding equal integers are skipped, the orderings of the others being still
+// equally likely.
+func NewPermutationTest(opts Options) nist.Test { return permutationTest{opts} }
+
+func (permutationTest) Name() string    { return "Permutation Test" }
+func (permutationTest) Section() string { return "3.3.2 F" }
+func (t permutationTest) Params() map[string]any {
+	return map[string]any{"width": t.opts.Width, "t": permutationT}
+}
+
+// MinLength returns the length of 5 groups per ordering.
+func (t permutationTest) MinLength() int { return t.minimum() * t.opts.Width }
+
+func (permutationTest) minimum() int { return permutations * permutationT }
+
+func (t permutationTest) Run(bs *b.BitStream) (*nist.Result, error) {
+	s, err := newSequence(bs, t.opts.Width, t.minimum())
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]int, orderings)
+	n := 0
+	for j := 0; j+permutationT <= len(s.x); j += permutationT {
+		if f, ok := ordering(s.x[j : j+permutationT]); ok {
+			counts[f]++
+			n++
+		}
+	}
+	if n < permutations {
+		return nil, fmt.Errorf("%w: %d groups of distinct integers", ErrNotEnoughBits, n)
+	}
+	probs := make([]float64, orderings)
+	for i := range probs {
+		probs[i] = 1.0 / orderings
+	}
+	x, df := chiSquare(counts, probs, n)
+	return result(t, s, nil, []float64{chiSquareP(x, df)}, []float64{x}), nil
+}
+
+// ordering returns the number, from 0 to t! - 1, of the relative ordering of the values: the
+// digits of the number in the factorial number system count the later values smaller than each
+// value. It returns false if two values are equal.
+func ordering(values []uint32) (int, bool) {
+	f := 0
+	for i, v := range values {
+		smaller := 0
+		for _, w := range values[i+1:] {
+			if w == v {
+				return 0, false
+			}
+			if w < v {
+				smaller++
+			}
+		}
+		f = f*(len(values)-i) + smaller
+	}
+	return f, true
+}
+
+type runsUpTest struct{ opts Options }
+
+// NewRunsUpTest returns the Run Test (section 3.3.2 G), in the form of exercise 3.3.2-14: the
+// value ending each run up is skipped, so that the lengths of the runs are independent, with the
+// probabilities 1/r! - 1/(r+1)!, and their counts are compared with them by a χ² test. Equal
+// integers end a run, which makes the test fail on narrow integers.
+func NewRunsUpTest(opts Options) nist.Test { return runsUpTest{opts} }
+
+func (runsUpTest) Name() string    { return "Runs Up Test" }
+func (runsUpTest) Section() string { return "3.3.2 G" }
+func (t runsUpTest) Params() map[string]any {
+	return map[string]any{"width": t.opts.Width}
+}
+
+// MinLength returns the length of 1000 runs on average, each one of e values with the skipped
+// one.
+func (t runsUpTest) MinLength() int { return t.minimum() * t.opts.Width }
+
+func (runsUpTest) minimum() int { return int(math.Ceil(runsUp * math.E)) }
+
+func (t runsUpTest) Run(bs *b.BitStream) (*nist.Result, error) {
+	s, err := newSequence(bs, t.opts.Width, t.minimum())
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]int, runsUpMax)
+	n := 0
+	for i := 0; i < len(s.x); {
+		r := 1
+		for i+r < len(s.x) && s.x[i+r] > s.x[i+r-1] {
+			r++
+		}
+		if i+r == len(s.x) {
+			break // the last run may not be over
+		}
+		counts[min(r, runsUpMax)-1]++
+		n++
+		i += r + 1
+	}
+
+	probs := make([]float64, runsUpMax)
+	factorial := 1.0 // r!
+	for r := 1; r < runsUpMax; r++ {
+		probs[r-1] = 1/factorial - 1/(factorial*float64(r+1))
+		factorial *= float64(r + 1)
+	}
+	probs[runsUpMax-1] = 1 / factorial
+	x, df := chiSquare(counts, probs, n)
+	return result(t, s, nil, []float64{chiSquareP(x, df)}, []float64{x}), nil
+}
+
+type maximumTest struct{ opts Options }
+
+// NewMaximumTest returns the Maximum-of-t Test (section 3.3.2 H): the maximum V of t = 5 values
+// has the distribution function x^t, and the maxima of the groups are compared with it by the
+// Kolmogorov-Smirnov statistics K+ and K-.
+func NewMaximumTest(opts Options) nist.Test { return maximumTest{opts} }
+
+func (maximumTest) Name() string    { return "Maximum-of-t Test" }
+func (maximumTest) Section() string { return "3.3.2 H" }
+func (t maximumTest) Params() map[string]any {
+	return map[string]any{"width": t.opts.Width, "t": maximumT}
+}
+
+// MinLength returns the length of 1000 groups.
+func (t maximumTest) MinLength() int { return t.minimum() * t.opts.Width }
+
+func (maximumTest) minimum() int { return maxima * maximumT }
+
+func (t maximumTest) Run(bs *b.BitStream) (*nist.Result, error) {
+	s, err := newSequence(bs, t.opts.Width, t.minimum())
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(s.x) / maximumT
+	v := make([]uint32, n)
+	for j := range v {
+		v[j] = slices.Max(s.x[j*maximumT : (j+1)*maximumT])
+	}
+	slices.Sort(v)
+
+	// the maximum of integers falls at or below v with probability ((v+1) / 2^w)^t
+	scale := math.Exp2(float64(s.width))
+	upper, lower := make([]float64, n), make([]float64, n)
+	for i, x := range v {
+		upper[i] = math.Pow((float64(x)+1)/scale, maximumT)
+		lower[i] = math.Pow(float64(x)/scale, maximumT)
+	}
+	kPlus, kMinus := ks(upper, lower)
+	return result(t, s, []string{"K+", "K-"},
+		[]float64{ksP(kPlus, n), ksP(kMinus, n)}, []float64{kPlus, kMinus}), nil
+}
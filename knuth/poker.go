@@ -0,0 +1,157 @@
+package knuth
+
+import (
+	"fmt"
+	"math"
+
+	b "github.com/notJoon/drbg/bitstream"
+	nist "github.com/notJoon/drbg/nist"
+)
+
+const (
+	pokerHand = 5   // the categories of a hand
+	hands     = 200 // the smallest number of hands tested
+	segments  = 100 // the smallest number of segments of the coupon collector test
+)
+
+// stirling5 holds the Stirling numbers of the second kind {5 r}, for r from 1 to 5.
+var stirling5 = [pokerHand]float64{1, 15, 25, 10, 1}
+
+type pokerTest struct{ opts Options }
+
+// NewPokerTest returns the Poker Test (section 3.3.2 D), in its simpler form: the number r of
+// distinct categories among 5 consecutive ones has the probability
+// d (d-1) ... (d-r+1) / d^5 {5 r}, and the counts of r of the hands are compared with them by a
+// χ² test.
+func NewPokerTest(opts Options) nist.Test { return pokerTest{opts} }
+
+func (pokerTest) Name() string    { return "Poker Test" }
+func (pokerTest) Section() string { return "3.3.2 D" }
+func (t pokerTest) Params() map[string]any {
+	return map[string]any{"width": t.opts.Width, "d": t.opts.D, "k": pokerHand}
+}
+
+// MinLength returns the length of 200 hands.
+func (t pokerTest) MinLength() int { return t.minimum() * t.opts.Width }
+
+func (pokerTest) minimum() int { return hands * pokerHand }
+
+func (t pokerTest) Run(bs *b.BitStream) (*nist.Result, error) {
+	s, err := newSequence(bs, t.opts.Width, t.minimum())
+	if err != nil {
+		return nil, err
+	}
+	d, n := t.opts.D, len(s.x)/pokerHand
+
+	counts := make([]int, pokerHand)
+	var hand [pokerHand]int
+	for j := 0; j < n; j++ {
+		r := 0
+		for i := range hand {
+			y := s.category(j*pokerHand+i, d)
+			distinct := true
+			for _, z := range hand[:r] {
+				if z == y {
+					distinct = false
+					break
+				}
+			}
+			if distinct {
+				hand[r] = y
+				r++
+			}
+		}
+		counts[r-1]++
+	}
+
+	probs := make([]float64, pokerHand)
+	falling := 1.0 // d (d-1) ... (d-r+1) / d^r
+	for r := 1; r <= pokerHand; r++ {
+		falling *= float64(d-r+1) / float64(d)
+		probs[r-1] = falling * math.Pow(float64(d), float64(r-pokerHand)) * stirling5[r-1]
+	}
+	x, df := chiSquare(counts, probs, n)
+	return result(t, s, nil, []float64{chiSquareP(x, df)}, []float64{x}), nil
+}
+
+type couponCollectorTest struct{ opts Options }
+
+// NewCouponCollectorTest returns the Coupon Collector's Test (section 3.3.2 E): the sequence is
+// cut into segments, each one ending when it holds every one of the d categories, and the counts
+// of the lengths of the segments are compared with their distribution by a χ² test.
+func NewCouponCollectorTest(opts Options) nist.Test { return couponCollectorTest{opts} }
+
+func (couponCollectorTest) Name() string    { return "Coupon Collector's Test" }
+func (couponCollectorTest) Section() string { return "3.3.2 E" }
+func (t couponCollectorTest) Params() map[string]any {
+	return map[string]any{"width": t.opts.Width, "d": t.opts.D}
+}
+
+// MinLength returns the length of 100 segments on average, each one of d H_d integers.
+func (t couponCollectorTest) MinLength() int { return t.minimum() * t.opts.Width }
+
+func (t couponCollectorTest) minimum() int {
+	h := 0.0
+	for i := 1; i <= t.opts.D; i++ {
+		h += 1 / float64(i)
+	}
+	return int(math.Ceil(segments * float64(t.opts.D) * h))
+}
+
+func (t couponCollectorTest) Run(bs *b.BitStream) (*nist.Result, error) {
+	s, err := newSequence(bs, t.opts.Width, t.minimum())
+	if err != nil {
+		return nil, err
+	}
+	d := t.opts.D
+	probs := couponProbabilities(d)
+	longest := d + len(probs) - 1 // the segments of this length or more are counted together
+
+	counts := make([]int, len(probs))
+	seen := make([]bool, d)
+	n, length, missing := 0, 0, d
+	for i := range s.x {
+		y := s.category(i, d)
+		length++
+		if !seen[y] {
+			seen[y] = true
+			missing--
+		}
+		if missing > 0 {
+			continue
+		}
+		counts[min(length, longest)-d]++
+		n++
+		clear(seen)
+		length, missing = 0, d
+	}
+	if n < 2 {
+		return nil, fmt.Errorf("%w: %d complete segments", ErrNotEnoughBits, n)
+	}
+	x, df := chiSquare(counts, probs, n)
+	return result(t, s, nil, []float64{chiSquareP(x, df)}, []float64{x}), nil
+}
+
+// couponProbabilities returns the probabilities that a segment collecting d categories has the
+// length r, from d on, the last one being the probability of the longer segments. A segment of
+// length r holds d - 1 categories after r - 1 values and draws the last one with probability
+// 1/d; the probabilities of the numbers of categories after each value are computed until the
+// longer segments are less likely than 10^-9.
+func couponProbabilities(d int) []float64 {
+	q := make([]float64, d) // q[j]: the probability of j categories among the values drawn
+	q[0] = 1
+	var probs []float64
+	tail := 1.0
+	for r := 1; tail > 1e-9; r++ {
+		if r >= d {
+			p := q[d-1] / float64(d)
+			probs = append(probs, p)
+			tail -= p
+		}
+		for j := min(r, d-1); j >= 1; j-- {
+			q[j] = q[j]*float64(j)/float64(d) + q[j-1]*float64(d-j+1)/float64(d)
+		}
+		q[0] = 0
+	}
+	return append(probs, max(tail, 0))
+}
@@ -3,16 +3,52 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 
 	stream "github.com/notJoon/drbg/bitstream"
+	"github.com/notJoon/drbg/generator"
 	nist "github.com/notJoon/drbg/nist"
-
-	"github.com/jedib0t/go-pretty/table"
+	"github.com/notJoon/drbg/report"
 )
 
 func main() {
+	// "generate" writes the output of a generator; "entropy" estimates the min-entropy of
+	// samples; "fips140" and "ais31" run the FIPS 140-2 and AIS 20/31 statistical tests;
+	// "diehard" and "knuth" run the Diehard battery and the tests of Knuth's TAOCP; "test", or no
+	// subcommand, runs the SP 800-22 tests
+	args := os.Args[1:]
+	if len(args) > 0 {
+		switch args[0] {
+		case "generate":
+			generate(args[1:])
+			return
+		case "entropy":
+			assessEntropy(args[1:])
+			return
+		case "fips140":
+			fipsTests(args[1:])
+			return
+		case "ais31":
+			aisTests(args[1:])
+			return
+		case "diehard":
+			diehardTests(args[1:])
+			return
+		case "knuth":
+			knuthTests(args[1:])
+			return
+		case "test":
+			args = args[1:]
+		}
+	}
+
 	allTests := flag.Bool("all", false, "Run all tests")
+	testList := flag.String("tests", "", "Comma-separated list of test ids to run (see -list)")
+	list := flag.Bool("list", false, "List the available tests")
 
 	frequency := flag.Bool("frequency", false, "Run Frequency (Monobit) Test")
 	blockFrequency := flag.Bool("block-frequency", false, "Run Frequency Test within a Block")
@@ -25,11 +61,14 @@ func main() {
 	dft := flag.Bool("dft", false, "Run Discrete Fourier Transform (Spectral) Test")
 
 	nonOverlappingTemplate := flag.Bool("non-overlapping", false, "Run Non-overlapping Template Matching Test.\nDefault template is \"000000001\" and block size is 10 bits.")
-	overlappingTemplate := flag.Bool("overlapping", false, "Run Overlapping Template Matching Test.\nDefault template is \"000000001\" and block size is 10 bits.")
+	overlappingTemplate := flag.Bool("overlapping", false, "Run Overlapping Template Matching Test.\nDefault template is \"111111111\" and block size is 1032 bits.")
 	// specifies the template B to match. Must be string of ones and zeros (e.g. "001")
-	templateB := flag.String("template", "000000001", "The template B to be matched (a string of ones and zeros)")
+	templateB := flag.String("template", "000000001", "The template B to be matched (a string of ones and zeros).\n\"all\" matches every aperiodic template of 9 bits as the NIST reference implementation does")
 	// specified the length of the substrting to test, in bits.
-	blockSize := flag.Uint64("block-size", 20, "The length in bits of the substring to be tested")
+	blockSize := flag.Uint64("block-size", 20, "The length in bits of the substring to be tested.\n0 selects the block lengths of the NIST reference implementation")
+	// the Overlapping Template Matching Test has its own parameters (SP 800-22 section 2.8.2)
+	overlappingB := flag.String("overlapping-template", "111111111", "The template B of the Overlapping Template Matching Test (a string of ones and zeros)")
+	overlappingBlockSize := flag.Uint64("overlapping-block-size", 1032, "The block length in bits of the Overlapping Template Matching Test")
 
 	universal := flag.Bool("universal", false, "Run Maurer's Universal Statistical Test")
 
@@ -43,291 +82,441 @@ func main() {
 	approximateEntropyBlockSize := flag.Uint64("entropy-block-size", 10, "The length in bits of the substring to be tested")
 
 	cusum := flag.Bool("cusum", false, "Run Cumulative Sums (Cusums) Test. Default mode is 0 (forward).")
-	mode := flag.Int("mode", 0, "The mode of the test (0 or 1)")
+	mode := flag.Int("mode", 0, "The mode of the test (0 forward, 1 backward, 2 both)")
 
 	randomExcursions := flag.Bool("random-excursions", false, "Run Random Excursions Test")
 	randomExcursionsVariant := flag.Bool("random-excursions-variant", false, "Run Random Excursions Variant Test")
 
+	// not part of SP 800-22: -all does not select it
+	autocorrelation := flag.Bool("autocorrelation", false, "Run Autocorrelation Test, which reports the lags of the strongest deviations")
+	lagList := flag.String("lags", "1-32", "The lags of the Autocorrelation Test: comma-separated lags or ranges of lags (e.g. \"1-32,64,100-128\")")
+
 	filename := flag.String("file", "", "File containing the random bits")
+	gen := flag.String("gen", "", "Test the output of this generator instead of a file (see generate -list)")
+	seedHex := flag.String("seed", "", "Seed of -gen in hexadecimal. If empty, the reference seed of the generator, or a random seed that is printed")
+	genBits := flag.Int("bits", 1000000, "Number of bits to draw from -gen")
+	inputFormat := flag.String("input-format", "decimal", "Encoding of the file: decimal (one integer per line), raw (binary), ascii ('0' and '1' characters) or hex")
+	wordSize := flag.Int("word-size", 8, "Width in bits of each integer of a decimal file: 8, 16, 32 or 64")
+	endian := flag.String("endian", "big", "Byte order of the integers of a decimal file: big or little")
+	lowBits := flag.Int("low-bits", 0, "Use only the low bits of each integer of a decimal file. 0 uses the whole word")
+
+	// SP 800-22 section 4.2: split the input into m sequences of n bits and assess the generator
+	streams := flag.Int("streams", 0, "Number of sequences (m) to split the input into. 0 tests the whole input as one sequence")
+	bitstreamLength := flag.Int("bitstream-length", 0, "The length in bits of each sequence (n). Defaults to the input length divided by -streams")
+	format := flag.String("format", "table", "Output format: table, json, csv or junit")
+	streamInput := flag.Bool("stream", false, "Read the file in a single pass without loading it in memory.\nOnly the tests that can be computed incrementally are run")
+	reportFile := flag.String("report", "", "Write a finalAnalysisReport.txt compatible report of the -streams assessment to this file")
 
 	help := flag.Bool("help", false, "Show help message")
-	flag.Parse()
+	flag.CommandLine.Parse(args)
 
 	if *help {
 		flag.Usage()
 		os.Exit(0)
 	}
 
-	if *filename == "" {
+	if *list {
+		listTests(os.Stdout, append(nist.IDs(), "autocorrelation"), func(id string) (nist.Test, error) {
+			return newTest(id, nist.DefaultOptions(), nil)
+		})
+		os.Exit(0)
+	}
+
+	if *filename == "" && *gen == "" {
 		fmt.Println("Error: No file specified")
 		os.Exit(1)
 	}
+	if *filename != "" && *gen != "" {
+		fmt.Println("Error: -file cannot be combined with -gen")
+		os.Exit(1)
+	}
 
-	var (
-		bs  *stream.BitStream
-		err error
-	)
-
-	// regulation of the bitstream
-	// ????
-	if *frequency {
-		bs, err = stream.FromFileWithLimit(*filename, 100)
-	} else {
-		bs, err = stream.FromFile(*filename)
+	switch *format {
+	case "table", "json", "csv", "junit":
+	default:
+		fmt.Printf("Error: unknown format %q (expected table, json, csv or junit)\n", *format)
+		os.Exit(1)
 	}
 
+	inFormat, err := stream.ParseFormat(*inputFormat)
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	// test result counters
-	pass, fail := 0, 0
-
-	// Draw table for test results
-	t := table.NewWriter()
-	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(table.Row{"NIST Statistical Test Suite", "p-value", "Result"})
+	if *endian != "big" && *endian != "little" {
+		fmt.Printf("Error: unknown byte order %q (expected big or little)\n", *endian)
+		os.Exit(1)
+	}
+	intOpts := stream.IntegerOptions{
+		WordSize:     *wordSize,
+		LittleEndian: *endian == "little",
+		LowBits:      *lowBits,
+	}
 
-	if *allTests || *frequency {
-		testName := "Frequency (Monobit) Test"
-		p_val, isRandom, err := nist.FrequencyTest(bs)
-		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-			os.Exit(1)
+	// Collect the selected tests
+	var ids []string
+	if *allTests {
+		ids = nist.IDs()
+	} else {
+		// the per-test flags are shorthands for the ids of the registry
+		legacy := []struct {
+			id       string
+			selected bool
+		}{
+			{"frequency", *frequency},
+			{"block-frequency", *blockFrequency},
+			{"runs", *runs},
+			{"longest-run", *longestRun},
+			{"rank", *rank},
+			{"dft", *dft},
+			{"non-overlapping-template", *nonOverlappingTemplate},
+			{"overlapping-template", *overlappingTemplate},
+			{"universal", *universal},
+			{"linear-complexity", *linearComplexity},
+			{"serial", *serial},
+			{"approximate-entropy", *approximateEntropy},
+			{"cumulative-sums", *cusum},
+			{"random-excursions", *randomExcursions},
+			{"random-excursions-variant", *randomExcursionsVariant},
+			{"autocorrelation", *autocorrelation},
 		}
-
-		writeResult(t, testName, p_val, isRandom, &pass, &fail)
-	}
-	if *allTests || *blockFrequency {
-		testName := "Frequency Test within a Block"
-		p_val, isRandom, err := nist.BlockFrequencyTest(bs, uint64(*blockFrequencyBlockSize))
-		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-			os.Exit(1)
+		for _, l := range legacy {
+			if l.selected {
+				ids = append(ids, l.id)
+			}
 		}
+		ids = appendIDs(ids, *testList)
+	}
 
-		writeResult(t, testName, p_val, isRandom, &pass, &fail)
+	lags, err := parseLags(*lagList)
+	if err != nil {
+		fmt.Printf("Error (autocorrelation test): %v\n", err)
+		os.Exit(1)
 	}
 
-	if *allTests || *runs {
-		testName := "Runs Test"
-		p_val, isRandom, err := nist.Runs(bs)
+	var template []uint8
+	if *templateB != "all" {
+		template, err = parseTemplate(*templateB)
 		if err != nil {
-			fmt.Printf("Error: %v\n", err)
+			fmt.Printf("Error (template test): %v\n", err)
 			os.Exit(1)
 		}
-
-		writeResult(t, testName, p_val, isRandom, &pass, &fail)
+	}
+	overlapping, err := parseTemplate(*overlappingB)
+	if err != nil {
+		fmt.Printf("Error (overlapping template test): %v\n", err)
+		os.Exit(1)
+	}
+	opts := nist.Options{
+		BlockFrequencyM:     *blockFrequencyBlockSize,
+		Template:            template,
+		TemplateM:           *blockSize,
+		OverlappingTemplate: overlapping,
+		OverlappingM:        *overlappingBlockSize,
+		LinearComplexityM:   *inputSize,
+		SerialM:             *serialBlockSize,
+		ApproximateEntropyM: *approximateEntropyBlockSize,
+		CusumMode:           *mode,
 	}
 
-	if *allTests || *longestRun {
-		testName := "Test for the Longest Run of Ones in a Block"
-		p_val, isRandom, err := nist.LongestRunOfOnes(bs)
+	tests := make([]nist.Test, 0, len(ids))
+	for _, id := range ids {
+		test, err := newTest(id, opts, lags)
 		if err != nil {
-			fmt.Printf("Error: %v\n", err)
+			fmt.Printf("Error (%s): %v\n", id, err)
 			os.Exit(1)
 		}
+		tests = append(tests, test)
+	}
 
-		writeResult(t, testName, p_val, isRandom, &pass, &fail)
+	if *streamInput && *streams > 0 {
+		fmt.Println("Error: -stream cannot be combined with -streams")
+		os.Exit(1)
 	}
 
-	if *allTests || *rank {
-		testName := "Binary Matrix Rank Test"
-		p_val, isRandom, err := nist.Rank(bs)
-		if err != nil {
-			fmt.Printf("Error: %v\n", err)
+	// the input is either a file or the output of a generator
+	source := *filename
+	var g generator.Generator
+	if *gen != "" {
+		if *genBits <= 0 {
+			fmt.Printf("Error: -bits must be positive, got %d\n", *genBits)
 			os.Exit(1)
 		}
-
-		writeResult(t, testName, p_val, isRandom, &pass, &fail)
-	}
-
-	if *allTests || *dft {
-		testName := "Discrete Fourier Transform (Spectral) Test"
-		p_val, isRandom, err := nist.DFT(bs)
+		g, source, err = seededGenerator(*gen, *seedHex)
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 			os.Exit(1)
 		}
-
-		writeResult(t, testName, p_val, isRandom, &pass, &fail)
 	}
 
-	if *allTests || *nonOverlappingTemplate {
-		testName := "Non-overlapping Template Matching Test"
-		if *templateB == "" {
-			fmt.Println("Error (non-overlapping template test): template B is required for Non-overlapping Template Matching Test.\nUse -template \"001\" (or other tmeplate)")
-			os.Exit(1)
-		}
-		if *blockSize == 0 {
-			fmt.Println("Error (non-overlapping template test): block size is required for Non-overlapping Template Matching Test.\nUse -block-size 10 (or other block size)")
-			os.Exit(1)
-		}
-		B := make([]uint8, len(*templateB))
-		for i, c := range *templateB {
-			switch c {
-			case '0':
-				B[i] = 0
-			case '1':
-				B[i] = 1
-			default:
-				fmt.Printf("Error (non-overlapping template test): invalid character in template B: %c\n", c)
+	var rep *report.Report
+	if *streamInput {
+		var src *stream.StreamReader
+		if g != nil {
+			if *genBits%8 != 0 {
+				fmt.Println("Error: -stream with -gen needs a multiple of 8 bits")
 				os.Exit(1)
 			}
+			src, err = stream.NewStreamReader(io.LimitReader(g, int64(*genBits/8)), stream.FormatRaw)
+		} else {
+			src, err = openStream(*filename, inFormat, intOpts)
+		}
+		if err == nil {
+			rep, err = streamTests(source, src, ids, tests)
 		}
-		p_value, isRandom, err := nist.NonOverlappingTemplateMatching(B, *blockSize, bs)
 		if err != nil {
-			fmt.Printf("Error (non-overlapping template test): %v\n", err)
+			fmt.Printf("Error: %v\n", err)
 			os.Exit(1)
 		}
-
-		writeResult(t, testName, p_value, isRandom, &pass, &fail)
-	}
-
-	if *allTests || *overlappingTemplate {
-		testName := "Overlapping Template Matching Test"
-		if *templateB == "" {
-			fmt.Println("Error (overlapping templelate test): template B is required for Non-overlapping Template Matching Test.\nUse -template \"001\" (or other tmeplate)")
-			os.Exit(1)
+	} else {
+		var bs *stream.BitStream
+
+		// regulation of the bitstream
+		// ????
+		switch {
+		case g != nil:
+			bs, err = generator.ReadBits(g, *genBits)
+		case inFormat != stream.FormatDecimal:
+			bs, err = stream.FromFileFormat(*filename, inFormat)
+		case *frequency && intOpts == stream.DefaultIntegerOptions():
+			bs, err = stream.FromFileWithLimit(*filename, 100)
+		default:
+			bs, err = stream.FromFileIntegers(*filename, intOpts)
 		}
-		if *blockSize == 0 {
-			fmt.Println("Error (overlapping templelate test): block size is required for Non-overlapping Template Matching Test.\nUse -block-size 10 (or other block size)")
+
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
 			os.Exit(1)
 		}
-		B := make([]uint8, len(*templateB))
-		for i, c := range *templateB {
-			switch c {
-			case '0':
-				B[i] = 0
-			case '1':
-				B[i] = 1
-			default:
-				fmt.Printf("Error (overlapping templelate test): invalid character in template B: %c\n", c)
+
+		if *streams > 0 {
+			n := *bitstreamLength
+			if n == 0 {
+				n = bs.Len() / *streams
+			}
+			seqs, err := bs.Split(n, *streams)
+			if err != nil {
+				fmt.Printf("Error: cannot split %d bits into %d sequences of %d bits: %v\n", bs.Len(), *streams, n, err)
 				os.Exit(1)
 			}
+
+			var assessments []*nist.Assessment
+			rep, assessments = assessStreams(source, ids, tests, seqs, n)
+			if *reportFile != "" {
+				if err := writeFinalAnalysis(*reportFile, source, assessments); err != nil {
+					fmt.Printf("Error: %v\n", err)
+					os.Exit(1)
+				}
+			}
+		} else {
+			rep = runTests(source, ids, tests, bs)
 		}
-		p_value, isRandom, err := nist.OverlappingTemplateMatching(B, *blockSize, bs)
-		if err != nil {
-			fmt.Printf("Error (overlapping templelate test): %v\n", err)
-			os.Exit(1)
-		}
+	}
 
-		writeResult(t, testName, p_value, isRandom, &pass, &fail)
+	if err := writeReport(os.Stdout, *format, rep); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
 	}
 
-	if *universal {
-		testName := "Maurer's Universal Statistical Test"
-		p_val, isRandom, err := nist.UniversalRecommendedValues(bs)
-		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-			os.Exit(1)
+	if _, _, _, errored := rep.Counts(); errored > 0 {
+		os.Exit(1)
+	}
+}
+
+// appendIDs appends the ids of a comma-separated list to ids, skipping those already present.
+func appendIDs(ids []string, list string) []string {
+	for _, id := range strings.Split(list, ",") {
+		if id = strings.TrimSpace(id); id != "" && !slices.Contains(ids, id) {
+			ids = append(ids, id)
 		}
+	}
+	return ids
+}
 
-		writeResult(t, testName, p_val, isRandom, &pass, &fail)
+// writeReport writes the report in the given format: table, json, csv or junit.
+func writeReport(w io.Writer, format string, rep *report.Report) error {
+	switch format {
+	case "json":
+		return report.WriteJSON(w, rep)
+	case "csv":
+		return report.WriteCSV(w, rep)
+	case "junit":
+		return report.WriteJUnit(w, rep)
 	}
+	return report.WriteTable(w, rep)
+}
 
-	if *allTests || *linearComplexity {
-		testName := "Linear Complexity Test"
-		if *inputSize < 500 || *inputSize > 5000 {
-			fmt.Println("Error: input size must be between 500 and 5000")
-			os.Exit(1)
+// newTest builds the test of the given id: a test of SP 800-22 from the DefaultRegistry, or the
+// Autocorrelation Test with the given lags.
+func newTest(id string, opts nist.Options, lags []int) (nist.Test, error) {
+	if id == "autocorrelation" {
+		return nist.NewAutocorrelationTest(lags), nil
+	}
+	return nist.New(id, opts)
+}
+
+// runTests runs every test on the bitstream. Tests needing more bits than the
+// bitstream holds are reported as skipped.
+func runTests(source string, ids []string, tests []nist.Test, bs *stream.BitStream) *report.Report {
+	rep := report.New(source, bs.Len(), 1)
+	for i, test := range tests {
+		if bs.Len() < test.MinLength() {
+			rep.AddSkipped(ids[i], test, fmt.Sprintf("needs %d bits, got %d", test.MinLength(), bs.Len()))
+			continue
 		}
-		p_val, isRandom, err := nist.LinearComplexity(*inputSize, bs)
+
+		res, err := test.Run(bs)
 		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-			os.Exit(1)
+			rep.AddError(ids[i], test, err)
+			continue
 		}
+		rep.AddResult(ids[i], test, res)
+	}
+	return rep
+}
 
-		writeResult(t, testName, p_val, isRandom, &pass, &fail)
+// openStream opens a file to be read in a single pass. The file is closed when the process exits.
+func openStream(filename string, format stream.Format, intOpts stream.IntegerOptions) (*stream.StreamReader, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
 	}
+	if format == stream.FormatDecimal {
+		return stream.NewIntegerStreamReader(file, intOpts)
+	}
+	return stream.NewStreamReader(file, format)
+}
 
-	if *allTests || *serial {
-		testName := "Serial Test"
-		p_val, isRandom, err := nist.Serial(*serialBlockSize, bs)
-		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-			os.Exit(1)
+// streamTests runs every test that can be computed incrementally in a single pass over
+// src, without loading it in memory. The other tests are reported as skipped.
+func streamTests(source string, src stream.BitSource, ids []string, tests []nist.Test) (*report.Report, error) {
+	accs := make([]nist.Accumulator, len(tests))
+	var running []nist.Accumulator
+	for i, test := range tests {
+		if st, ok := test.(nist.StreamTest); ok {
+			accs[i] = st.NewAccumulator()
+			running = append(running, accs[i])
 		}
+	}
+	n, err := nist.Accumulate(src, running...)
+	if err != nil {
+		return nil, err
+	}
 
-		if isRandom != nil {
-			pass++
-			t.AppendRow([]interface{}{testName, fmt.Sprintf("%.2f", p_val), "Pass"})
-		} else {
-			fail++
-			t.AppendRow([]interface{}{testName, fmt.Sprintf("%.2f", p_val), "Fail"})
+	rep := report.New(source, n, 1)
+	for i, test := range tests {
+		switch {
+		case accs[i] == nil:
+			rep.AddSkipped(ids[i], test, "not available when streaming")
+		case n < test.MinLength():
+			rep.AddSkipped(ids[i], test, fmt.Sprintf("needs %d bits, got %d", test.MinLength(), n))
+		default:
+			res, err := accs[i].Result()
+			if err != nil {
+				rep.AddError(ids[i], test, err)
+				continue
+			}
+			rep.AddResult(ids[i], test, res)
 		}
 	}
+	return rep, nil
+}
 
-	if *allTests || *approximateEntropy {
-		testName := "Approximate Entropy Test"
-		p_val, isRandom, err := nist.ApproximateEntropy(*approximateEntropyBlockSize, bs)
-		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-			os.Exit(1)
+// assessStreams runs every test on each sequence and computes the proportion of passing
+// sequences and the uniformity of p-values (SP 800-22 section 4.2) of each sub-test.
+// It returns the report and the assessments of every test that could be run.
+func assessStreams(source string, ids []string, tests []nist.Test, seqs []*stream.BitStream, n int) (*report.Report, []*nist.Assessment) {
+	rep := report.New(source, n, len(seqs))
+	var all []*nist.Assessment
+
+	for i, test := range tests {
+		if n < test.MinLength() {
+			rep.AddSkipped(ids[i], test, fmt.Sprintf("needs %d bits, got %d", test.MinLength(), n))
+			continue
 		}
 
-		writeResult(t, testName, p_val, isRandom, &pass, &fail)
-	}
-
-	if *allTests || *cusum {
-		testName := "Cumulative Sums Test"
-		p_val, isRandom, err := nist.CumulativeSums(*mode, bs)
+		assessments, err := nist.Assess(test, seqs)
 		if err != nil {
-			fmt.Printf("Error: %v\n", err)
+			rep.AddError(ids[i], test, err)
+			continue
 		}
+		rep.AddAssessments(ids[i], test, assessments)
+		all = append(all, assessments...)
+	}
+	return rep, all
+}
+
+// writeFinalAnalysis writes the assessments to a finalAnalysisReport.txt compatible file.
+func writeFinalAnalysis(filename, generator string, assessments []*nist.Assessment) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
 
-		writeResult(t, testName, p_val, isRandom, &pass, &fail)
+	if err := report.WriteFinalAnalysis(file, generator, assessments); err != nil {
+		return err
 	}
+	return file.Close()
+}
 
-	if *allTests || *randomExcursions {
-		testName := "Random Excursions Test"
-		p_val, isRandom, err := nist.RandomExcursions(bs)
+// listTests writes the id, section and name of every test, built by newTest.
+func listTests(w io.Writer, ids []string, newTest func(id string) (nist.Test, error)) {
+	for _, id := range ids {
+		test, err := newTest(id)
 		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-			os.Exit(1)
+			fmt.Fprintf(w, "%-28s %s\n", id, err)
+			continue
 		}
+		fmt.Fprintf(w, "%-28s %-6s %s\n", id, test.Section(), test.Name())
+	}
+}
 
-		if isRandom != nil {
-			pass++
-			t.AppendRow([]interface{}{testName, fmt.Sprintf("%.2f", p_val), "Pass"})
-		} else {
-			fail++
-			t.AppendRow([]interface{}{testName, fmt.Sprintf("%.2f", p_val), "Fail"})
+// parseTemplate converts a string of ones and zeros (e.g. "001") into a template.
+func parseTemplate(s string) ([]uint8, error) {
+	B := make([]uint8, len(s))
+	for i, c := range s {
+		switch c {
+		case '0':
+			B[i] = 0
+		case '1':
+			B[i] = 1
+		default:
+			return nil, fmt.Errorf("invalid character in template B: %c", c)
 		}
 	}
+	return B, nil
+}
 
-	if *allTests || *randomExcursionsVariant {
-		testName := "Random Excursions Variant Test"
-		p_val, isRandom, err := nist.RandomExcursionsVariant(bs)
+// parseLags converts a comma-separated list of lags and ranges of lags (e.g. "1-32,64") into lags.
+func parseLags(s string) ([]int, error) {
+	var lags []int
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		first, last, isRange := strings.Cut(field, "-")
+		from, err := strconv.Atoi(first)
 		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-			os.Exit(1)
+			return nil, fmt.Errorf("invalid lag %q", field)
 		}
-
-		if isRandom != nil {
-			pass++
-			t.AppendRow([]interface{}{testName, fmt.Sprintf("%.2f", p_val), "Pass"})
-		} else {
-			fail++
-			t.AppendRow([]interface{}{testName, fmt.Sprintf("%.2f", p_val), "Fail"})
+		to := from
+		if isRange {
+			if to, err = strconv.Atoi(last); err != nil {
+				return nil, fmt.Errorf("invalid range of lags %q", field)
+			}
+		}
+		if from < 1 || to < from {
+			return nil, fmt.Errorf("invalid lags %q: lags must be positive and ranges increasing", field)
+		}
+		for d := from; d <= to; d++ {
+			lags = append(lags, d)
 		}
 	}
-
-	t.AppendFooter(table.Row{"", "Total Tests", pass + fail})
-	t.AppendFooter(table.Row{"", "Pass", pass})
-	t.AppendFooter(table.Row{"", "Fail", fail})
-	t.Render()
-}
-
-// writeResult writes the result of a test to the table
-func writeResult(t table.Writer, testName string, pValue float64, isRandom bool, pass *int, fail *int) {
-	result := "Fail"
-	if isRandom {
-		result = "Pass"
-		*pass += 1
-	} else {
-		*fail += 1
+	if len(lags) == 0 {
+		return nil, fmt.Errorf("no lag in %q", s)
 	}
-	t.AppendRow([]interface{}{testName, fmt.Sprintf("%.2f", pValue), result})
+	return lags, nil
 }
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMain runs the command instead of the tests when the test binary is started by runCLI.
+func TestMain(m *testing.M) {
+	if os.Getenv("DRBG_RUN_MAIN") == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runCLI runs the command with args in a child process and returns its standard output
+// and exit code.
+func runCLI(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), "DRBG_RUN_MAIN=1")
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		return stdout.String(), exitErr.ExitCode()
+	case err != nil:
+		t.Fatal(err)
+	}
+	return stdout.String(), 0
+}
+
+func TestRandomExcursionsNotApplicable(t *testing.T) {
+	// the random walk of a sequence of ones never returns to zero
+	filename := filepath.Join(t.TempDir(), "ones.bin")
+	if err := os.WriteFile(filename, bytes.Repeat([]byte{0xff}, 250000), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{
+		{"-file", filename, "-input-format", "raw"},
+		{"-file", filename, "-input-format", "raw", "-streams", "2"},
+	} {
+		args = append(args, "-tests", "frequency,random-excursions,random-excursions-variant", "-format", "csv")
+		out, code := runCLI(t, args...)
+		if code != 0 {
+			t.Errorf("%v: exit code %d, expected 0:\n%s", args, code, out)
+		}
+
+		for _, id := range []string{"random-excursions", "random-excursions-variant"} {
+			found := false
+			for _, line := range strings.Split(out, "\n") {
+				if !strings.HasPrefix(line, id+",") {
+					continue
+				}
+				found = true
+				if !strings.Contains(line, ",skipped,") || !strings.Contains(line, "insufficient number of cycles") {
+					t.Errorf("%v: %s not skipped for too few cycles: %s", args, id, line)
+				}
+			}
+			if !found {
+				t.Errorf("%v: no row for %s:\n%s", args, id, out)
+			}
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package nist
+
+import (
+	"errors"
+	"math"
+
+	b "github.com/notJoon/drbg/bitstream"
+)
+
+// ref: A Statistical Test Suite for Random and Pseudorandom Number Generators for Cryptographic Application
+// Section 4.2 Interpretation of Empirical Results (p. 84)
+
+const (
+	// UniformityAlpha is the significance level of the uniformity of p-values (P-value_T).
+	UniformityAlpha = 0.0001
+	// MinUniformitySequences is the smallest number of sequences for which
+	// the uniformity of p-values is assessed.
+	MinUniformitySequences = 55
+)
+
+var ErrNoSequences = errors.New("no sequences to assess")
+
+// Assessment holds the second-level statistics of a (sub-)test run over many sequences.
+type Assessment struct {
+	Name      string    // name of the test
+	Label     string    // label of the sub-test, empty for single-valued tests
+	PValues   []float64 // p-value obtained on each sequence
+	Histogram [10]int   // number of p-values falling in each of the intervals [0, 0.1), ..., [0.9, 1]
+	Passes    int       // number of sequences with a p-value >= Alpha
+
+	Proportion    float64 // proportion of passing sequences
+	ProportionMin float64 // lower bound of the acceptable proportion
+	ProportionMax float64 // upper bound of the acceptable proportion
+	Uniformity    float64 // P-value_T of the uniformity of the p-values
+}
+
+// Sequences returns the number of sequences the sub-test was computed on.
+func (a *Assessment) Sequences() int {
+	return len(a.PValues)
+}
+
+// ProportionOK reports whether the proportion of passing sequences lies within
+// the confidence interval p̂ ± 3 * sqrt(p̂(1-p̂)/m), where p̂ = 1 - Alpha.
+func (a *Assessment) ProportionOK() bool {
+	return a.Proportion >= a.ProportionMin && a.Proportion <= a.ProportionMax
+}
+
+// UniformityApplicable reports whether enough sequences were tested to assess
+// the uniformity of the p-values.
+func (a *Assessment) UniformityApplicable() bool {
+	return a.Sequences() >= MinUniformitySequences
+}
+
+// UniformityOK reports whether the p-values are uniformly distributed (P-value_T >= UniformityAlpha).
+// It returns true when too few sequences were tested for the uniformity to be assessed.
+func (a *Assessment) UniformityOK() bool {
+	return !a.UniformityApplicable() || a.Uniformity >= UniformityAlpha
+}
+
+// Passed reports whether the sub-test passed both the proportion and the uniformity checks.
+func (a *Assessment) Passed() bool {
+	return a.ProportionOK() && a.UniformityOK()
+}
+
+// Assess runs the test on every sequence and computes, for each sub-test, the proportion
+// of sequences that pass and the uniformity of the distribution of p-values.
+//
+// Sequences on which the test cannot be applied (Run returns an error) are left out of the
+// assessment. An error is returned only if the test could not be applied to any sequence.
+func Assess(test Test, seqs []*b.BitStream) ([]*Assessment, error) {
+	if len(seqs) == 0 {
+		return nil, ErrNoSequences
+	}
+
+	var (
+		results  []*Result
+		firstErr error
+	)
+	for _, seq := range seqs {
+		res, err := test.Run(seq)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		results = append(results, res)
+	}
+	if len(results) == 0 {
+		return nil, firstErr
+	}
+
+	return AssessResults(test.Name(), results), nil
+}
+
+// AssessResults computes the second-level statistics of results obtained by running
+// the same test on different sequences. Sub-tests are matched by their label.
+func AssessResults(name string, results []*Result) []*Assessment {
+	var assessments []*Assessment
+	byLabel := make(map[string]*Assessment)
+
+	for _, res := range results {
+		for i, p := range res.PValues {
+			label := ""
+			if i < len(res.Labels) {
+				label = res.Labels[i]
+			}
+			a, ok := byLabel[label]
+			if !ok {
+				a = &Assessment{Name: name, Label: label}
+				byLabel[label] = a
+				assessments = append(assessments, a)
+			}
+			a.PValues = append(a.PValues, p)
+		}
+	}
+
+	for _, a := range assessments {
+		a.compute()
+	}
+	return assessments
+}
+
+// compute fills in the histogram, proportion and uniformity from the p-values.
+func (a *Assessment) compute() {
+	m := float64(len(a.PValues))
+	for _, p := range a.PValues {
+		a.Histogram[pValueBin(p)]++
+		if p >= Alpha {
+			a.Passes++
+		}
+	}
+
+	// (4.2.1) proportion of sequences passing a test
+	pHat := 1 - Alpha
+	delta := 3 * math.Sqrt(pHat*(1-pHat)/m)
+	a.Proportion = float64(a.Passes) / m
+	a.ProportionMin = pHat - delta
+	a.ProportionMax = pHat + delta
+
+	// (4.2.2) uniform distribution of p-values
+	a.Uniformity = uniformity(a.Histogram[:], m)
+}
+
+// pValueBin returns the index of the interval of width 0.1 the p-value falls in.
+func pValueBin(p float64) int {
+	bin := int(p * 10)
+	if bin > 9 {
+		bin = 9
+	}
+	if bin < 0 {
+		bin = 0
+	}
+	return bin
+}
+
+// uniformity computes P-value_T = igamc(9/2, χ²/2) where
+// χ² = Σ (F_i - s/10)² / (s/10) over the 10 intervals.
+func uniformity(histogram []int, s float64) float64 {
+	expected := s / float64(len(histogram))
+	chi2 := 0.0
+	for _, f := range histogram {
+		diff := float64(f) - expected
+		chi2 += diff * diff / expected
+	}
+	return igamc(float64(len(histogram)-1)/2.0, chi2/2.0)
+}
@@ -0,0 +1,71 @@
+package nist
+
+import (
+	"testing"
+
+	b "github.com/notJoon/drbg/bitstream"
+)
+
+func TestAssessResults(t *testing.T) {
+	// 1000 p-values spread evenly over the 10 intervals, 10 of them failing.
+	results := make([]*Result, 1000)
+	for i := range results {
+		p := (float64(i) + 0.5) / 1000
+		results[i] = &Result{Name: "Serial Test", PValues: []float64{p, 0.5}, Labels: []string{"a", "b"}}
+	}
+
+	assessments := AssessResults("Serial Test", results)
+	if len(assessments) != 2 {
+		t.Fatalf("expected 2 assessments, got %d", len(assessments))
+	}
+
+	a := assessments[0]
+	if a.Label != "a" || a.Sequences() != 1000 {
+		t.Errorf("got label %q over %d sequences", a.Label, a.Sequences())
+	}
+	for i, count := range a.Histogram {
+		if count != 100 {
+			t.Errorf("Histogram[%d] = %d, expected 100", i, count)
+		}
+	}
+	if a.Passes != 990 {
+		t.Errorf("Passes = %d, expected 990", a.Passes)
+	}
+	// Section 4.2.1: for m = 1000 the confidence interval is 0.99 ± 0.0094392
+	if !almostEq(a.ProportionMin, 0.9805608, 1e-6) || !almostEq(a.ProportionMax, 0.9994392, 1e-6) {
+		t.Errorf("confidence interval = [%v, %v]", a.ProportionMin, a.ProportionMax)
+	}
+	if !almostEq(a.Uniformity, 1, 1e-9) || !a.Passed() {
+		t.Errorf("Uniformity = %v, Passed = %v", a.Uniformity, a.Passed())
+	}
+
+	// every p-value of the second sub-test falls in the same interval
+	c := assessments[1]
+	if c.Histogram[5] != 1000 || c.UniformityOK() || c.Passed() {
+		t.Errorf("Histogram = %v, Uniformity = %v", c.Histogram, c.Uniformity)
+	}
+}
+
+func TestAssess(t *testing.T) {
+	bs := b.NewBitStream([]byte{0xAA, 0xAA, 0xAA, 0xAA, 0x00, 0x00, 0x00, 0x00})
+	seqs, err := bs.Split(32, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assessments, err := Assess(NewFrequencyTest(), seqs)
+	if err != nil {
+		t.Fatalf("Assess() error = %v", err)
+	}
+	a := assessments[0]
+	if a.Sequences() != 2 || a.Passes != 1 || a.Proportion != 0.5 || a.ProportionOK() {
+		t.Errorf("got %d sequences, %d passes, proportion %v", a.Sequences(), a.Passes, a.Proportion)
+	}
+	if a.UniformityApplicable() {
+		t.Errorf("uniformity should not be applicable to %d sequences", a.Sequences())
+	}
+
+	if _, err := Assess(NewFrequencyTest(), nil); err != ErrNoSequences {
+		t.Errorf("Assess() error = %v, expected %v", err, ErrNoSequences)
+	}
+}
@@ -1,66 +1,89 @@
 package nist
 
 import (
-	"bytes"
+	"fmt"
 	"math"
 
 	b "github.com/notJoon/drbg/bitstream"
 )
 
 func ApproximateEntropy(m uint64, bs *b.BitStream) (float64, bool, error) {
-	n := uint64(bs.Len())
-	var psi [2]float64
+	res, err := NewApproximateEntropyTest(m).Run(bs)
+	if err != nil {
+		return 0, false, err
+	}
+	return res.PValue(), res.Passed(), nil
+}
 
-	for indexPsi := range psi {
-		appendedEpsilon := make([]uint8, n+m-1)
-		for i := uint64(0); i < n; i++ {
-			bit, err := bs.Bit(int(i))
-			if err != nil {
-				return 0, false, err
-			}
-			appendedEpsilon[i] = bit
-		}
+type approximateEntropyTest struct {
+	m uint64 // the length of each block
+}
 
-		for j := uint64(0); j < m-1; j++ {
-			bit, err := bs.Bit(int(j))
-			if err != nil {
-				return 0, false, err
-			}
-			appendedEpsilon[n+j] = bit
-		}
+// NewApproximateEntropyTest returns the Approximate Entropy Test as a Test,
+// using overlapping blocks of m bits.
+func NewApproximateEntropyTest(m uint64) Test { return approximateEntropyTest{m: m} }
 
-		two_raise_power_to_m := 1 << m
-		tempLastIndex := n - m + 1
+func (approximateEntropyTest) Name() string    { return "Approximate Entropy Test" }
+func (approximateEntropyTest) Section() string { return "2.12" }
+func (t approximateEntropyTest) Params() map[string]any {
+	return map[string]any{"m": t.m}
+}
 
-		C := make([]float64, two_raise_power_to_m)
-		possible_m_bits_blocks := make([][]uint8, two_raise_power_to_m)
+// MinLength returns the smallest n satisfying m < floor(log2 n) - 5.
+func (t approximateEntropyTest) MinLength() int { return 1 << (t.m + 6) }
 
-		for bit := range possible_m_bits_blocks {
-			possible_m_bits_blocks[bit] = Uint_To_BitsArray_size_N(uint64(bit), m)
-			for index := uint64(0); index < tempLastIndex; index++ {
-				if bytes.Equal(possible_m_bits_blocks[bit], appendedEpsilon[index:index+m]) {
-					C[bit]++
-				}
-			}
-		}
+func (t approximateEntropyTest) Run(bs *b.BitStream) (*Result, error) {
+	return accumulate(t.NewAccumulator(), bs)
+}
 
-		for indexC := range C {
-			C[indexC] /= float64(n)
-		}
+func (t approximateEntropyTest) NewAccumulator() Accumulator {
+	if t.m > 31 {
+		return &approximateEntropyAccumulator{t: t}
+	}
+	return &approximateEntropyAccumulator{t: t, counter: newPatternCounter(t.m + 1)}
+}
+
+type approximateEntropyAccumulator struct {
+	t       approximateEntropyTest
+	counter *patternCounter // counts the (m+1)-bit patterns, nil if m is too large
+}
+
+func (a *approximateEntropyAccumulator) AddBit(bit byte) {
+	if a.counter != nil {
+		a.counter.add(bit)
+	}
+}
+
+func (a *approximateEntropyAccumulator) Result() (*Result, error) {
+	t, m := a.t, a.t.m
+	if m == 0 || a.counter == nil {
+		return nil, fmt.Errorf("invalid block size. got %d, should be between 1 and 31", m)
+	}
+	n := a.counter.n
+	if n == 0 {
+		return nil, ErrEmptyBitStream
+	}
+	if m >= n {
+		return nil, fmt.Errorf("invalid block size. got %d, should be between 1 and %d", m, n-1)
+	}
 
+	// the frequencies of the overlapping (m+1)-bit blocks, then of the m-bit blocks
+	counts := a.counter.circularCounts()
+	var psi [2]float64
+	for indexPsi := len(psi) - 1; indexPsi >= 0; indexPsi-- {
 		sum := 0.0
-		for _, value := range C {
-			if value > 0 {
-				sum += value * math.Log(value)
+		for _, count := range counts {
+			if count > 0 {
+				C := float64(count) / float64(n)
+				sum += C * math.Log(C)
 			}
 		}
 		psi[indexPsi] = sum
-		m++
+		counts = foldCounts(counts)
 	}
-	m--
 
 	chi2 := 2 * float64(n) * (math.Log(2) - (psi[0] - psi[1]))
 	p_val := igamc(math.Pow(2.0, float64(m-1)), chi2/2)
 
-	return p_val, p_val > 0.01, nil
+	return singleResult(t, int(n), p_val, chi2, nil), nil
 }
@@ -0,0 +1,188 @@
+package nist
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	mathbits "math/bits"
+	"slices"
+
+	b "github.com/notJoon/drbg/bitstream"
+)
+
+const (
+	// AutocorrelationLags is the largest lag tested when no lags are given: the lags 1 to 32.
+	AutocorrelationLags = 32
+	// AutocorrelationTop is the number of lags whose counts are reported, those of the strongest
+	// deviations.
+	AutocorrelationTop = 5
+)
+
+// Autocorrelation performs the autocorrelation test on the given bitstream for the given lags,
+// or for the lags 1 to 32 if none are given.
+//
+// For a lag d, the number of differences between the sequence and itself shifted by d bits is
+//
+//	A(d) = sum from i=0 to n-d-1 of (x_i XOR x_i+d)
+//
+// and its normalised form
+//
+//	Z(d) = (2A(d) - (n-d)) / sqrt(n-d)
+//
+// is approximately standard normal for a random sequence. A correlation at a single lag, such as
+// the period of a ring oscillator, stands out in Z(d) while the DFT test averages it away among
+// the other frequencies. As every lag is a separate test, the p-values are adjusted by Holm's
+// step-down method, so that the probability that a random sequence fails at any lag stays below
+// the significance level.
+//
+// Returns the deviation at every lag, the strongest first, and a boolean indicating whether
+// every adjusted p-value is at least 0.01.
+func Autocorrelation(lags []int, bs *b.BitStream) ([]LagDeviation, bool, error) {
+	t := NewAutocorrelationTest(lags).(autocorrelationTest)
+	deviations, err := t.deviations(bs)
+	if err != nil {
+		return nil, false, err
+	}
+	return deviations, deviations[0].PValue >= Alpha, nil
+}
+
+// LagDeviation holds the deviation of the autocorrelation at a lag.
+type LagDeviation struct {
+	Lag    int     // the lag d
+	Count  int     // A(d), the number of bits differing from the bit d positions later
+	Z      float64 // the normalised statistic Z(d)
+	PValue float64 // the p-value of Z(d), adjusted for the number of lags tested
+}
+
+type autocorrelationTest struct {
+	lags []int // the lags tested, in increasing order
+}
+
+// NewAutocorrelationTest returns the autocorrelation test as a Test, for the given lags or the
+// lags 1 to 32 if none are given. The test is not part of SP 800-22 and has no section.
+func NewAutocorrelationTest(lags []int) Test {
+	if len(lags) == 0 {
+		lags = make([]int, AutocorrelationLags)
+		for i := range lags {
+			lags[i] = i + 1
+		}
+	}
+	lags = slices.Clone(lags)
+	slices.Sort(lags)
+	return autocorrelationTest{lags: slices.Compact(lags)}
+}
+
+func (autocorrelationTest) Name() string    { return "Autocorrelation Test" }
+func (autocorrelationTest) Section() string { return "" }
+func (t autocorrelationTest) Params() map[string]any {
+	return map[string]any{"lags": len(t.lags), "d_min": t.lags[0], "d_max": t.lags[len(t.lags)-1]}
+}
+
+// MinLength returns the length leaving 1000 pairs of bits at the largest lag.
+func (t autocorrelationTest) MinLength() int { return t.lags[len(t.lags)-1] + 1000 }
+
+// Run reports a single sub-test, the smallest adjusted p-value over all the lags, under the same
+// label for every sequence so that the results of many sequences can be assessed together. The
+// strongest lag and the counts A(d) of the lags with the strongest deviations are in Counts.
+func (t autocorrelationTest) Run(bs *b.BitStream) (*Result, error) {
+	deviations, err := t.deviations(bs)
+	if err != nil {
+		return nil, err
+	}
+
+	strongest := deviations[0]
+	res := &Result{
+		Name:       t.Name(),
+		N:          bs.Len(),
+		PValues:    []float64{strongest.PValue},
+		Labels:     []string{"min adjusted p-value"},
+		Statistics: []float64{strongest.Z},
+		Counts:     map[string]int64{"lags": int64(len(deviations)), "strongest lag": int64(strongest.Lag)},
+	}
+	for _, dev := range deviations[:min(AutocorrelationTop, len(deviations))] {
+		res.Counts[fmt.Sprintf("lag %d", dev.Lag)] = int64(dev.Count)
+	}
+	return res, nil
+}
+
+// deviations returns the deviation at every lag, in increasing order of their p-values.
+func (t autocorrelationTest) deviations(bs *b.BitStream) ([]LagDeviation, error) {
+	n := bs.Len()
+	if n == 0 {
+		return nil, ErrEmptyBitStream
+	}
+	if d := t.lags[0]; d < 1 {
+		return nil, fmt.Errorf("lags must be positive, got %d", d)
+	}
+	if d := t.lags[len(t.lags)-1]; d >= n {
+		return nil, fmt.Errorf("input sequence length should be greater than the lag %d, got %d", d, n)
+	}
+
+	words := packBits(bs)
+	deviations := make([]LagDeviation, len(t.lags))
+	pValues := make([]float64, len(t.lags))
+	for i, d := range t.lags {
+		count := xorCount(words, n-d, d)
+		z := (2*float64(count) - float64(n-d)) / math.Sqrt(float64(n-d))
+		pValues[i] = math.Erfc(math.Abs(z) / math.Sqrt2)
+		deviations[i] = LagDeviation{Lag: d, Count: count, Z: z}
+	}
+	for i, p := range holm(pValues) {
+		deviations[i].PValue = p
+	}
+	// the p-values decrease as |Z(d)| increases
+	slices.SortStableFunc(deviations, func(a, b LagDeviation) int {
+		return cmp.Compare(math.Abs(b.Z), math.Abs(a.Z))
+	})
+	return deviations, nil
+}
+
+// holm returns the p-values adjusted by Holm's step-down method: with the m p-values in
+// increasing order p_(1), ..., p_(m), the adjusted p-value of p_(k) is the largest of
+// min(1, (m-j+1) p_(j)) for j <= k.
+func holm(pValues []float64) []float64 {
+	m := len(pValues)
+	order := make([]int, m)
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(i, j int) int { return cmp.Compare(pValues[i], pValues[j]) })
+	adjusted := make([]float64, m)
+	largest := 0.0
+	for j, i := range order {
+		largest = max(largest, min(1, float64(m-j)*pValues[i]))
+		adjusted[i] = largest
+	}
+	return adjusted
+}
+
+// packBits returns the bits of bs packed in 64-bit words, most significant bit first, followed by
+// a zero word.
+func packBits(bs *b.BitStream) []uint64 {
+	data := bs.Bytes()
+	words := make([]uint64, (len(data)+7)/8+1)
+	for i, c := range data {
+		words[i/8] |= uint64(c) << (56 - 8*(i%8))
+	}
+	return words
+}
+
+// xorCount returns the number of the first n bits of the packed words that differ from the bit
+// lag positions later.
+func xorCount(words []uint64, n, lag int) int {
+	count := 0
+	for k := 0; 64*k < n; k++ {
+		pos := 64*k + lag
+		q, r := pos/64, uint(pos%64)
+		shifted := words[q] << r
+		if r != 0 {
+			shifted |= words[q+1] >> (64 - r)
+		}
+		x := words[k] ^ shifted
+		if rest := n - 64*k; rest < 64 {
+			x &= ^uint64(0) << (64 - rest)
+		}
+		count += mathbits.OnesCount64(x)
+	}
+	return count
+}
@@ -0,0 +1,106 @@
+package nist
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	b "github.com/notJoon/drbg/bitstream"
+)
+
+func TestXorCount(t *testing.T) {
+	bs := b.NewBitStream(xorshiftBytes(300))
+	words := packBits(bs)
+	for _, lag := range []int{1, 7, 63, 64, 65, 200, 2000} {
+		n := bs.Len() - lag
+		expected := 0
+		for i := 0; i < n; i++ {
+			x, _ := bs.Bit(i)
+			y, _ := bs.Bit(i + lag)
+			expected += int(x ^ y)
+		}
+		if got := xorCount(words, n, lag); got != expected {
+			t.Errorf("lag %d: A(d) = %d, expected %d", lag, got, expected)
+		}
+	}
+}
+
+func TestHolm(t *testing.T) {
+	got := holm([]float64{0.04, 0.01, 0.03, 0.5})
+	expected := []float64{0.09, 0.04, 0.09, 0.5}
+	for i := range got {
+		if math.Abs(got[i]-expected[i]) > 1e-12 {
+			t.Errorf("adjusted p-values %v, expected %v", got, expected)
+			break
+		}
+	}
+}
+
+func TestAutocorrelation(t *testing.T) {
+	data := make([]byte, 1<<17)
+	rand.New(rand.NewSource(1)).Read(data)
+	lags := make([]int, 64)
+	for i := range lags {
+		lags[i] = i + 1
+	}
+
+	deviations, pass, err := Autocorrelation(lags, b.NewBitStream(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pass || len(deviations) != len(lags) {
+		t.Errorf("random data: pass = %v, %d deviations", pass, len(deviations))
+	}
+
+	// copy each bit to the bit 37 positions later with probability 1/20
+	bs := b.NewBitStream(data)
+	r := rand.New(rand.NewSource(2))
+	for i := 37; i < bs.Len(); i++ {
+		if r.Intn(20) == 0 {
+			bit, _ := bs.Bit(i - 37)
+			bs.SetBit(i, bit)
+		}
+	}
+	deviations, pass, err = Autocorrelation(lags, bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pass || deviations[0].Lag != 37 || deviations[0].Z > -5 {
+		t.Errorf("correlated data: pass = %v, strongest deviation %+v", pass, deviations[0])
+	}
+
+	res, err := NewAutocorrelationTest(lags).Run(bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.PValues) != 1 || res.Labels[0] != "min adjusted p-value" || res.Pass(0) ||
+		res.PValues[0] != deviations[0].PValue || res.Counts["strongest lag"] != 37 ||
+		res.Counts["lag 37"] != int64(deviations[0].Count) {
+		t.Errorf("result %v %v %v", res.Labels, res.PValues, res.Counts)
+	}
+
+	for _, lags := range [][]int{{0, 1}, {bs.Len()}} {
+		if _, _, err := Autocorrelation(lags, bs); err == nil {
+			t.Errorf("no error for the lags %v", lags)
+		}
+	}
+}
+
+func TestAutocorrelationAssess(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	seqs := make([]*b.BitStream, 10)
+	for i := range seqs {
+		data := make([]byte, 1<<12)
+		r.Read(data)
+		seqs[i] = b.NewBitStream(data)
+	}
+
+	// the strongest lags differ between sequences, but the summary sub-test does not
+	assessments, err := Assess(NewAutocorrelationTest(nil), seqs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assessments) != 1 || assessments[0].Label != "min adjusted p-value" || len(assessments[0].PValues) != len(seqs) {
+		t.Fatalf("assessments %+v", assessments)
+	}
+}
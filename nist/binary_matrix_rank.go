@@ -1,6 +1,7 @@
 package nist
 
 import (
+	"fmt"
 	"math"
 
 	b "github.com/notJoon/drbg/bitstream"
@@ -31,6 +32,26 @@ import (
 //   - bool: True if the test passes (p-value >= 0.01), False otherwise.
 //   - error: Any error that occurred during the test, such as invalid input parameters.
 func Rank(bs *b.BitStream) (float64, bool, error) {
+	res, err := NewRankTest().Run(bs)
+	if err != nil {
+		return 0, false, err
+	}
+	return res.PValue(), res.Passed(), nil
+}
+
+type rankTest struct{}
+
+// NewRankTest returns the Binary Matrix Rank Test as a Test.
+func NewRankTest() Test { return rankTest{} }
+
+func (rankTest) Name() string    { return "Binary Matrix Rank Test" }
+func (rankTest) Section() string { return "2.5" }
+func (rankTest) Params() map[string]any {
+	return map[string]any{"M": 32, "Q": 32}
+}
+func (rankTest) MinLength() int { return 38 * 32 * 32 }
+
+func (t rankTest) Run(bs *b.BitStream) (*Result, error) {
 	var (
 		M uint64 = 32 // number of rows in the matrix
 		Q uint64 = 32 // number of columns in the matrix
@@ -39,6 +60,9 @@ func Rank(bs *b.BitStream) (float64, bool, error) {
 	// sequentially divide the sequence into M*Q bit disjoint blocks
 	n := bs.Len()
 	N := uint64(n) / (M * Q)
+	if N == 0 {
+		return nil, fmt.Errorf("input sequence length should be at least %d bits, got %d", M*Q, n)
+	}
 	R := make([]uint64, N)   // TODO: change name to rank
 	F := make([]uint64, M+1) // number of matrices with rank_i = index (index means, rank)
 
@@ -65,18 +89,40 @@ func Rank(bs *b.BitStream) (float64, bool, error) {
 		F[R[i]]++
 	}
 
-	// compute chi-square value
+	// compute chi-square value against the probabilities of a random matrix
+	// to have full rank, rank M-1, and a lower rank
 	var (
+		p_M         = rankProbability(M, M, Q)
+		p_M_minus_1 = rankProbability(M-1, M, Q)
+		p_rest      = 1 - p_M - p_M_minus_1
+
 		__F_M_float64         = float64(F[M])
 		__F_M_minus_1_float64 = float64(F[M-1])
 		__N_float64           = float64(N)
-		chi_square            = (__F_M_float64-0.2888*__N_float64)*(__F_M_float64-0.2888*__N_float64)/(0.2888*__N_float64) + (__F_M_minus_1_float64-0.5776*__N_float64)*(__F_M_minus_1_float64-0.5776*__N_float64)/(0.5776*__N_float64) + (__N_float64-__F_M_float64-__F_M_minus_1_float64-0.1336*__N_float64)*(__N_float64-__F_M_float64-__F_M_minus_1_float64-0.1336*__N_float64)/(0.1336*__N_float64)
+		chi_square            = (__F_M_float64-p_M*__N_float64)*(__F_M_float64-p_M*__N_float64)/(p_M*__N_float64) + (__F_M_minus_1_float64-p_M_minus_1*__N_float64)*(__F_M_minus_1_float64-p_M_minus_1*__N_float64)/(p_M_minus_1*__N_float64) + (__N_float64-__F_M_float64-__F_M_minus_1_float64-p_rest*__N_float64)*(__N_float64-__F_M_float64-__F_M_minus_1_float64-p_rest*__N_float64)/(p_rest*__N_float64)
 	)
 
 	// compute P-value
 	P_value := math.Pow(math.E, -1*chi_square/2)
 
-	return P_value, P_value >= 0.01, nil
+	counts := map[string]int64{
+		"N":     int64(N),
+		"F_M":   int64(F[M]),
+		"F_M-1": int64(F[M-1]),
+	}
+	return singleResult(t, n, P_value, chi_square, counts), nil
+}
+
+// rankProbability returns the probability for a random M x Q binary matrix to have rank r
+// (Section 3.5):
+//
+//	p_r = 2^(r(Q+M-r)-MQ) * Π_{i=0}^{r-1} (1-2^(i-Q))(1-2^(i-M)) / (1-2^(i-r))
+func rankProbability(r, M, Q uint64) float64 {
+	product := 1.0
+	for i := uint64(0); i < r; i++ {
+		product *= (1 - math.Pow(2, float64(i)-float64(Q))) * (1 - math.Pow(2, float64(i)-float64(M))) / (1 - math.Pow(2, float64(i)-float64(r)))
+	}
+	return math.Pow(2, float64(r*(Q+M-r))-float64(M*Q)) * product
 }
 
 func RankComputationOfBinaryMatrices(matrix [][]uint8) uint64 {
@@ -11,27 +11,6 @@ import (
 
 var ErrSequenceTooShort = errors.New("input sequence length should be at least 100 bits")
 
-// piWithBaseI calculates the proportion πi of '1's in each M-bit block of the bitstream.
-// It returns a slice of float64 representing the proportion of '1's for each block
-// and an error if an issue occurs during bit extraction.
-func piWithBaseI(bs *b.BitStream, M, N uint64) ([]float64, error) {
-	var sum uint64
-	result := make([]float64, 0, N)
-
-	for i := uint64(1); i <= N; i++ {
-		sum = 0
-		for j := uint64(0); j < M; j++ {
-			bit, err := bs.Bit(int((i-1)*M + j))
-			if err != nil {
-				return nil, err
-			}
-			sum += uint64(bit)
-		}
-		result = append(result, float64(sum)/float64(M))
-	}
-	return result, nil
-}
-
 // BlockFrequencyTest performs the Frequency Test Within a Block as defined in NIST SP800-22.
 // It takes a BitStream and block size M as input and returns the P-value of the test,
 // a bool representing if the P-value suggests randomness (true if P >= 0.01), and an error if any.
@@ -44,34 +23,73 @@ func piWithBaseI(bs *b.BitStream, M, N uint64) ([]float64, error) {
 //   - bool: True if the test passes (p-value >= 0.01), False otherwise.
 //   - error: Any error that occurred during the test, such as invalid input parameters.
 func BlockFrequencyTest(bs *b.BitStream, M uint64) (float64, bool, error) {
-	n := uint64(bs.Len())
-	if n < 100 {
-		return 0, false, fmt.Errorf("input sequence length should be at least 100 bits, got %d", n)
-	}
-	if M < 20 || M <= n/100 {
-		maxM := n / 100
-		return 0, false, fmt.Errorf("invalid block size. got %d, should be at least 20 and less than %d", M, maxM)
+	res, err := NewBlockFrequencyTest(M).Run(bs)
+	if err != nil {
+		return 0, false, err
 	}
+	return res.PValue(), res.Passed(), nil
+}
 
-	// partition the input sequence into N = floor(n/M) non-overlapping blocks
-	N := n / M
+type blockFrequencyTest struct {
+	M uint64 // the length of each block
+}
 
-	// determine the proportion πi of ones in each M-bit block
-	pi, err := piWithBaseI(bs, M, N)
-	if err != nil {
-		return 0, false, err
+// NewBlockFrequencyTest returns the Frequency Test within a Block as a Test,
+// using blocks of M bits.
+func NewBlockFrequencyTest(M uint64) Test { return blockFrequencyTest{M: M} }
+
+func (blockFrequencyTest) Name() string    { return "Frequency Test within a Block" }
+func (blockFrequencyTest) Section() string { return "2.2" }
+func (t blockFrequencyTest) Params() map[string]any {
+	return map[string]any{"M": t.M}
+}
+func (blockFrequencyTest) MinLength() int { return 100 }
+
+func (t blockFrequencyTest) Run(bs *b.BitStream) (*Result, error) {
+	return accumulate(t.NewAccumulator(), bs)
+}
+
+func (t blockFrequencyTest) NewAccumulator() Accumulator {
+	return &blockFrequencyAccumulator{t: t}
+}
+
+type blockFrequencyAccumulator struct {
+	t       blockFrequencyTest
+	n       uint64
+	ones    uint64  // number of ones in the current block
+	N       uint64  // number of complete blocks
+	tempSum float64 // sum of (πi - 1/2)^2 over the complete blocks
+}
+
+func (a *blockFrequencyAccumulator) AddBit(bit byte) {
+	a.n++
+	a.ones += uint64(bit)
+	if a.t.M > 0 && a.n%a.t.M == 0 {
+		// determine the proportion πi of ones in the M-bit block
+		pi := float64(a.ones) / float64(a.t.M)
+		diff := pi - 0.5
+		a.tempSum += diff * diff
+		a.N++
+		a.ones = 0
 	}
+}
 
-	// compute the test statistic X^2
-	tempSum := 0.0
-	for _, value := range pi {
-		diff := value - 0.5
-		tempSum += diff * diff
+func (a *blockFrequencyAccumulator) Result() (*Result, error) {
+	t, M, n := a.t, a.t.M, a.n
+	// SP 800-22 recommends M >= 20, M > 0.01n and N < 100, but the NIST reference
+	// implementation (and its published results) use M = 128 on any length.
+	if M == 0 || M > n {
+		return nil, fmt.Errorf("invalid block size. got %d, should be between 1 and %d", M, n)
 	}
-	X2 := 4 * float64(M) * tempSum
+
+	// the sequence is partitioned into N = floor(n/M) non-overlapping blocks
+	N := a.N
+
+	// compute the test statistic X^2
+	X2 := 4 * float64(M) * a.tempSum
 
 	// compute the P-value using the incomplete gamma function complement
 	p_value := igamc(float64(N)/2.0, X2/2.0)
 
-	return p_value, p_value >= 0.01, nil
+	return singleResult(t, int(n), p_value, X2, map[string]int64{"N": int64(N)}), nil
 }
@@ -8,56 +8,99 @@ import (
 )
 
 func CumulativeSums(mode int, bs *b.BitStream) (float64, bool, error) {
-	n := uint64(bs.Len())
-
-	if n < 2 {
-		panic(fmt.Sprintf("input length is too short, should be larger than 2. got=%d", n))
+	res, err := NewCumulativeSumsTest(mode).Run(bs)
+	if err != nil {
+		return 0, false, err
 	}
+	return res.PValue(), res.Passed(), nil
+}
 
-	X := make([]int8, n)
-	S := make([]int64, n)
+// Modes of the Cumulative Sums Test.
+const (
+	CusumForward  = 0 // walk the sequence forward
+	CusumBackward = 1 // walk the sequence backward
+	CusumBoth     = 2 // report both directions, as the NIST reference implementation does
+)
 
-	for i := uint64(0); i < n; i++ {
-		bit, err := bs.Bit(int(i))
-		if err != nil {
-			return 0, false, err
-		}
+type cumulativeSumsTest struct {
+	mode int // CusumForward, CusumBackward or CusumBoth
+}
 
-		X[i] = 2*int8(bit) - 1
-	}
+// NewCumulativeSumsTest returns the Cumulative Sums (Cusum) Test as a Test.
+// A mode of CusumForward (0) walks the sequence forward, CusumBackward (1) walks it
+// backward and CusumBoth (2) reports one p-value for each direction.
+func NewCumulativeSumsTest(mode int) Test { return cumulativeSumsTest{mode: mode} }
 
-	switch mode {
-	case 0:
-		_n := int64(n)
-		index_s := int64(0)
+func (cumulativeSumsTest) Name() string    { return "Cumulative Sums Test" }
+func (cumulativeSumsTest) Section() string { return "2.13" }
+func (t cumulativeSumsTest) Params() map[string]any {
+	return map[string]any{"mode": t.mode}
+}
+func (cumulativeSumsTest) MinLength() int { return 100 }
 
-		S[index_s] = int64(X[index_s])
-		for index_s = 1; index_s < _n; index_s++ {
-			S[index_s] = S[index_s-1] + int64(X[index_s])
-		}
-	case 1:
-		index_x := uint64(n - 1)
-		index_s := uint64(0)
-
-		S[index_s] = int64(X[index_x])
-		for index_s = 1; index_s < n; index_s++ {
-			index_x--
-			S[index_s] = S[index_s-1] + int64(X[index_x])
-		}
-	default:
-		panic(fmt.Sprintf("invalid mode: %d", mode))
+func (t cumulativeSumsTest) Run(bs *b.BitStream) (*Result, error) {
+	return accumulate(t.NewAccumulator(), bs)
+}
+
+func (t cumulativeSumsTest) NewAccumulator() Accumulator {
+	return &cumulativeSumsAccumulator{t: t}
+}
+
+// cumulativeSumsAccumulator tracks the partial sums S_k of the sequence (X_i = 2ε_i - 1).
+// The backward partial sums are S_n - S_k, so their maximum excursion only depends
+// on the smallest and largest of S_0, ..., S_{n-1}.
+type cumulativeSumsAccumulator struct {
+	t        cumulativeSumsTest
+	n        uint64
+	S        int64 // the partial sum S_n
+	min, max int64 // the extremes of S_0 = 0, ..., S_{n-1}
+	zForward int64 // max |S_k| for 1 <= k <= n
+}
+
+func (a *cumulativeSumsAccumulator) AddBit(bit byte) {
+	a.min = min(a.min, a.S)
+	a.max = max(a.max, a.S)
+	a.S += 2*int64(bit) - 1
+	a.n++
+	a.zForward = max(a.zForward, a.S, -a.S)
+}
+
+func (a *cumulativeSumsAccumulator) Result() (*Result, error) {
+	t, n := a.t, a.n
+
+	if n < 2 {
+		return nil, fmt.Errorf("input length is too short, should be larger than 2. got=%d", n)
 	}
 
-	z := math.Abs(float64(S[0]))
-	now := 0.0
+	var modes []int
+	switch t.mode {
+	case CusumForward, CusumBackward:
+		modes = []int{t.mode}
+	case CusumBoth:
+		modes = []int{CusumForward, CusumBackward}
+	default:
+		return nil, fmt.Errorf("invalid mode: %d", t.mode)
+	}
 
-	for index := uint64(1); index < n; index++ {
-		now = math.Abs(float64(S[index]))
-		if now > z {
-			z = now
+	res := &Result{Name: t.Name(), N: int(n), Counts: make(map[string]int64)}
+	for _, mode := range modes {
+		z := float64(a.zForward)
+		if mode == CusumBackward {
+			z = float64(max(a.S-a.min, a.max-a.S))
 		}
+		p_value := cumulativeSums(n, z)
+		label := cusumModeLabel(mode)
+		res.PValues = append(res.PValues, p_value)
+		res.Labels = append(res.Labels, label)
+		res.Statistics = append(res.Statistics, z)
+		res.Counts["z ("+label+")"] = int64(z)
 	}
+	return res, nil
+}
 
+// cumulativeSums computes the p-value of the maximum excursion z of the partial sums
+// of a sequence of n bits.
+func cumulativeSums(n uint64, z float64) float64 {
 	n_float64 := float64(n)
 
 	var k int64
@@ -74,10 +117,17 @@ func CumulativeSums(mode int, bs *b.BitStream) (float64, bool, error) {
 	}
 
 	p_value := 1.0 - term1 + term2
-
-	return p_value, p_value >= 0.01, nil
+	return p_value
 }
 
 func cumulativeDistibution(z float64) float64 {
 	return 0.5 * (math.Erf(z/math.Sqrt2) + 1)
 }
+
+// cusumModeLabel names the direction in which a mode walks the sequence.
+func cusumModeLabel(mode int) string {
+	if mode == CusumBackward {
+		return "reverse"
+	}
+	return "forward"
+}
@@ -26,6 +26,24 @@ import (
 //   - bool: True if the test passes (p-value >= 0.01), False otherwise.
 //   - error: Any error that occurred during the test, such as invalid input parameters.
 func DFT(bs *b.BitStream) (float64, bool, error) {
+	res, err := NewDFTTest().Run(bs)
+	if err != nil {
+		return 0, false, err
+	}
+	return res.PValue(), res.Passed(), nil
+}
+
+type dftTest struct{}
+
+// NewDFTTest returns the Discrete Fourier Transform (Spectral) Test as a Test.
+func NewDFTTest() Test { return dftTest{} }
+
+func (dftTest) Name() string           { return "Discrete Fourier Transform (Spectral) Test" }
+func (dftTest) Section() string        { return "2.6" }
+func (dftTest) Params() map[string]any { return nil }
+func (dftTest) MinLength() int         { return 1000 }
+
+func (t dftTest) Run(bs *b.BitStream) (*Result, error) {
 	n := bs.Len()
 	X := make([]float64, 0, n/8)
 
@@ -34,7 +52,7 @@ func DFT(bs *b.BitStream) (float64, bool, error) {
 	for i := 0; i < n; i++ {
 		bit, err := bs.Bit(i)
 		if err != nil {
-			return 0, false, err
+			return nil, err
 		}
 		X = append(X, 2*float64(bit)-1)
 	}
@@ -68,7 +86,7 @@ func DFT(bs *b.BitStream) (float64, bool, error) {
 
 	p_value := math.Erfc(math.Abs(d) / math.Sqrt2)
 
-	return p_value, p_value >= 0.01, nil
+	return singleResult(t, n, p_value, d, map[string]int64{"N_1": int64(observedPeaks)}), nil
 }
 
 // modulus calculates the modulus (absolute value) of the first half of the input sequence.
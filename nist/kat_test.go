@@ -0,0 +1,199 @@
+package nist
+
+import (
+	"sync"
+	"testing"
+
+	b "github.com/notJoon/drbg/bitstream"
+	"github.com/notJoon/drbg/expansion"
+)
+
+// Known-answer tests against the results published in SP 800-22 Rev. 1a: the worked
+// examples of each section (2.x.4 and 2.x.8) and the results of Appendix B for the
+// first 1,000,000 bits of the binary expansion of e (data.e of the NIST reference
+// implementation).
+
+var (
+	eOnce sync.Once
+	eBits *b.BitStream
+)
+
+// e1e6 returns the first 1,000,000 bits of e, computed once for every test.
+func e1e6() *b.BitStream {
+	eOnce.Do(func() { eBits = expansion.E(1000000) })
+	return eBits
+}
+
+// eBitsN returns the first n bits of e.
+func eBitsN(n int) func() *b.BitStream {
+	return func() *b.BitStream {
+		bs, _ := e1e6().Slice(0, n)
+		return bs
+	}
+}
+
+// pi100 returns the first 100 bits of π, used by the examples of SP 800-22.
+func pi100() *b.BitStream {
+	return expansion.Pi(100)
+}
+
+// bits returns the sequence written as a string of ones and zeros.
+func bits(s string) func() *b.BitStream {
+	return func() *b.BitStream {
+		bs := b.NewBitStream(nil)
+		for _, c := range s {
+			bs.Append(byte(c - '0'))
+		}
+		return bs
+	}
+}
+
+func TestKnownAnswers(t *testing.T) {
+	B := parseKATTemplate
+
+	tests := []struct {
+		name  string
+		test  Test
+		input func() *b.BitStream
+		want  []float64 // the published p-values
+		tol   float64   // the published values are rounded to 6 decimals
+	}{
+		// 2.1 Frequency (Monobit) Test
+		{"2.1.4", NewFrequencyTest(), bits("1011010101"), []float64{0.527089}, 1e-6},
+		{"2.1.8", NewFrequencyTest(), pi100, []float64{0.109599}, 1e-6},
+		{"B e", NewFrequencyTest(), e1e6, []float64{0.953749}, 1e-6},
+
+		// 2.2 Frequency Test within a Block
+		{"2.2.4", NewBlockFrequencyTest(3), bits("0110011010"), []float64{0.801252}, 1e-6},
+		{"2.2.8", NewBlockFrequencyTest(10), pi100, []float64{0.706438}, 1e-6},
+		{"B e", NewBlockFrequencyTest(100), e1e6, []float64{0.619340}, 1e-6}, // obtained with blocks of 100 bits
+
+		// 2.3 Runs Test
+		{"2.3.4", NewRunsTest(), bits("1001101011"), []float64{0.147232}, 1e-6},
+		{"2.3.8", NewRunsTest(), pi100, []float64{0.500798}, 1e-6},
+		{"B e", NewRunsTest(), e1e6, []float64{0.561917}, 1e-6},
+
+		// 2.4 Test for the Longest Run of Ones in a Block. The example publishes 0.180609,
+		// computed with more digits of the probabilities π_i than the reference implementation
+		// (and this package) use, which gives 0.180598.
+		{"2.4.8", NewLongestRunOfOnesTest(), bits("11001100000101010110110001001100111000000000001001001101010100010001001111010110100000001101011111001100111001101101100010110010"), []float64{0.180609}, 2e-5},
+		{"B e", NewLongestRunOfOnesTest(), e1e6, []float64{0.718945}, 1e-6},
+
+		// 2.5 Binary Matrix Rank Test
+		{"2.5.8", NewRankTest(), eBitsN(100000), []float64{0.532069}, 1e-6},
+		{"B e", NewRankTest(), e1e6, []float64{0.306156}, 1e-6},
+
+		// 2.6 Discrete Fourier Transform (Spectral) Test. The examples of the section were
+		// computed with the threshold of an earlier revision and are not reproduced here.
+		{"B e", NewDFTTest(), e1e6, []float64{0.847187}, 1e-6},
+
+		// 2.7 Non-overlapping Template Matching Test
+		{"2.7.4", NewNonOverlappingTemplateTest(B("001"), 10), bits("10100100101110010110"), []float64{0.344154}, 1e-6},
+		{"B e", NewNonOverlappingTemplateTest(B("000000001"), 0), e1e6, []float64{0.078790}, 1e-6},
+
+		// 2.8 Overlapping Template Matching Test
+		{"2.8.8", NewOverlappingTemplateTest(B("111111111"), 1032), e1e6, []float64{0.110434}, 1e-6},
+
+		// 2.9 Maurer's "Universal Statistical" Test
+		{"2.9.8", NewUniversalTest(7, 1280), e1e6, []float64{0.282568}, 1e-6},
+		{"B e", NewUniversalTest(0, 0), e1e6, []float64{0.282568}, 1e-6},
+
+		// 2.10 Linear Complexity Test
+		{"2.10.8", NewLinearComplexityTest(1000), e1e6, []float64{0.845406}, 1e-6},
+		{"B e", NewLinearComplexityTest(500), e1e6, []float64{0.826335}, 1e-6},
+
+		// 2.11 Serial Test
+		{"2.11.4", NewSerialTest(3), bits("0011011101"), []float64{0.808792, 0.670320}, 1e-6},
+		{"2.11.8", NewSerialTest(2), e1e6, []float64{0.843764, 0.561915}, 1e-6},
+		{"B e", NewSerialTest(16), e1e6, []float64{0.766182, 0.462921}, 1e-6},
+
+		// 2.12 Approximate Entropy Test. The result of Appendix B is obtained with blocks of 5 bits.
+		{"2.12.4", NewApproximateEntropyTest(3), bits("0100110101"), []float64{0.261961}, 1e-6},
+		{"2.12.8", NewApproximateEntropyTest(2), pi100, []float64{0.235301}, 1e-6},
+		{"B e", NewApproximateEntropyTest(5), e1e6, []float64{0.361687}, 1e-6},
+
+		// 2.13 Cumulative Sums (Cusum) Test
+		{"2.13.4", NewCumulativeSumsTest(CusumForward), bits("1011010111"), []float64{0.4116588}, 1e-6},
+		{"2.13.8", NewCumulativeSumsTest(CusumBoth), pi100, []float64{0.219194, 0.114866}, 1e-6},
+		{"B e", NewCumulativeSumsTest(CusumBoth), e1e6, []float64{0.669887, 0.724266}, 1e-6},
+
+		// 2.14 Random Excursions Test, states x = -4, ..., -1, +1, ..., +4
+		{"2.14.8", NewRandomExcursionsTest(), e1e6, []float64{
+			0.573306, 0.197996, 0.164011, 0.007779, 0.786868, 0.440912, 0.797854, 0.778186,
+		}, 1e-6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.test.Name()+"/"+tt.name, func(t *testing.T) {
+			res, err := tt.test.Run(tt.input())
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if len(res.PValues) != len(tt.want) {
+				t.Fatalf("got %d p-values, want %d", len(res.PValues), len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !almostEq(res.PValues[i], want, tt.tol) {
+					t.Errorf("%s: p-value = %.6f, want %.6f", res.Label(i), res.PValues[i], want)
+				}
+			}
+		})
+	}
+}
+
+// TestKnownAnswersRandomExcursionsVariant checks the visits ξ(x) and the p-values
+// of the states published in section 2.15.8.
+func TestKnownAnswersRandomExcursionsVariant(t *testing.T) {
+	want := []struct {
+		x   string
+		ksi float64
+		p   float64
+	}{
+		{"x = -9", 1450, 0.858946},
+		{"x = -8", 1435, 0.794755},
+		{"x = -7", 1380, 0.576249},
+		{"x = -6", 1366, 0.493417},
+		{"x = -5", 1412, 0.633873},
+		{"x = -4", 1475, 0.917283},
+		{"x = -3", 1480, 0.934708},
+		{"x = -2", 1468, 0.816012},
+		{"x = -1", 1502, 0.826009},
+		{"x = +1", 1409, 0.137861},
+		{"x = +2", 1369, 0.200642},
+		{"x = +3", 1396, 0.441254},
+		{"x = +4", 1479, 0.939291},
+		{"x = +5", 1599, 0.505683},
+	}
+
+	res, err := NewRandomExcursionsVariantTest().Run(e1e6())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if res.Counts["J"] != 1490 {
+		t.Errorf("J = %d, want 1490", res.Counts["J"])
+	}
+
+	byLabel := make(map[string]int)
+	for i := range res.PValues {
+		byLabel[res.Label(i)] = i
+	}
+	for _, w := range want {
+		i, ok := byLabel[w.x]
+		if !ok {
+			t.Errorf("missing state %s", w.x)
+			continue
+		}
+		if res.Statistics[i] != w.ksi || !almostEq(res.PValues[i], w.p, 1e-6) {
+			t.Errorf("%s: ksi = %v, p-value = %.6f, want %v, %.6f", w.x, res.Statistics[i], res.PValues[i], w.ksi, w.p)
+		}
+	}
+}
+
+// parseKATTemplate returns the template written as a string of ones and zeros.
+func parseKATTemplate(s string) []uint8 {
+	B := make([]uint8, len(s))
+	for i, c := range s {
+		B[i] = uint8(c - '0')
+	}
+	return B
+}
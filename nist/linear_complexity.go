@@ -1,6 +1,7 @@
 package nist
 
 import (
+	"fmt"
 	"math"
 
 	b "github.com/notJoon/drbg/bitstream"
@@ -31,7 +32,33 @@ import (
 // Recommended input
 // 500 ≤ M ≤ 5000
 func LinearComplexity(M uint64, bs *b.BitStream) (float64, bool, error) {
+	res, err := NewLinearComplexityTest(M).Run(bs)
+	if err != nil {
+		return 0, false, err
+	}
+	return res.PValue(), res.Passed(), nil
+}
+
+type linearComplexityTest struct {
+	M uint64 // the length of each block
+}
+
+// NewLinearComplexityTest returns the Linear Complexity Test as a Test, using blocks of M bits.
+func NewLinearComplexityTest(M uint64) Test { return linearComplexityTest{M: M} }
+
+func (linearComplexityTest) Name() string    { return "Linear Complexity Test" }
+func (linearComplexityTest) Section() string { return "2.10" }
+func (t linearComplexityTest) Params() map[string]any {
+	return map[string]any{"M": t.M}
+}
+func (linearComplexityTest) MinLength() int { return 1000000 }
+
+func (t linearComplexityTest) Run(bs *b.BitStream) (*Result, error) {
+	M := t.M
 	n := bs.Len() // n ≥ 1e6
+	if M == 0 || uint64(n) < M {
+		return nil, fmt.Errorf("input sequence length should be at least %d bits, got %d", M, n)
+	}
 	N := uint64(n) / M
 	blocks := make([][]uint8, 0, N)
 
@@ -44,7 +71,7 @@ func LinearComplexity(M uint64, bs *b.BitStream) (float64, bool, error) {
 		for j := uint64(0); j < M; j++ {
 			bit, err := bs.Bit(int(i*M + j))
 			if err != nil {
-				return 0, false, err
+				return nil, err
 			}
 			block[j] = bit
 		}
@@ -84,7 +111,9 @@ func LinearComplexity(M uint64, bs *b.BitStream) (float64, bool, error) {
 		}
 	}
 
-	PI := []float64{0.010417, 0.03125, 0.125, 0.5, 0.25, 0.0625, 0.020833}
+	// π0 is 0.01047 as in the NIST reference implementation, whose published results
+	// depend on it, rather than the exact 1/96 = 0.010417
+	PI := []float64{0.01047, 0.03125, 0.125, 0.5, 0.25, 0.0625, 0.020833}
 	K := 6 // degrees of freedom (K = 6 as defined in SP 800-22)
 	chi_2 := 0.0
 
@@ -96,7 +125,11 @@ func LinearComplexity(M uint64, bs *b.BitStream) (float64, bool, error) {
 
 	p_val := igamc(float64(K)/2.0, chi_2/2.0)
 
-	return p_val, p_val >= 0.01, nil
+	counts := map[string]int64{"N": int64(N)}
+	for i, value := range v {
+		counts[fmt.Sprintf("v%d", i)] = int64(value)
+	}
+	return singleResult(t, n, p_val, chi_2, counts), nil
 }
 
 // berlekampMassey implements the Berlekamp-Massey algorithm for determining the linear complexity of a binary sequence.
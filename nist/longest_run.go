@@ -2,6 +2,7 @@ package nist
 
 import (
 	"errors"
+	"fmt"
 
 	b "github.com/notJoon/drbg/bitstream"
 )
@@ -35,6 +36,119 @@ var (
 //   - bool: True if the test passes (p-value >= 0.01), False otherwise.
 //   - error: Any error that occurred during the test, such as invalid input parameters.
 func LongestRunOfOnes(bs *b.BitStream) (float64, bool, error) {
+	res, err := NewLongestRunOfOnesTest().Run(bs)
+	if err != nil {
+		return 0, false, err
+	}
+	return res.PValue(), res.Passed(), nil
+}
+
+type longestRunTest struct{}
+
+// NewLongestRunOfOnesTest returns the Test for the Longest Run of Ones in a Block as a Test.
+func NewLongestRunOfOnesTest() Test { return longestRunTest{} }
+
+func (longestRunTest) Name() string           { return "Test for the Longest Run of Ones in a Block" }
+func (longestRunTest) Section() string        { return "2.4" }
+func (longestRunTest) Params() map[string]any { return nil }
+func (longestRunTest) MinLength() int         { return 128 }
+
+func (t longestRunTest) Run(bs *b.BitStream) (*Result, error) {
+	return accumulate(t.NewAccumulator(), bs)
+}
+
+func (t longestRunTest) NewAccumulator() Accumulator {
+	return &longestRunAccumulator{
+		t:      t,
+		blocks: [3]longestRunBlocks{{M: 8, K: 3}, {M: 128, K: 5}, {M: 10000, K: 6}},
+	}
+}
+
+// longestRunBlocks tabulates the longest runs of ones in the blocks of M bits.
+type longestRunBlocks struct {
+	M, K           uint64
+	pos            uint64 // position in the current block
+	count, longest uint64
+	v              [7]uint64
+}
+
+func (blk *longestRunBlocks) add(bit byte) {
+	if bit == 0 {
+		blk.longest = max(blk.longest, blk.count)
+		blk.count = 0
+	} else {
+		blk.count++
+	}
+
+	blk.pos++
+	if blk.pos < blk.M {
+		return
+	}
+	longest := max(blk.longest, blk.count)
+	blk.pos, blk.count, blk.longest = 0, 0, 0
+
+	// Tabulate the frequencies νi of the longest runs of ones in each block into categories,
+	// where each cell contains the number of runs of ones of a given length.
+	switch blk.K {
+	case 3:
+		if longest <= 1 {
+			blk.v[0]++
+		} else if longest == 2 {
+			blk.v[1]++
+		} else if longest == 3 {
+			blk.v[2]++
+		} else {
+			blk.v[3]++
+		}
+	case 5:
+		if longest <= 4 {
+			blk.v[0]++
+		} else if longest == 5 {
+			blk.v[1]++
+		} else if longest == 6 {
+			blk.v[2]++
+		} else if longest == 7 {
+			blk.v[3]++
+		} else if longest == 8 {
+			blk.v[4]++
+		} else {
+			blk.v[5]++
+		}
+	case 6:
+		if longest <= 10 {
+			blk.v[0]++
+		} else if longest == 11 {
+			blk.v[1]++
+		} else if longest == 12 {
+			blk.v[2]++
+		} else if longest == 13 {
+			blk.v[3]++
+		} else if longest == 14 {
+			blk.v[4]++
+		} else if longest == 15 {
+			blk.v[5]++
+		} else {
+			blk.v[6]++
+		}
+	}
+}
+
+// longestRunAccumulator tabulates the blocks of every size the test may use,
+// since the block size depends on the length of the sequence.
+type longestRunAccumulator struct {
+	t      longestRunTest
+	n      uint64
+	blocks [3]longestRunBlocks
+}
+
+func (a *longestRunAccumulator) AddBit(bit byte) {
+	a.n++
+	for i := range a.blocks {
+		a.blocks[i].add(bit)
+	}
+}
+
+func (a *longestRunAccumulator) Result() (*Result, error) {
 	// Declare Constant
 	var (
 		_PI_K3_M8     = [4]float64{0.2148, 0.3672, 0.2305, 0.1875}
@@ -50,97 +164,22 @@ func LongestRunOfOnes(bs *b.BitStream) (float64, bool, error) {
 		K uint64
 	)
 
-	n := uint64(bs.Len())
+	t, n := a.t, a.n
 
+	// Divide the sequence into M-bit blocks.
+	var blk *longestRunBlocks
 	if n < 128 {
-		return 0, false, ErrNotEnoughLength
+		return nil, ErrNotEnoughLength
 	} else if n < 6272 {
-		M = 8
-		N = n / 8
-		K = 3
+		blk = &a.blocks[0]
 	} else if n < 750000 {
-		M = 128
-		N = n / 128
-		K = 5
+		blk = &a.blocks[1]
 	} else {
-		M = 10000
-		N = n / 10000
-		K = 6
-	}
-
-	// Divide the sequence into M-bit blocks.
-	sliceBoundary_start := uint64(0)
-	sliceBoundary_end := M
-	v := [7]uint64{0, 0, 0, 0, 0, 0, 0}
-	for {
-		var longest, count uint64
-		for i := sliceBoundary_start; i < sliceBoundary_end; i++ {
-			bit, err := bs.Bit(int(i))
-			if err != nil {
-				return 0, false, err
-			}
-			if bit == 0 {
-				longest = max(longest, count)
-				count = 0
-			} else {
-				count++
-			}
-		}
-		longest = max(longest, count)
-
-		// Tabulate the frequencies νi of the longest runs of ones in each block into categories,
-		// where each cell contains the number of runs of ones of a given length.
-		switch K {
-		case 3:
-			if longest <= 1 {
-				v[0]++
-			} else if longest == 2 {
-				v[1]++
-			} else if longest == 3 {
-				v[2]++
-			} else {
-				v[3]++
-			}
-		case 5:
-			if longest <= 4 {
-				v[0]++
-			} else if longest == 5 {
-				v[1]++
-			} else if longest == 6 {
-				v[2]++
-			} else if longest == 7 {
-				v[3]++
-			} else if longest == 8 {
-				v[4]++
-			} else {
-				v[5]++
-			}
-		case 6:
-			if longest <= 10 {
-				v[0]++
-			} else if longest == 11 {
-				v[1]++
-			} else if longest == 12 {
-				v[2]++
-			} else if longest == 13 {
-				v[3]++
-			} else if longest == 14 {
-				v[4]++
-			} else if longest == 15 {
-				v[5]++
-			} else {
-				v[6]++
-			}
-		default:
-			return 0, false, ErrInvalidValueK
-		}
-
-		sliceBoundary_start += M
-		sliceBoundary_end += M
-		if sliceBoundary_end > n {
-			break
-		}
+		blk = &a.blocks[2]
 	}
+	M, K = blk.M, blk.K
+	N = n / M
+	v := blk.v
 
 	// (3) Compute Test Statistic and Reference Distribution χ^2
 	var (
@@ -169,7 +208,7 @@ func LongestRunOfOnes(bs *b.BitStream) (float64, bool, error) {
 			case 1000:
 				__PI = _PI_K5_M1000[i]
 			default:
-				return 0, false, ErrInvalidValueM
+				return nil, ErrInvalidValueM
 			}
 			__temp := (__v - __N*__PI) * (__v - __N*__PI) / (__N * __PI)
 			chi_square += __temp
@@ -187,5 +226,9 @@ func LongestRunOfOnes(bs *b.BitStream) (float64, bool, error) {
 	// (4) Compute P-value
 	p_value := igamc(float64(K)/2.0, chi_square/2.0)
 
-	return p_value, p_value >= 0.01, nil
+	counts := map[string]int64{"M": int64(M), "N": int64(N)}
+	for i := uint64(0); i <= K; i++ {
+		counts[fmt.Sprintf("v%d", i)] = int64(v[i])
+	}
+	return singleResult(t, int(n), p_value, chi_square, counts), nil
 }
@@ -47,27 +47,54 @@ var ErrEmptyBitStream = errors.New("empty bitstream")
 //   - bool: True if the test passes (p-value >= 0.01), False otherwise.
 //   - error: Any error that occurred during the test, such as invalid input parameters.
 func FrequencyTest(bs *b.BitStream) (float64, bool, error) {
-	n := bs.Len()
-	if n == 0 {
-		return 0, false, ErrEmptyBitStream
+	res, err := NewFrequencyTest().Run(bs)
+	if err != nil {
+		return 0, false, err
 	}
+	return res.PValue(), res.Passed(), nil
+}
+
+type frequencyTest struct{}
+
+// NewFrequencyTest returns the Frequency (Monobit) Test as a Test.
+func NewFrequencyTest() Test { return frequencyTest{} }
 
-	var S_n int64 = 0
-	for i := 0; i < n; i++ {
-		bit, err := bs.Bit(i)
-		if err != nil {
-			return 0, false, err
-		}
-		if bit == 0 {
-			S_n -= 1
-		} else {
-			S_n += 1
-		}
+func (frequencyTest) Name() string           { return "Frequency (Monobit) Test" }
+func (frequencyTest) Section() string        { return "2.1" }
+func (frequencyTest) Params() map[string]any { return nil }
+func (frequencyTest) MinLength() int         { return 100 }
+
+func (t frequencyTest) Run(bs *b.BitStream) (*Result, error) {
+	return accumulate(t.NewAccumulator(), bs)
+}
+
+func (t frequencyTest) NewAccumulator() Accumulator {
+	return &frequencyAccumulator{t: t}
+}
+
+type frequencyAccumulator struct {
+	t   frequencyTest
+	n   int
+	S_n int64
+}
+
+func (a *frequencyAccumulator) AddBit(bit byte) {
+	a.n++
+	if bit == 0 {
+		a.S_n -= 1
+	} else {
+		a.S_n += 1
+	}
+}
+
+func (a *frequencyAccumulator) Result() (*Result, error) {
+	t, n, S_n := a.t, a.n, a.S_n
+	if n == 0 {
+		return nil, ErrEmptyBitStream
 	}
 
 	S_obs := math.Abs(float64(S_n)) / math.Sqrt(float64(n))
 	p_value := math.Erfc(S_obs / math.Sqrt2)
 
-	isRandom := p_value >= 0.01
-	return p_value, isRandom, nil
+	return singleResult(t, n, p_value, S_obs, map[string]int64{"S_n": S_n}), nil
 }
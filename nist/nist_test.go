@@ -1,6 +1,8 @@
 package nist
 
 import (
+	"bytes"
+	"errors"
 	"math"
 	"testing"
 
@@ -64,3 +66,91 @@ func TestMonobit_FrequenctTest(t *testing.T) {
 func almostEq(a, b, epsilon float64) bool {
 	return math.Abs(a-b) < epsilon
 }
+
+// xorshiftBytes returns n deterministic pseudo-random bytes.
+func xorshiftBytes(n int) []byte {
+	data := make([]byte, n)
+	state := uint32(1)
+	for i := range data {
+		state ^= state << 13
+		state ^= state >> 17
+		state ^= state << 5
+		data[i] = byte(state)
+	}
+	return data
+}
+
+func TestResultShape(t *testing.T) {
+	bs := b.NewBitStream(xorshiftBytes(2048))
+	// the random excursion tests need at least 500 cycles of the random walk
+	excursions := map[string]bool{
+		NewRandomExcursionsTest().Name():        true,
+		NewRandomExcursionsVariantTest().Name(): true,
+	}
+
+	tests := []Test{
+		NewFrequencyTest(),
+		NewBlockFrequencyTest(200),
+		NewRunsTest(),
+		NewLongestRunOfOnesTest(),
+		NewRankTest(),
+		NewDFTTest(),
+		NewNonOverlappingTemplateTest([]uint8{0, 0, 1}, 128),
+		NewSerialTest(3),
+		NewApproximateEntropyTest(2),
+		NewCumulativeSumsTest(1),
+		NewRandomExcursionsTest(),
+		NewRandomExcursionsVariantTest(),
+		NewAutocorrelationTest(nil),
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name(), func(t *testing.T) {
+			bs := bs
+			if excursions[test.Name()] {
+				bs = e1e6()
+			}
+			res, err := test.Run(bs)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if res.Name != test.Name() {
+				t.Errorf("Result.Name = %q, expected %q", res.Name, test.Name())
+			}
+			if res.N != bs.Len() {
+				t.Errorf("Result.N = %d, expected %d", res.N, bs.Len())
+			}
+			if len(res.PValues) == 0 || len(res.Statistics) != len(res.PValues) {
+				t.Errorf("got %d p-values and %d statistics", len(res.PValues), len(res.Statistics))
+			}
+			if len(res.Labels) != 0 && len(res.Labels) != len(res.PValues) {
+				t.Errorf("got %d labels for %d p-values", len(res.Labels), len(res.PValues))
+			}
+			for _, p := range res.PValues {
+				if p < 0 || p > 1 || math.IsNaN(p) {
+					t.Errorf("p-value %v out of range", p)
+				}
+			}
+		})
+	}
+}
+
+func TestRandomExcursionsCycles(t *testing.T) {
+	// 16384 random bits return to zero a few dozen times at most
+	short := b.NewBitStream(xorshiftBytes(2048))
+	// 0x55 is 01010101: the random walk returns to zero every 2 bits
+	alternating := b.NewBitStream(bytes.Repeat([]byte{0x55}, 250))
+
+	for _, test := range []Test{NewRandomExcursionsTest(), NewRandomExcursionsVariantTest()} {
+		if _, err := test.Run(short); !errors.Is(err, ErrNotEnoughCycles) {
+			t.Errorf("%s: got %v, expected ErrNotEnoughCycles", test.Name(), err)
+		}
+		res, err := test.Run(alternating)
+		if err != nil {
+			t.Fatalf("%s: %v", test.Name(), err)
+		}
+		if res.Counts["J"] < 1000 {
+			t.Errorf("%s: J = %d, expected at least 1000", test.Name(), res.Counts["J"])
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package nist
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"math"
@@ -54,18 +55,76 @@ import (
 //   - bool: True if the test passes (p-value >= 0.01), False otherwise.
 //   - error: Any error that occurred during the test, such as invalid input parameters.
 func NonOverlappingTemplateMatching(B []uint8, eachBlockSize uint64, bs *b.BitStream) (float64, bool, error) {
-	m := len(B)
+	res, err := NewNonOverlappingTemplateTest(B, eachBlockSize).Run(bs)
+	if err != nil {
+		return 0, false, err
+	}
+	return res.PValue(), res.Passed(), nil
+}
+
+// nonOverlappingBlocks is the number of blocks N used by the NIST reference
+// implementation when no block length is given.
+const nonOverlappingBlocks = 8
+
+type nonOverlappingTemplateTest struct {
+	templates [][]uint8 // the templates to be matched
+	M         uint64    // the length of each block, 0 to split the input into 8 blocks
+}
+
+// NewNonOverlappingTemplateTest returns the Non-overlapping Template Matching Test as a Test,
+// searching for the template B in blocks of M bits. If M is zero, the input is split into
+// N = 8 blocks as in the NIST reference implementation.
+func NewNonOverlappingTemplateTest(B []uint8, M uint64) Test {
+	return nonOverlappingTemplateTest{templates: [][]uint8{B}, M: M}
+}
+
+// NewAperiodicTemplatesTest returns the Non-overlapping Template Matching Test run once for
+// every aperiodic template of m bits, as in the NIST reference implementation. The result
+// holds one p-value per template, labelled with the template.
+func NewAperiodicTemplatesTest(m int, M uint64) Test {
+	return nonOverlappingTemplateTest{templates: AperiodicTemplates(m), M: M}
+}
+
+func (nonOverlappingTemplateTest) Name() string    { return "Non-overlapping Template Matching Test" }
+func (nonOverlappingTemplateTest) Section() string { return "2.7" }
+func (t nonOverlappingTemplateTest) Params() map[string]any {
+	params := map[string]any{"M": t.M}
+	if len(t.templates) == 1 {
+		params["B"] = templateString(t.templates[0])
+	} else {
+		params["templates"] = len(t.templates)
+	}
+	if len(t.templates) > 0 {
+		params["m"] = len(t.templates[0])
+	}
+	return params
+}
+func (t nonOverlappingTemplateTest) MinLength() int {
+	if t.M == 0 {
+		return 100
+	}
+	return int(t.M)
+}
+
+func (t nonOverlappingTemplateTest) Run(bs *b.BitStream) (*Result, error) {
 	n := bs.Len()
-	M := eachBlockSize
+	M := t.M
+	if M == 0 {
+		M = uint64(n) / nonOverlappingBlocks
+	}
+	if M == 0 {
+		return nil, ErrEmptyBitStream
+	}
+	if len(t.templates) == 0 {
+		return nil, errors.New("no template to match")
+	}
+	// the bits past the last complete block are discarded
 	N := uint64(n) / M
-
-	if uint64(n)%M != 0 {
-		errorMessage := fmt.Sprintf("Invalid input: eachBlockSize=%v. It should be a multiple of %v, but %v mod %v gives a remainder of %v. Please provide an input that is a multiple of %v.", eachBlockSize, M, n, M, uint64(n)%M, M)
-		return 0, false, errors.New(errorMessage)
+	if N == 0 {
+		return nil, fmt.Errorf("input sequence length should be at least the block length %d, got %d", M, n)
 	}
 
 	blocks := make([][]uint8, N)
-	W := make([]uint64, N)
 	partitionStart := uint64(0)
 	partitionEnd := M
 	for j := range blocks {
@@ -73,7 +132,7 @@ func NonOverlappingTemplateMatching(B []uint8, eachBlockSize uint64, bs *b.BitSt
 		for i := partitionStart; i < partitionEnd; i++ {
 			bit, err := bs.Bit(int(i))
 			if err != nil {
-				return 0, false, err
+				return nil, err
 			}
 			block[i-partitionStart] = bit
 		}
@@ -82,6 +141,31 @@ func NonOverlappingTemplateMatching(B []uint8, eachBlockSize uint64, bs *b.BitSt
 		partitionEnd += M
 	}
 
+	res := &Result{Name: t.Name(), N: n, Counts: make(map[string]int64)}
+	for _, B := range t.templates {
+		p_value, chi_square, W := nonOverlappingTemplate(B, M, blocks)
+
+		prefix := ""
+		if len(t.templates) > 1 {
+			prefix = templateString(B) + " "
+			res.Labels = append(res.Labels, templateString(B))
+		}
+		res.PValues = append(res.PValues, p_value)
+		res.Statistics = append(res.Statistics, chi_square)
+		for j, value := range W {
+			res.Counts[fmt.Sprintf("%sW%d", prefix, j+1)] = int64(value)
+		}
+	}
+	return res, nil
+}
+
+// nonOverlappingTemplate counts the occurrences W of the template B in each block
+// and returns the p-value and the χ² statistic of the counts.
+func nonOverlappingTemplate(B []uint8, M uint64, blocks [][]uint8) (float64, float64, []uint64) {
+	m := len(B)
+	N := len(blocks)
+	W := make([]uint64, N)
+
 	for j := range blocks {
 		for bitPosition := 0; bitPosition <= int(M)-m; bitPosition++ {
 			match := true
@@ -109,6 +193,30 @@ func NonOverlappingTemplateMatching(B []uint8, eachBlockSize uint64, bs *b.BitSt
 	}
 
 	p_value := igamc(float64(N)/2.0, chi_square/2.0)
+	return p_value, chi_square, W
+}
 
-	return p_value, p_value >= 0.01, nil
+// AperiodicTemplates returns every aperiodic template of m bits in increasing order.
+// A template is aperiodic if no proper shift of it overlaps itself, i.e. none of
+// its proper prefixes is also a suffix (e.g. "001" is aperiodic, "010" is not).
+func AperiodicTemplates(m int) [][]uint8 {
+	var templates [][]uint8
+	for value := uint64(0); value < 1<<m; value++ {
+		B := Uint_To_BitsArray_size_N(value, uint64(m))
+		if isAperiodic(B) {
+			templates = append(templates, B)
+		}
+	}
+	return templates
+}
+
+// isAperiodic reports whether no proper prefix of B is also a suffix of B.
+func isAperiodic(B []uint8) bool {
+	m := len(B)
+	for shift := 1; shift < m; shift++ {
+		if bytes.Equal(B[:m-shift], B[shift:]) {
+			return false
+		}
+	}
+	return true
 }
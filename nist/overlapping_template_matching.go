@@ -2,6 +2,7 @@ package nist
 
 import (
 	"bytes"
+	"fmt"
 	"math"
 
 	b "github.com/notJoon/drbg/bitstream"
@@ -23,82 +24,109 @@ import (
 //   - bool: True if the test passes (p-value >= 0.01), False otherwise.
 //   - error: Any error that occurred during the test, such as invalid input parameters.
 func OverlappingTemplateMatching(B []uint8, eachBlockSize uint64, bs *b.BitStream) (float64, bool, error) {
+	res, err := NewOverlappingTemplateTest(B, eachBlockSize).Run(bs)
+	if err != nil {
+		return 0, false, err
+	}
+	return res.PValue(), res.Passed(), nil
+}
+
+// overlappingBlockLength is the block length M used by the NIST reference implementation.
+const overlappingBlockLength = 1032
+
+type overlappingTemplateTest struct {
+	B []uint8 // the template to be matched
+	M uint64  // the length of each block
+}
+
+// NewOverlappingTemplateTest returns the Overlapping Template Matching Test as a Test,
+// searching for the template B in blocks of M bits. If M is zero, blocks of
+// 1032 bits are used as in the NIST reference implementation.
+func NewOverlappingTemplateTest(B []uint8, M uint64) Test {
+	if M == 0 {
+		M = overlappingBlockLength
+	}
+	return overlappingTemplateTest{B: B, M: M}
+}
+
+func (overlappingTemplateTest) Name() string    { return "Overlapping Template Matching Test" }
+func (overlappingTemplateTest) Section() string { return "2.8" }
+func (t overlappingTemplateTest) Params() map[string]any {
+	return map[string]any{"B": templateString(t.B), "m": len(t.B), "M": t.M}
+}
+func (overlappingTemplateTest) MinLength() int { return 1000000 }
+
+func (t overlappingTemplateTest) Run(bs *b.BitStream) (*Result, error) {
+	B := t.B
 	m := len(B)
 	n := bs.Len()
 
+	const K = 5 // the number of degrees of freedom
+
 	var (
-		M uint64 = eachBlockSize   // The length of the substring of ε to be tested.
-		N uint64 = (uint64(n) / M) // The number of independent blocks. N has to be fixed at 8.
+		M uint64 = t.M           // The length of the substring of ε to be tested.
+		N uint64 = uint64(n) / M // The number of independent blocks.
 	)
+	if m == 0 || uint64(m) > M {
+		return nil, fmt.Errorf("invalid template length. got %d, should be between 1 and the block length %d", m, M)
+	}
+	if N == 0 {
+		return nil, fmt.Errorf("input sequence length should be at least %d bits, got %d", M, n)
+	}
 
-	// partition the sequence into N independent blocks of length M.
-	blocks := make([][]uint8, N)
-
-	// The number of occurrences of B in each block
-	// by incrementing an array v[i]
-	v := make([]float64, 6)
+	// The number of blocks in which B occurs i times (i < K),
+	// and K times or more in v[K]
+	v := make([]float64, K+1)
 
-	var (
-		start uint64 = 0
-		end   uint64 = M
-	)
-	for j := range blocks {
-		block := make([]uint8, M)
-		for i := start; i < end; i++ {
-			bit, err := bs.Bit(int(i))
+	// search for matches in each of the N independent blocks of length M
+	block := make([]uint8, M)
+	for j := uint64(0); j < N; j++ {
+		for i := range block {
+			bit, err := bs.Bit(int(j*M) + i)
 			if err != nil {
-				return 0, false, err
+				return nil, err
 			}
-			block[i-start] = bit
+			block[i] = bit
 		}
-		blocks[j] = block
-		start = end
-		end = end + M
-	}
 
-	// search for matches
-	var numberOfOccurrence uint64
-	for _, block := range blocks {
-		numberOfOccurrence = 0
+		numberOfOccurrence := 0
 		for bitPos := 0; bitPos <= len(block)-m; bitPos++ {
 			if bytes.Equal(block[bitPos:bitPos+m], B) {
 				numberOfOccurrence++
-				if numberOfOccurrence >= 5 {
-					v[numberOfOccurrence]++
-				}
 			}
 		}
+		v[min(numberOfOccurrence, K)]++
 	}
 
 	// Compute values for λ, η
 	_float64_m := float64(m)
-	lambda := (float64(N) - _float64_m + 1) / math.Pow(2, _float64_m)
+	lambda := (float64(M) - _float64_m + 1) / math.Pow(2, _float64_m)
 	eta := lambda / 2.0
 
 	// Compute χ^2 as specified in Section 3.8 (p.74)
-	pi := []float64{0.364091, 0.185659, 0.139381, 0.100571, 0.070432, 0.139865}
-
+	pi := make([]float64, K+1)
 	sum := 0.0
-	K := 5
 	for i := 0; i < K; i++ {
 		pi[i] = Pr(i, eta)
 		sum += pi[i]
 	}
-
 	pi[K] = 1 - sum
 
 	chi2 := 0.0
 	_float64_N := float64(N)
-
 	for i := range v {
 		tmp := _float64_N * pi[i]
 		diff := v[i] - tmp
-		chi2 = diff * diff / tmp
+		chi2 += diff * diff / tmp
 	}
 
-	p_value := igamc(2.5, chi2/2.0)
+	p_value := igamc(float64(K)/2.0, chi2/2.0)
 
-	return p_value, p_value >= 0.01, nil
+	counts := make(map[string]int64, len(v))
+	for i, value := range v {
+		counts[fmt.Sprintf("v%d", i)] = int64(value)
+	}
+	return singleResult(t, n, p_value, chi2, counts), nil
 }
 
 // Pr calculates the probability of observing u occurrences of the template
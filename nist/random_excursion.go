@@ -1,13 +1,54 @@
 package nist
 
 import (
+	"errors"
+	"fmt"
 	"math"
 
 	b "github.com/notJoon/drbg/bitstream"
 )
 
+// ErrNotEnoughCycles is returned by the random excursion tests when the random walk has
+// fewer than max(0.005 sqrt(n), 500) cycles, the bound under which the reference
+// implementation reports the tests as not applicable.
+var ErrNotEnoughCycles = errors.New("insufficient number of cycles")
+
+// checkCycles returns ErrNotEnoughCycles if J cycles are too few for the random excursion
+// tests on n bits.
+func checkCycles(J, n uint64) error {
+	if bound := math.Max(0.005*math.Sqrt(float64(n)), 500); float64(J) < bound {
+		return fmt.Errorf("%w: J = %d, at least %.0f are needed", ErrNotEnoughCycles, J, bound)
+	}
+	return nil
+}
+
 func RandomExcursions(bs *b.BitStream) ([]float64, []bool, error) {
+	res, err := NewRandomExcursionsTest().Run(bs)
+	if err != nil {
+		return nil, nil, err
+	}
+	pass := make([]bool, len(res.PValues))
+	for i := range pass {
+		pass[i] = res.Pass(i)
+	}
+	return res.PValues, pass, nil
+}
+
+type randomExcursionsTest struct{}
+
+// NewRandomExcursionsTest returns the Random Excursions Test as a Test.
+func NewRandomExcursionsTest() Test { return randomExcursionsTest{} }
+
+func (randomExcursionsTest) Name() string           { return "Random Excursions Test" }
+func (randomExcursionsTest) Section() string        { return "2.14" }
+func (randomExcursionsTest) Params() map[string]any { return nil }
+func (randomExcursionsTest) MinLength() int         { return 1000000 }
+
+func (t randomExcursionsTest) Run(bs *b.BitStream) (*Result, error) {
 	n := uint64(bs.Len())
+	if n == 0 {
+		return nil, ErrEmptyBitStream
+	}
 	var State_X []int64 = []int64{-4, -3, -2, -1, 1, 2, 3, 4}
 
 	var X []int64 = make([]int64, n)
@@ -15,7 +56,7 @@ func RandomExcursions(bs *b.BitStream) ([]float64, []bool, error) {
 	for i := uint64(0); i < n; i++ {
 		bit, err := bs.Bit(int(i))
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 		X[i] = 2*int64(bit) - 1
 	}
@@ -39,6 +80,9 @@ func RandomExcursions(bs *b.BitStream) ([]float64, []bool, error) {
 		}
 	}
 	J = J - 1
+	if err := checkCycles(J, n); err != nil {
+		return nil, err
+	}
 
 	Cycles := make([][]uint64, 8)
 	var CycleIndex int64 = -1
@@ -100,12 +144,23 @@ func RandomExcursions(bs *b.BitStream) ([]float64, []bool, error) {
 	}
 
 	p_value := make([]float64, 8)
-	randomness := make([]bool, 8)
+	labels := make([]string, 8)
+	counts := map[string]int64{"J": int64(J)}
 
-	for i := range p_value {
+	for i, x := range State_X {
 		p_value[i] = igamc(5.0/2.0, chi2[i]/2.0)
-		randomness[i] = p_value[i] >= 0.01
+		labels[i] = fmt.Sprintf("x = %+d", x)
+		for k := range v[i] {
+			counts[fmt.Sprintf("x = %+d, v%d", x, k)] = int64(v[i][k])
+		}
 	}
 
-	return p_value, randomness, nil
+	return &Result{
+		Name:       t.Name(),
+		N:          int(n),
+		PValues:    p_value,
+		Labels:     labels,
+		Statistics: chi2,
+		Counts:     counts,
+	}, nil
 }
@@ -1,13 +1,39 @@
 package nist
 
 import (
+	"fmt"
 	"math"
 
 	b "github.com/notJoon/drbg/bitstream"
 )
 
 func RandomExcursionsVariant(bs *b.BitStream) ([]float64, []bool, error) {
+	res, err := NewRandomExcursionsVariantTest().Run(bs)
+	if err != nil {
+		return nil, nil, err
+	}
+	pass := make([]bool, len(res.PValues))
+	for i := range pass {
+		pass[i] = res.Pass(i)
+	}
+	return res.PValues, pass, nil
+}
+
+type randomExcursionsVariantTest struct{}
+
+// NewRandomExcursionsVariantTest returns the Random Excursions Variant Test as a Test.
+func NewRandomExcursionsVariantTest() Test { return randomExcursionsVariantTest{} }
+
+func (randomExcursionsVariantTest) Name() string           { return "Random Excursions Variant Test" }
+func (randomExcursionsVariantTest) Section() string        { return "2.15" }
+func (randomExcursionsVariantTest) Params() map[string]any { return nil }
+func (randomExcursionsVariantTest) MinLength() int         { return 1000000 }
+
+func (t randomExcursionsVariantTest) Run(bs *b.BitStream) (*Result, error) {
 	n := uint64(bs.Len())
+	if n == 0 {
+		return nil, ErrEmptyBitStream
+	}
 	var State_X []int64 = []int64{-9, -8, -7, -6, -5, -4, -3, -2, -1, 1, 2, 3, 4, 5, 6, 7, 8, 9}
 
 	var X []int64 = make([]int64, n)
@@ -15,7 +41,7 @@ func RandomExcursionsVariant(bs *b.BitStream) ([]float64, []bool, error) {
 	for i := uint64(0); i < n; i++ {
 		bit, err := bs.Bit(int(i))
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 		X[i] = 2*int64(bit) - 1
 	}
@@ -39,6 +65,9 @@ func RandomExcursionsVariant(bs *b.BitStream) ([]float64, []bool, error) {
 		}
 	}
 	J = J - 1
+	if err := checkCycles(uint64(J), n); err != nil {
+		return nil, err
+	}
 
 	var ksi [18]int64
 	for _, value := range S_Prime {
@@ -50,11 +79,22 @@ func RandomExcursionsVariant(bs *b.BitStream) ([]float64, []bool, error) {
 	}
 
 	var P_value []float64 = make([]float64, 18)
-	var randomness []bool = make([]bool, 18)
+	var statistics []float64 = make([]float64, 18)
+	var labels []string = make([]string, 18)
+	counts := map[string]int64{"J": J}
 	for i := range P_value {
 		P_value[i] = math.Erfc(math.Abs(float64(ksi[i]-J)) / math.Sqrt(2.0*float64(J)*(4.0*math.Abs(float64(State_X[i]))-2.0)))
-		randomness[i] = P_value[i] >= 0.01
+		statistics[i] = float64(ksi[i])
+		labels[i] = fmt.Sprintf("x = %+d", State_X[i])
+		counts[fmt.Sprintf("ksi(%+d)", State_X[i])] = ksi[i]
 	}
 
-	return P_value, randomness, nil
+	return &Result{
+		Name:       t.Name(),
+		N:          int(n),
+		PValues:    P_value,
+		Labels:     labels,
+		Statistics: statistics,
+		Counts:     counts,
+	}, nil
 }
@@ -0,0 +1,186 @@
+package nist
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	ErrUnknownTest   = errors.New("unknown test")
+	ErrDuplicateTest = errors.New("test already registered")
+)
+
+// Options holds the parameters used to build the tests of a Registry.
+// Tests that take no parameters ignore it.
+type Options struct {
+	BlockFrequencyM     uint64  // the block length of the Frequency Test within a Block
+	Template            []uint8 // the template B of the Non-overlapping Template Matching Test, nil for every aperiodic template
+	TemplateM           uint64  // the block length of the Non-overlapping Template Matching Test, 0 for the NIST default
+	OverlappingTemplate []uint8 // the template B of the Overlapping Template Matching Test, nil for a run of 9 ones
+	OverlappingM        uint64  // the block length of the Overlapping Template Matching Test, 0 for 1032 bits
+	LinearComplexityM   uint64  // the block length of the Linear Complexity Test
+	SerialM             uint64  // the block length of the Serial Test
+	ApproximateEntropyM uint64  // the block length of the Approximate Entropy Test
+	CusumMode           int     // the mode of the Cumulative Sums Test (CusumForward, CusumBackward or CusumBoth)
+}
+
+// DefaultOptions returns the parameters used when none are given.
+func DefaultOptions() Options {
+	return Options{
+		BlockFrequencyM:     128,
+		Template:            []uint8{0, 0, 0, 0, 0, 0, 0, 0, 1},
+		TemplateM:           20,
+		OverlappingM:        overlappingBlockLength,
+		LinearComplexityM:   500,
+		SerialM:             16,
+		ApproximateEntropyM: 10,
+		CusumMode:           CusumBoth,
+	}
+}
+
+// Factory builds a Test from the given options.
+type Factory func(opts Options) (Test, error)
+
+// Registry maps canonical test ids (e.g. "frequency", "rank") to the factories
+// building them. It is safe for concurrent use.
+type Registry struct {
+	mu        sync.RWMutex
+	ids       []string // ids in registration order
+	factories map[string]Factory
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]Factory)}
+}
+
+// Register adds a test to the registry under the given id.
+// It returns an error if the id is empty or already in use.
+func (r *Registry) Register(id string, factory Factory) error {
+	if id == "" || factory == nil {
+		return fmt.Errorf("invalid registration for test %q", id)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.factories[id]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateTest, id)
+	}
+	r.ids = append(r.ids, id)
+	r.factories[id] = factory
+	return nil
+}
+
+// IDs returns the ids of every registered test in registration order.
+func (r *Registry) IDs() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]string, len(r.ids))
+	copy(ids, r.ids)
+	return ids
+}
+
+// Lookup returns the factory registered under the given id.
+func (r *Registry) Lookup(id string) (Factory, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	factory, ok := r.factories[id]
+	return factory, ok
+}
+
+// New builds the test registered under the given id.
+func (r *Registry) New(id string, opts Options) (Test, error) {
+	factory, ok := r.Lookup(id)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTest, id)
+	}
+	return factory(opts)
+}
+
+// DefaultRegistry holds every test of SP 800-22, in the order of the document.
+var DefaultRegistry = NewRegistry()
+
+// Register adds a test to the DefaultRegistry. It panics if the id is already in use,
+// so that third-party tests can register themselves from an init function.
+func Register(id string, factory Factory) {
+	if err := DefaultRegistry.Register(id, factory); err != nil {
+		panic(err)
+	}
+}
+
+// IDs returns the ids of every test in the DefaultRegistry.
+func IDs() []string {
+	return DefaultRegistry.IDs()
+}
+
+// New builds the test registered under the given id in the DefaultRegistry.
+func New(id string, opts Options) (Test, error) {
+	return DefaultRegistry.New(id, opts)
+}
+
+func init() {
+	Register("frequency", func(Options) (Test, error) {
+		return NewFrequencyTest(), nil
+	})
+	Register("block-frequency", func(opts Options) (Test, error) {
+		return NewBlockFrequencyTest(opts.BlockFrequencyM), nil
+	})
+	Register("runs", func(Options) (Test, error) {
+		return NewRunsTest(), nil
+	})
+	Register("longest-run", func(Options) (Test, error) {
+		return NewLongestRunOfOnesTest(), nil
+	})
+	Register("rank", func(Options) (Test, error) {
+		return NewRankTest(), nil
+	})
+	Register("dft", func(Options) (Test, error) {
+		return NewDFTTest(), nil
+	})
+	Register("non-overlapping-template", func(opts Options) (Test, error) {
+		// without a template, every aperiodic template of 9 bits is matched
+		if len(opts.Template) == 0 {
+			return NewAperiodicTemplatesTest(9, opts.TemplateM), nil
+		}
+		return NewNonOverlappingTemplateTest(opts.Template, opts.TemplateM), nil
+	})
+	Register("overlapping-template", func(opts Options) (Test, error) {
+		// without a template, a run of 9 ones is matched
+		template := opts.OverlappingTemplate
+		if len(template) == 0 {
+			template = []uint8{1, 1, 1, 1, 1, 1, 1, 1, 1}
+		}
+		return NewOverlappingTemplateTest(template, opts.OverlappingM), nil
+	})
+	Register("universal", func(Options) (Test, error) {
+		return NewUniversalTest(0, 0), nil
+	})
+	Register("linear-complexity", func(opts Options) (Test, error) {
+		if opts.LinearComplexityM < 500 || opts.LinearComplexityM > 5000 {
+			return nil, fmt.Errorf("block size must be between 500 and 5000, got %d", opts.LinearComplexityM)
+		}
+		return NewLinearComplexityTest(opts.LinearComplexityM), nil
+	})
+	Register("serial", func(opts Options) (Test, error) {
+		return NewSerialTest(opts.SerialM), nil
+	})
+	Register("approximate-entropy", func(opts Options) (Test, error) {
+		return NewApproximateEntropyTest(opts.ApproximateEntropyM), nil
+	})
+	Register("cumulative-sums", func(opts Options) (Test, error) {
+		if opts.CusumMode < CusumForward || opts.CusumMode > CusumBoth {
+			return nil, fmt.Errorf("invalid mode: %d", opts.CusumMode)
+		}
+		return NewCumulativeSumsTest(opts.CusumMode), nil
+	})
+	Register("random-excursions", func(Options) (Test, error) {
+		return NewRandomExcursionsTest(), nil
+	})
+	Register("random-excursions-variant", func(Options) (Test, error) {
+		return NewRandomExcursionsVariantTest(), nil
+	})
+}
@@ -0,0 +1,81 @@
+package nist
+
+import (
+	"errors"
+	"testing"
+
+	b "github.com/notJoon/drbg/bitstream"
+)
+
+// constantTest is a third-party test always reporting the same p-value.
+type constantTest struct{ p float64 }
+
+func (constantTest) Name() string           { return "Constant Test" }
+func (constantTest) Section() string        { return "" }
+func (constantTest) Params() map[string]any { return nil }
+func (constantTest) MinLength() int         { return 0 }
+func (c constantTest) Run(bs *b.BitStream) (*Result, error) {
+	return singleResult(c, bs.Len(), c.p, 0, nil), nil
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	ids := IDs()
+	if len(ids) != 15 {
+		t.Fatalf("expected the 15 tests of SP 800-22, got %d: %v", len(ids), ids)
+	}
+
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		test, err := New(id, DefaultOptions())
+		if err != nil {
+			t.Fatalf("New(%q) error = %v", id, err)
+		}
+		if seen[test.Section()] {
+			t.Errorf("section %s registered twice", test.Section())
+		}
+		seen[test.Section()] = true
+	}
+
+	if _, err := New("no-such-test", DefaultOptions()); !errors.Is(err, ErrUnknownTest) {
+		t.Errorf("New() error = %v, expected %v", err, ErrUnknownTest)
+	}
+}
+
+func TestRegistryThirdParty(t *testing.T) {
+	r := NewRegistry()
+	factory := func(Options) (Test, error) { return constantTest{p: 0.5}, nil }
+
+	if err := r.Register("constant", factory); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := r.Register("constant", factory); !errors.Is(err, ErrDuplicateTest) {
+		t.Errorf("Register() error = %v, expected %v", err, ErrDuplicateTest)
+	}
+
+	test, err := r.New("constant", Options{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	res, err := test.Run(b.NewBitStream([]byte{0xAA}))
+	if err != nil || !res.Passed() || res.PValue() != 0.5 {
+		t.Errorf("Run() = %+v, %v", res, err)
+	}
+	if ids := r.IDs(); len(ids) != 1 || ids[0] != "constant" {
+		t.Errorf("IDs() = %v", ids)
+	}
+}
+
+func TestRegistryOverlappingDefaults(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Template = []uint8{0, 0, 0, 0, 0, 0, 0, 0, 1}
+	opts.TemplateM = 20
+
+	test, err := New("overlapping-template", opts)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	params := test.Params()
+	if params["B"] != "111111111" || params["M"] != uint64(1032) {
+		t.Errorf("Params() = %v, expected B=111111111 and M=1032", params)
+	}
+}
@@ -1,7 +1,6 @@
 package nist
 
 import (
-	"errors"
 	"math"
 
 	b "github.com/notJoon/drbg/bitstream"
@@ -18,41 +17,65 @@ import (
 //   - bool: True if the test passes (p-value >= 0.01), False otherwise.
 //   - error: Any error that occurred during the test, such as invalid input parameters.
 func Runs(bs *b.BitStream) (float64, bool, error) {
-	n := uint64(bs.Len())
-	pi := 0.0
-
-	// calculate the proportion of ones in the sequence
-	for i := 0; i < int(n); i++ {
-		bit, err := bs.Bit(i)
-		if err != nil {
-			return 0, false, err
-		}
-		pi += float64(bit)
+	res, err := NewRunsTest().Run(bs)
+	if err != nil {
+		return 0, false, err
 	}
-	pi /= float64(n)
+	return res.PValue(), res.Passed(), nil
+}
 
-	// determine if the prerequisite frequency test is passed
-	tau := 2.0 / math.Sqrt(float64(n))
-	if math.Abs(pi-0.5) >= tau {
-		return 0, false, errors.New("frequency test failed")
+type runsTest struct{}
+
+// NewRunsTest returns the Runs Test as a Test.
+func NewRunsTest() Test { return runsTest{} }
+
+func (runsTest) Name() string           { return "Runs Test" }
+func (runsTest) Section() string        { return "2.3" }
+func (runsTest) Params() map[string]any { return nil }
+func (runsTest) MinLength() int         { return 100 }
+
+func (t runsTest) Run(bs *b.BitStream) (*Result, error) {
+	return accumulate(t.NewAccumulator(), bs)
+}
+
+func (t runsTest) NewAccumulator() Accumulator {
+	return &runsAccumulator{t: t}
+}
+
+type runsAccumulator struct {
+	t       runsTest
+	n       uint64
+	ones    int64
+	V_n     float64 // the total number of runs
+	prevBit byte
+}
+
+func (a *runsAccumulator) AddBit(bit byte) {
+	if a.n == 0 || bit != a.prevBit {
+		a.V_n++
+		a.prevBit = bit
 	}
+	a.n++
+	a.ones += int64(bit)
+}
 
-	// compute the test statistic V_n
-	var prevBit byte
-	V_n := 1.0
-	if n > 0 {
-		firstBit, _ := bs.Bit(0)
-		prevBit = byte(firstBit)
+func (a *runsAccumulator) Result() (*Result, error) {
+	t, n, ones, V_n := a.t, a.n, a.ones, a.V_n
+	if n == 0 {
+		return nil, ErrEmptyBitStream
 	}
 
-	for i := 1; i < int(n); i++ {
-		currentBit, _ := bs.Bit(i)
-		if currentBit != prevBit {
-			V_n++
-			prevBit = currentBit
-		}
+	// calculate the proportion of ones in the sequence
+	pi := float64(ones) / float64(n)
+
+	// determine if the prerequisite frequency test is passed.
+	// If it is not, the runs test need not be performed and the p-value is 0,
+	// so that the sequence still counts as failing when many sequences are assessed.
+	tau := 2.0 / math.Sqrt(float64(n))
+	if math.Abs(pi-0.5) >= tau {
+		return singleResult(t, int(n), 0, 0, map[string]int64{"ones": ones}), nil
 	}
 
 	p_value := math.Erfc(math.Abs(V_n-2*float64(n)*pi*(1-pi)) / (2 * math.Sqrt(2.0*float64(n)) * pi * (1 - pi)))
-	return p_value, p_value >= 0.01, nil
+	return singleResult(t, int(n), p_value, V_n, map[string]int64{"ones": ones, "V_n": int64(V_n)}), nil
 }
@@ -1,63 +1,87 @@
 package nist
 
 import (
-	"bytes"
+	"fmt"
 	"math"
 
 	b "github.com/notJoon/drbg/bitstream"
 )
 
 func Serial(m uint64, bs *b.BitStream) ([]float64, []bool, error) {
-	n := uint64(bs.Len())
+	res, err := NewSerialTest(m).Run(bs)
+	if err != nil {
+		return nil, nil, err
+	}
+	pass := make([]bool, len(res.PValues))
+	for i := range pass {
+		pass[i] = res.Pass(i)
+	}
+	return res.PValues, pass, nil
+}
 
-	v := make([][]uint64, 3)
+type serialTest struct {
+	m uint64 // the length in bits of each block
+}
 
-	var section2_idx uint64
-	for section2_idx = 0; section2_idx <= 2; section2_idx++ {
-		if int64(m)-int64(section2_idx)-1 <= 0 {
-			break
-		}
+// NewSerialTest returns the Serial Test as a Test, using overlapping blocks of m bits.
+func NewSerialTest(m uint64) Test { return serialTest{m: m} }
 
-		appendedEpsilon := make([]uint8, n+m-section2_idx-1)
-		for i := uint64(0); i < n; i++ {
-			bit, err := bs.Bit(int(i))
-			if err != nil {
-				return nil, nil, err
-			}
-			appendedEpsilon[i] = bit
-		}
-		for i := uint64(0); i < m-section2_idx-1; i++ {
-			bit, err := bs.Bit(int(i))
-			if err != nil {
-				return nil, nil, err
-			}
-			appendedEpsilon[n+i] = bit
-		}
-		blockSize := m - section2_idx
-		v[section2_idx] = make([]uint64, uint64(math.Pow(2, float64(blockSize))))
-
-		// determine the frequency of all possible overlapping m-bit blocks
-		// the frequency of all possible overlapping m-bit blocks.
-		for blockIndex := uint64(0); blockIndex < n; blockIndex++ {
-			for vIndex := range v[section2_idx] {
-				arr1 := appendedEpsilon[blockIndex : blockIndex+blockSize]
-				arr2 := Uint_To_BitsArray_size_N(uint64(vIndex), blockSize)
-				if bytes.Equal(arr1, arr2) {
-					v[section2_idx][vIndex]++
-				}
-			}
-		}
+func (serialTest) Name() string    { return "Serial Test" }
+func (serialTest) Section() string { return "2.11" }
+func (t serialTest) Params() map[string]any {
+	return map[string]any{"m": t.m}
+}
+
+// MinLength returns the smallest n satisfying m < floor(log2 n) - 2.
+func (t serialTest) MinLength() int { return 1 << (t.m + 3) }
+
+func (t serialTest) Run(bs *b.BitStream) (*Result, error) {
+	return accumulate(t.NewAccumulator(), bs)
+}
+
+func (t serialTest) NewAccumulator() Accumulator {
+	if t.m > 32 {
+		return &serialAccumulator{t: t}
+	}
+	return &serialAccumulator{t: t, counter: newPatternCounter(t.m)}
+}
+
+type serialAccumulator struct {
+	t       serialTest
+	counter *patternCounter // nil if m is too large to count the patterns
+}
+
+func (a *serialAccumulator) AddBit(bit byte) {
+	if a.counter != nil {
+		a.counter.add(bit)
+	}
+}
+
+func (a *serialAccumulator) Result() (*Result, error) {
+	t, m := a.t, a.t.m
+	if m < 2 || a.counter == nil {
+		return nil, fmt.Errorf("invalid block size. got %d, should be between 2 and 32", m)
+	}
+	n := a.counter.n
+	if n == 0 {
+		return nil, ErrEmptyBitStream
+	}
+	if n < m {
+		return nil, fmt.Errorf("input sequence length should be at least m = %d bits, got %d", m, n)
 	}
 
+	// determine the frequency of all possible overlapping m-bit, (m-1)-bit and (m-2)-bit blocks
+	v := make([][]uint64, 3)
+	v[0] = a.counter.circularCounts()
+	v[1] = foldCounts(v[0])
+	v[2] = foldCounts(v[1])
+
 	// compute ψ
-	// // ψ_m = psi[0], ψ_{m-1} = psi[1], ψ_{m-2} = psi[2]
+	// ψ_m = psi[0], ψ_{m-1} = psi[1], ψ_{m-2} = psi[2]
 	psi := [3]float64{0, 0, 0}
 	for i := range psi {
-		if len(v[i]) == 0 {
-			break
-		}
 		for _, value := range v[i] {
-			psi[i] += math.Pow(float64(value), 2)
+			psi[i] += float64(value) * float64(value)
 		}
 		psi[i] = math.Pow(2, float64(m)-float64(i))/float64(n)*psi[i] - float64(n)
 	}
@@ -70,10 +94,13 @@ func Serial(m uint64, bs *b.BitStream) ([]float64, []bool, error) {
 	p1 := igamc(temp, delta1/2)
 	p2 := igamc(temp/2, delta2/2)
 
-	p_val := []float64{p1, p2}
-	pass := []bool{p1 >= 0.01, p2 >= 0.01}
-
-	return p_val, pass, nil
+	return &Result{
+		Name:       t.Name(),
+		N:          int(n),
+		PValues:    []float64{p1, p2},
+		Labels:     []string{"delta psi^2", "delta^2 psi^2"},
+		Statistics: []float64{delta1, delta2},
+	}, nil
 }
 
 func Uint_To_BitsArray_size_N(input uint64, N uint64) (bitArray []uint8) {
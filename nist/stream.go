@@ -0,0 +1,111 @@
+package nist
+
+import (
+	"io"
+
+	b "github.com/notJoon/drbg/bitstream"
+)
+
+// Accumulator consumes the bits of a sequence one at a time and computes the result
+// of a test once the whole sequence was seen. Its memory does not depend on the
+// length of the sequence.
+type Accumulator interface {
+	AddBit(bit byte)
+	Result() (*Result, error)
+}
+
+// StreamTest is a Test that can be computed in a single pass over the sequence,
+// so that sequences larger than the memory can be tested.
+type StreamTest interface {
+	Test
+	NewAccumulator() Accumulator
+}
+
+// RunStream runs the test on the bits read from src until io.EOF.
+func RunStream(t StreamTest, src b.BitSource) (*Result, error) {
+	acc := t.NewAccumulator()
+	if _, err := Accumulate(src, acc); err != nil {
+		return nil, err
+	}
+	return acc.Result()
+}
+
+// Accumulate feeds every bit read from src until io.EOF to each accumulator,
+// so that many tests are computed in a single pass. It returns the number of bits read.
+func Accumulate(src b.BitSource, accs ...Accumulator) (int, error) {
+	n := 0
+	for {
+		bit, err := src.ReadBit()
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		for _, acc := range accs {
+			acc.AddBit(bit)
+		}
+		n++
+	}
+}
+
+// accumulate feeds the bits of bs to acc and returns its result.
+// It lets the tests implementing StreamTest share their code with Run.
+func accumulate(acc Accumulator, bs *b.BitStream) (*Result, error) {
+	for i := 0; i < bs.Len(); i++ {
+		bit, err := bs.Bit(i)
+		if err != nil {
+			return nil, err
+		}
+		acc.AddBit(bit)
+	}
+	return acc.Result()
+}
+
+// patternCounter counts the overlapping m-bit patterns of a sequence, as if the
+// first m-1 bits were appended to its end (the sequence is taken as circular).
+type patternCounter struct {
+	m      uint64
+	n      uint64   // number of bits seen
+	window uint64   // the last m bits
+	head   uint64   // the first m-1 bits
+	counts []uint64 // counts[p] is the number of occurrences of the pattern p
+}
+
+func newPatternCounter(m uint64) *patternCounter {
+	return &patternCounter{m: m, counts: make([]uint64, 1<<m)}
+}
+
+func (c *patternCounter) add(bit byte) {
+	c.window = (c.window<<1 | uint64(bit)) & (1<<c.m - 1)
+	c.n++
+	if c.n < c.m {
+		c.head = c.window
+	} else {
+		c.counts[c.window]++
+	}
+}
+
+// circularCounts returns the counts of the n overlapping patterns of the circular sequence.
+// It needs at least m bits.
+func (c *patternCounter) circularCounts() []uint64 {
+	counts := make([]uint64, len(c.counts))
+	copy(counts, c.counts)
+
+	window := c.window
+	for i := int(c.m) - 2; i >= 0; i-- {
+		window = (window<<1 | (c.head>>uint(i))&1) & (1<<c.m - 1)
+		counts[window]++
+	}
+	return counts
+}
+
+// foldCounts returns the counts of the patterns one bit shorter: each pattern
+// p of length m-1 is the prefix of the patterns 2p and 2p+1.
+func foldCounts(counts []uint64) []uint64 {
+	folded := make([]uint64, len(counts)/2)
+	for p := range folded {
+		folded[p] = counts[2*p] + counts[2*p+1]
+	}
+	return folded
+}
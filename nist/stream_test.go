@@ -0,0 +1,109 @@
+package nist
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	b "github.com/notJoon/drbg/bitstream"
+)
+
+func streamTests() []StreamTest {
+	return []StreamTest{
+		NewFrequencyTest().(StreamTest),
+		NewBlockFrequencyTest(400).(StreamTest),
+		NewRunsTest().(StreamTest),
+		NewLongestRunOfOnesTest().(StreamTest),
+		NewSerialTest(5).(StreamTest),
+		NewApproximateEntropyTest(4).(StreamTest),
+		NewCumulativeSumsTest(CusumBoth).(StreamTest),
+	}
+}
+
+func TestRunStream(t *testing.T) {
+	data := xorshiftBytes(4096)
+	bs := b.NewBitStream(data)
+
+	for _, test := range streamTests() {
+		t.Run(test.Name(), func(t *testing.T) {
+			want, err := test.Run(bs)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			src, err := b.NewStreamReader(bytes.NewReader(data), b.FormatRaw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := RunStream(test, src)
+			if err != nil {
+				t.Fatalf("RunStream() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("RunStream() = %+v, Run() = %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestAccumulate(t *testing.T) {
+	data := xorshiftBytes(1024)
+	src, err := b.NewStreamReader(bytes.NewReader(data), b.FormatRaw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := streamTests()
+	accs := make([]Accumulator, len(tests))
+	for i, test := range tests {
+		accs[i] = test.NewAccumulator()
+	}
+	n, err := Accumulate(src, accs...)
+	if err != nil || n != len(data)*8 {
+		t.Fatalf("Accumulate() = %d, %v, want %d bits", n, err, len(data)*8)
+	}
+
+	bs := b.NewBitStream(data)
+	for i, test := range tests {
+		want, _ := test.Run(bs)
+		got, _ := accs[i].Result()
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: accumulated %+v, want %+v", test.Name(), got, want)
+		}
+	}
+}
+
+func TestPatternCounter(t *testing.T) {
+	bits := []byte{0, 1, 1, 0, 1, 0, 0, 0, 1, 1, 1}
+	for m := uint64(1); m <= 4; m++ {
+		c := newPatternCounter(m)
+		for _, bit := range bits {
+			c.add(bit)
+		}
+
+		// count the patterns of the sequence with its first m-1 bits appended
+		want := make([]uint64, 1<<m)
+		n := len(bits)
+		for i := 0; i < n; i++ {
+			p := 0
+			for j := 0; j < int(m); j++ {
+				p = p<<1 | int(bits[(i+j)%n])
+			}
+			want[p]++
+		}
+
+		got := c.circularCounts()
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("m = %d: circularCounts() = %v, want %v", m, got, want)
+		}
+		if m > 1 {
+			shorter := newPatternCounter(m - 1)
+			for _, bit := range bits {
+				shorter.add(bit)
+			}
+			if folded := foldCounts(got); !reflect.DeepEqual(folded, shorter.circularCounts()) {
+				t.Errorf("m = %d: foldCounts() = %v, want %v", m, folded, shorter.circularCounts())
+			}
+		}
+	}
+}
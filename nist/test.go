@@ -0,0 +1,83 @@
+package nist
+
+import (
+	b "github.com/notJoon/drbg/bitstream"
+)
+
+// Alpha is the significance level used by every test of the suite.
+// A p-value greater than or equal to Alpha is considered random.
+const Alpha = 0.01
+
+// Test is the common interface implemented by every statistical test of the suite.
+type Test interface {
+	// Name returns the human readable name of the test (e.g. "Frequency (Monobit) Test").
+	Name() string
+	// Section returns the section of SP 800-22 that describes the test (e.g. "2.1").
+	Section() string
+	// Params returns the parameters the test runs with, keyed by the symbol used in SP 800-22.
+	Params() map[string]any
+	// MinLength returns the minimum number of bits recommended by SP 800-22 for the test.
+	MinLength() int
+	// Run performs the test on the given bitstream.
+	Run(bs *b.BitStream) (*Result, error)
+}
+
+// Result holds the outcome of a single test run.
+//
+// Tests that produce more than one p-value (e.g. Serial, Random Excursions)
+// report one entry per sub-test in PValues, with the matching entry of Labels
+// naming the sub-test. Single-valued tests leave Labels empty.
+type Result struct {
+	Name       string           // name of the test that produced the result
+	N          int              // number of bits tested
+	PValues    []float64        // p-value of each sub-test
+	Labels     []string         // label of each sub-test, parallel to PValues
+	Statistics []float64        // test statistic of each sub-test, parallel to PValues
+	Counts     map[string]int64 // intermediate counts used to compute the statistics
+}
+
+// PValue returns the p-value of the first sub-test.
+func (r *Result) PValue() float64 {
+	if len(r.PValues) == 0 {
+		return 0
+	}
+	return r.PValues[0]
+}
+
+// Pass reports whether the i-th sub-test passed.
+func (r *Result) Pass(i int) bool {
+	return r.PValues[i] >= Alpha
+}
+
+// Passed reports whether every sub-test passed.
+func (r *Result) Passed() bool {
+	if len(r.PValues) == 0 {
+		return false
+	}
+	for i := range r.PValues {
+		if !r.Pass(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// Label returns the label of the i-th sub-test, or the test name when the
+// result has no sub-tests.
+func (r *Result) Label(i int) string {
+	if i < len(r.Labels) && r.Labels[i] != "" {
+		return r.Labels[i]
+	}
+	return r.Name
+}
+
+// singleResult builds a Result holding a single p-value.
+func singleResult(t Test, n int, pValue, statistic float64, counts map[string]int64) *Result {
+	return &Result{
+		Name:       t.Name(),
+		N:          n,
+		PValues:    []float64{pValue},
+		Statistics: []float64{statistic},
+		Counts:     counts,
+	}
+}
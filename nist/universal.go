@@ -1,15 +1,62 @@
 package nist
 
 import (
+	"fmt"
 	"math"
 
 	b "github.com/notJoon/drbg/bitstream"
 )
 
 func UniversalRecommendedValues(bs *b.BitStream) (float64, bool, error) {
+	res, err := NewUniversalTest(0, 0).Run(bs)
+	if err != nil {
+		return 0, false, err
+	}
+	return res.PValue(), res.Passed(), nil
+}
+
+// minUniversalLength is the smallest input for which SP 800-22 recommends a block length (L = 6).
+const minUniversalLength = 387840
+
+type universalTest struct {
+	L uint64 // the length of each block
+	Q uint64 // the number of blocks in the initialization segment
+}
+
+// NewUniversalTest returns Maurer's "Universal Statistical" Test as a Test.
+// If L or Q is zero, the values recommended by SP 800-22 for the input length are used.
+func NewUniversalTest(L, Q uint64) Test { return universalTest{L: L, Q: Q} }
+
+func (universalTest) Name() string    { return "Maurer's Universal Statistical Test" }
+func (universalTest) Section() string { return "2.9" }
+func (t universalTest) Params() map[string]any {
+	if t.L == 0 || t.Q == 0 {
+		return nil
+	}
+	return map[string]any{"L": t.L, "Q": t.Q}
+}
+func (universalTest) MinLength() int { return minUniversalLength }
+
+func (t universalTest) Run(bs *b.BitStream) (*Result, error) {
 	n := uint64(bs.Len())
-	L, Q := recommandedInputSize(n)
-	return Universal(L, Q, n, bs)
+	L, Q := t.L, t.Q
+	if L == 0 || Q == 0 {
+		if n < minUniversalLength {
+			return nil, fmt.Errorf("input sequence length should be at least %d bits, got %d", minUniversalLength, n)
+		}
+		L, Q = recommandedInputSize(n)
+	}
+	if L < 1 || L > 16 || n/L <= Q {
+		return nil, fmt.Errorf("invalid parameters for %d bits: L=%d, Q=%d", n, L, Q)
+	}
+
+	p_value, f_n, err := universal(L, Q, n, bs)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int64{"L": int64(L), "Q": int64(Q), "K": int64(n/L - Q)}
+	return singleResult(t, int(n), p_value, f_n, counts), nil
 }
 
 func recommandedInputSize(n uint64) (L uint64, Q uint64) {
@@ -69,6 +116,15 @@ func array2BinaryInt(arr []uint8) uint64 {
 // n >= (Q + K) * L
 // 6 <= L <= 16, Q = 10 * 2^L, k = floor(n/L) - Q ~= 1000 * 2^L
 func Universal(L uint64, Q uint64, n uint64, bs *b.BitStream) (float64, bool, error) {
+	p_value, _, err := universal(L, Q, n, bs)
+	if err != nil {
+		return 0, false, err
+	}
+	return p_value, p_value >= Alpha, nil
+}
+
+// universal computes the p-value and the test statistic f_n of the Universal test.
+func universal(L uint64, Q uint64, n uint64, bs *b.BitStream) (float64, float64, error) {
 	expectedValue_mu := [16]float64{0.7326495, 1.5374383, 2.4016068, 3.3112247, 4.2534266, 5.2177052, 6.1962507, 7.1836656, 8.1764248, 9.1723243, 10.170032, 11.168765, 12.168070, 13.167693, 14.167488, 15.167379}
 	variance_sigma := [16]float64{0.690, 1.338, 1.901, 2.358, 2.705, 2.954, 3.125, 3.238, 3.311, 3.356, 3.384, 3.401, 3.410, 3.416, 3.419, 3.421}
 
@@ -76,7 +132,7 @@ func Universal(L uint64, Q uint64, n uint64, bs *b.BitStream) (float64, bool, er
 	_float64_Q := float64(Q)
 
 	blocks := make([][]uint8, 0, Q+K)
-	T := make([]float64, Q)
+	T := make([]float64, 1<<L) // block number of the last occurrence of each L-bit value
 
 	// Divide into L-bits
 	var blockNum uint64 = 0
@@ -85,7 +141,7 @@ func Universal(L uint64, Q uint64, n uint64, bs *b.BitStream) (float64, bool, er
 		for i := uint64(0); i < L; i++ {
 			bit, err := bs.Bit(int(blockNum*L + i))
 			if err != nil {
-				return 0, false, err
+				return 0, 0, err
 			}
 			block[i] = bit
 		}
@@ -116,8 +172,12 @@ func Universal(L uint64, Q uint64, n uint64, bs *b.BitStream) (float64, bool, er
 	// (4) Compute the test statistic
 	var f_n float64 = sum / float64(K)
 
-	// (5) Compute P-value
-	var P_value float64 = math.Erfc(math.Abs((f_n - expectedValue_mu[L-1]) / (math.Sqrt2 * variance_sigma[L-1])))
+	// (5) Compute P-value. The standard deviation of f_n is c * sqrt(variance / K),
+	// where c accounts for the dependence between the blocks.
+	_float64_L, _float64_K := float64(L), float64(K)
+	c := 0.7 - 0.8/_float64_L + (4+32/_float64_L)*math.Pow(_float64_K, -3/_float64_L)/15
+	sigma := c * math.Sqrt(variance_sigma[L-1]/_float64_K)
+	var P_value float64 = math.Erfc(math.Abs((f_n - expectedValue_mu[L-1]) / (math.Sqrt2 * sigma)))
 
-	return P_value, P_value >= 0.01, nil
+	return P_value, f_n, nil
 }
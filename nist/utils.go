@@ -29,7 +29,8 @@ func igam(a, x float64) float64 {
 	tempLgam, _ := math.Lgamma(a)
 	ax = a*math.Log(x) - x - tempLgam
 	if ax < -MAXLOG {
-		panic("igam: Underflow")
+		// underflow: the result is too small to be represented (as in cephes)
+		return 0.0
 	}
 	ax = math.Exp(ax)
 
@@ -51,6 +52,12 @@ func igam(a, x float64) float64 {
 	return res * ax / a
 }
 
+// Igamc is the complemented incomplete gamma function Q(a, x). The p-value of a χ² statistic
+// with df degrees of freedom is Igamc(df/2, χ²/2).
+func Igamc(a, x float64) float64 {
+	return igamc(a, x)
+}
+
 func igamc(a, x float64) float64 {
 	if x <= 0 || a <= 0 {
 		return 1.0
@@ -62,7 +69,8 @@ func igamc(a, x float64) float64 {
 	tempLgam, _ := math.Lgamma(a)
 	ax := a*math.Log(x) - x - tempLgam
 	if ax < -MAXLOG {
-		panic("igamc: Underflow")
+		// underflow: the p-value is too small to be represented (as in cephes)
+		return 0.0
 	}
 	ax = math.Exp(ax)
 
@@ -108,3 +116,12 @@ func igamc(a, x float64) float64 {
 
 	return ans * ax
 }
+
+// templateString renders a template of bits as a string of ones and zeros (e.g. "001").
+func templateString(B []uint8) string {
+	s := make([]byte, len(B))
+	for i, bit := range B {
+		s[i] = '0' + bit
+	}
+	return string(s)
+}
@@ -0,0 +1,92 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// csvHeader lists the columns written by WriteCSV. Columns that do not apply
+// to a row (e.g. the assessment columns of a single sequence run) are left empty.
+var csvHeader = []string{
+	"id", "name", "section", "label", "status", "bits", "sequences",
+	"p_value", "statistic",
+	"passes", "proportion", "proportion_min", "proportion_max", "uniformity",
+	"c1", "c2", "c3", "c4", "c5", "c6", "c7", "c8", "c9", "c10",
+	"params", "message",
+}
+
+// WriteCSV writes the report as CSV, one row per sub-test. Skipped and
+// erroneous tests are written as a single row with an empty label.
+func WriteCSV(w io.Writer, r *Report) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, tr := range r.Tests {
+		params := formatParams(tr.Params)
+		if len(tr.SubTests) == 0 {
+			row := make([]string, len(csvHeader))
+			copy(row, []string{tr.ID, tr.Name, tr.Section, "", string(tr.Status), strconv.Itoa(r.Bits), strconv.Itoa(r.Sequences)})
+			row[len(row)-2] = params
+			row[len(row)-1] = tr.Message
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, sub := range tr.SubTests {
+			row := []string{tr.ID, tr.Name, tr.Section, sub.Label, string(sub.Status), strconv.Itoa(r.Bits), strconv.Itoa(r.Sequences)}
+			row = append(row, formatFloat(sub.PValue), formatFloat(sub.Statistic))
+			if a := sub.Assessment; a != nil {
+				row = append(row,
+					strconv.Itoa(a.Passes),
+					formatFloat(&a.Proportion),
+					formatFloat(&a.ProportionMin),
+					formatFloat(&a.ProportionMax),
+					formatFloat(&a.Uniformity),
+				)
+				for _, count := range a.Histogram {
+					row = append(row, strconv.Itoa(count))
+				}
+			} else {
+				row = append(row, make([]string, 15)...)
+			}
+			row = append(row, params, tr.Message)
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatFloat formats a value at full precision, or as an empty string if it is not set.
+func formatFloat(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'g', -1, 64)
+}
+
+// formatParams formats the parameters of a test as "key=value" pairs sorted by key.
+func formatParams(params map[string]any) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf("%s=%v", k, params[k])
+	}
+	return strings.Join(pairs, ";")
+}
@@ -0,0 +1,143 @@
+// Package report renders the results of the statistical tests in formats
+// understood by other tools.
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	nist "github.com/notJoon/drbg/nist"
+)
+
+// stsNames maps the name of each SP 800-22 test to the name used by the NIST
+// Statistical Test Suite (sts-2.1.2), in the order its assess tool reports them.
+var stsNames = []struct {
+	name string
+	sts  string
+}{
+	{"Frequency (Monobit) Test", "Frequency"},
+	{"Frequency Test within a Block", "BlockFrequency"},
+	{"Cumulative Sums Test", "CumulativeSums"},
+	{"Runs Test", "Runs"},
+	{"Test for the Longest Run of Ones in a Block", "LongestRun"},
+	{"Binary Matrix Rank Test", "Rank"},
+	{"Discrete Fourier Transform (Spectral) Test", "FFT"},
+	{"Non-overlapping Template Matching Test", "NonOverlappingTemplate"},
+	{"Overlapping Template Matching Test", "OverlappingTemplate"},
+	{"Maurer's Universal Statistical Test", "Universal"},
+	{"Approximate Entropy Test", "ApproximateEntropy"},
+	{"Random Excursions Test", "RandomExcursions"},
+	{"Random Excursions Variant Test", "RandomExcursionsVariant"},
+	{"Serial Test", "Serial"},
+	{"Linear Complexity Test", "LinearComplexity"},
+}
+
+// stsName returns the sts-2.1.2 name of a test and its position in the report.
+// Tests unknown to the reference suite keep their name and are reported last.
+func stsName(name string) (string, int) {
+	for i, n := range stsNames {
+		if n.name == name {
+			return n.sts, i
+		}
+	}
+	return name, len(stsNames)
+}
+
+// isRandomExcursions reports whether a test belongs to the random excursion family,
+// whose sample size only counts the sequences the test applies to.
+func isRandomExcursions(name string) bool {
+	return name == "Random Excursions Test" || name == "Random Excursions Variant Test"
+}
+
+const (
+	finalAnalysisRule   = "------------------------------------------------------------------------------"
+	finalAnalysisDashes = "- - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -"
+)
+
+// WriteFinalAnalysis writes the assessments in the layout of the finalAnalysisReport.txt
+// file of the NIST Statistical Test Suite: the C1..C10 histogram of the p-values, the
+// P-value of their uniformity, the proportion of passing sequences and the name of the
+// test, one row per sub-test. Values out of their acceptable range are marked with '*'.
+//
+// The generator is the name of the tested source shown in the header.
+func WriteFinalAnalysis(w io.Writer, generator string, assessments []*nist.Assessment) error {
+	bw := bufio.NewWriter(w)
+
+	rows := make([]*nist.Assessment, len(assessments))
+	copy(rows, assessments)
+	sort.SliceStable(rows, func(i, j int) bool {
+		_, a := stsName(rows[i].Name)
+		_, b := stsName(rows[j].Name)
+		return a < b
+	})
+
+	fmt.Fprintln(bw, finalAnalysisRule)
+	fmt.Fprintln(bw, "RESULTS FOR THE UNIFORMITY OF P-VALUES AND THE PROPORTION OF PASSING SEQUENCES")
+	fmt.Fprintln(bw, finalAnalysisRule)
+	fmt.Fprintf(bw, "   generator is <%s>\n", generator)
+	fmt.Fprintln(bw, finalAnalysisRule)
+	fmt.Fprintln(bw, " C1  C2  C3  C4  C5  C6  C7  C8  C9 C10  P-VALUE  PROPORTION  STATISTICAL TEST")
+	fmt.Fprintln(bw, finalAnalysisRule)
+
+	sampleSize, excursionsSampleSize := 0, 0
+	for _, a := range rows {
+		name, _ := stsName(a.Name)
+		sequences := a.Sequences()
+		if isRandomExcursions(a.Name) {
+			excursionsSampleSize = max(excursionsSampleSize, sequences)
+		} else {
+			sampleSize = max(sampleSize, sequences)
+		}
+
+		for _, count := range a.Histogram {
+			fmt.Fprintf(bw, "%3d ", count)
+		}
+
+		// as in the reference suite, the uniformity is shown once the expected
+		// count of each interval is at least 1
+		switch {
+		case sequences < len(a.Histogram):
+			fmt.Fprint(bw, "    ----    ")
+		case a.Uniformity < nist.UniformityAlpha:
+			fmt.Fprintf(bw, " %8.6f * ", a.Uniformity)
+		default:
+			fmt.Fprintf(bw, " %8.6f   ", a.Uniformity)
+		}
+
+		switch {
+		case sequences == 0:
+			fmt.Fprintf(bw, " ------     %s\n", name)
+		case !a.ProportionOK():
+			fmt.Fprintf(bw, "%4d/%-4d *  %s\n", a.Passes, sequences, name)
+		default:
+			fmt.Fprintf(bw, "%4d/%-4d    %s\n", a.Passes, sequences, name)
+		}
+	}
+
+	fmt.Fprintf(bw, "\n\n%s\n", finalAnalysisDashes)
+	if sampleSize > 0 {
+		fmt.Fprintln(bw, "The minimum pass rate for each statistical test with the exception of the")
+		fmt.Fprintf(bw, "random excursion (variant) test is approximately = %d for a\n", minimumPassRate(sampleSize))
+		fmt.Fprintf(bw, "sample size = %d binary sequences.\n\n", sampleSize)
+	}
+	if excursionsSampleSize > 0 {
+		fmt.Fprintln(bw, "The minimum pass rate for the random excursion (variant) test")
+		fmt.Fprintf(bw, "is approximately = %d for a sample size = %d binary sequences.\n\n", minimumPassRate(excursionsSampleSize), excursionsSampleSize)
+	}
+	fmt.Fprintln(bw, "For further guidelines construct a probability table using the MAPLE program")
+	fmt.Fprintln(bw, "provided in the addendum section of the documentation.")
+	fmt.Fprintln(bw, finalAnalysisDashes)
+
+	return bw.Flush()
+}
+
+// minimumPassRate returns the smallest number of passing sequences within the
+// confidence interval, truncated as in the reference suite.
+func minimumPassRate(sampleSize int) int {
+	pHat := 1 - nist.Alpha
+	m := float64(sampleSize)
+	return int((pHat - 3*math.Sqrt(pHat*nist.Alpha/m)) * m)
+}
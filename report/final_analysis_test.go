@@ -0,0 +1,114 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	b "github.com/notJoon/drbg/bitstream"
+	nist "github.com/notJoon/drbg/nist"
+)
+
+// results builds one result per sequence, with the i-th p-value of each sub-test
+// taken from pValues[i].
+func results(name string, labels []string, pValues ...[]float64) []*nist.Result {
+	res := make([]*nist.Result, len(pValues))
+	for i, p := range pValues {
+		res[i] = &nist.Result{Name: name, PValues: p, Labels: labels}
+	}
+	return res
+}
+
+func TestWriteFinalAnalysis(t *testing.T) {
+	var serial, frequency [][]float64
+	for i := 0; i < 100; i++ {
+		p := (float64(i) + 0.5) / 100
+		serial = append(serial, []float64{p, 0.05})
+		frequency = append(frequency, []float64{p})
+	}
+
+	var assessments []*nist.Assessment
+	assessments = append(assessments, nist.AssessResults("Serial Test", results("Serial Test", []string{"delta psi^2", "delta^2 psi^2"}, serial...))...)
+	assessments = append(assessments, nist.AssessResults("Frequency (Monobit) Test", results("Frequency (Monobit) Test", nil, frequency...))...)
+
+	var buf bytes.Buffer
+	if err := WriteFinalAnalysis(&buf, "data/data.e", assessments); err != nil {
+		t.Fatalf("WriteFinalAnalysis() error = %v", err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+
+	expected := []string{
+		"------------------------------------------------------------------------------",
+		"RESULTS FOR THE UNIFORMITY OF P-VALUES AND THE PROPORTION OF PASSING SEQUENCES",
+		"------------------------------------------------------------------------------",
+		"   generator is <data/data.e>",
+		"------------------------------------------------------------------------------",
+		" C1  C2  C3  C4  C5  C6  C7  C8  C9 C10  P-VALUE  PROPORTION  STATISTICAL TEST",
+		"------------------------------------------------------------------------------",
+		// Frequency is reported before Serial, as in the reference suite
+		" 10  10  10  10  10  10  10  10  10  10  1.000000     99/100     Frequency",
+		" 10  10  10  10  10  10  10  10  10  10  1.000000     99/100     Serial",
+		"100   0   0   0   0   0   0   0   0   0  0.000000 *  100/100     Serial",
+		"",
+		"",
+		"- - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -",
+		"The minimum pass rate for each statistical test with the exception of the",
+		"random excursion (variant) test is approximately = 96 for a",
+		"sample size = 100 binary sequences.",
+	}
+	for i, want := range expected {
+		if i >= len(lines) || lines[i] != want {
+			t.Fatalf("line %d:\n got: %q\nwant: %q", i, lines[i], want)
+		}
+	}
+}
+
+func TestWriteFinalAnalysisExcursions(t *testing.T) {
+	// the random walk of 0101... returns to zero every 2 bits, that of 11...1 never does:
+	// the random excursion tests do not apply to the last 2 sequences
+	var seqs []*b.BitStream
+	for i := 0; i < 5; i++ {
+		seqs = append(seqs, b.NewBitStream(bytes.Repeat([]byte{0x55}, 250)))
+	}
+	for i := 0; i < 2; i++ {
+		seqs = append(seqs, b.NewBitStream(bytes.Repeat([]byte{0xFF}, 250)))
+	}
+
+	var assessments []*nist.Assessment
+	for _, test := range []nist.Test{nist.NewFrequencyTest(), nist.NewRandomExcursionsTest(), nist.NewRandomExcursionsVariantTest()} {
+		a, err := nist.Assess(test, seqs)
+		if err != nil {
+			t.Fatalf("Assess(%s) error = %v", test.Name(), err)
+		}
+		assessments = append(assessments, a...)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteFinalAnalysis(&buf, "data/data.e", assessments); err != nil {
+		t.Fatalf("WriteFinalAnalysis() error = %v", err)
+	}
+	out := buf.String()
+
+	excursionRows := 0
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasSuffix(line, "RandomExcursions") || strings.HasSuffix(line, "RandomExcursionsVariant") {
+			excursionRows++
+			if !strings.Contains(line, "/5 ") {
+				t.Errorf("%q: expected a proportion over 5 sequences", line)
+			}
+		}
+	}
+	if excursionRows != 8+18 {
+		t.Errorf("got %d random excursion rows, expected 26", excursionRows)
+	}
+
+	for _, want := range []string{
+		"  2   0   0   0   0   0   0   0   0   5     ----       5/7    *  Frequency\n",
+		"random excursion (variant) test is approximately = 6 for a\nsample size = 7 binary sequences.\n",
+		"is approximately = 4 for a sample size = 5 binary sequences.\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	nist "github.com/notJoon/drbg/nist"
+)
+
+// The elements below follow the JUnit XML layout understood by most CI servers.
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as a JUnit XML document with one testsuite for the
+// source. Every sub-test (e.g. each state of the Random Excursions Test) is a testcase
+// whose classname is the id of its test. Skipped and erroneous tests are a single
+// testcase explaining why they were not run.
+func WriteJUnit(w io.Writer, r *Report) error {
+	suite := junitTestSuite{
+		Name: r.Source,
+		Properties: []junitProperty{
+			{Name: "bits", Value: fmt.Sprint(r.Bits)},
+			{Name: "sequences", Value: fmt.Sprint(r.Sequences)},
+			{Name: "alpha", Value: fmt.Sprint(r.Alpha)},
+		},
+	}
+
+	for _, tr := range r.Tests {
+		switch tr.Status {
+		case StatusSkipped:
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      tr.Name,
+				ClassName: tr.ID,
+				Skipped:   &junitMessage{Message: tr.Message},
+			})
+			suite.Skipped++
+			continue
+		case StatusError:
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      tr.Name,
+				ClassName: tr.ID,
+				Error:     &junitMessage{Message: tr.Message, Type: "error"},
+			})
+			suite.Errors++
+			continue
+		}
+
+		for _, sub := range tr.SubTests {
+			tc := junitTestCase{Name: sub.Label, ClassName: tr.ID}
+			if sub.Label != tr.Name {
+				tc.Name = fmt.Sprintf("%s (%s)", tr.Name, sub.Label)
+			}
+			if sub.Status != StatusPass {
+				tc.Failure = &junitMessage{
+					Message: failureMessage(sub, r.Alpha),
+					Type:    "randomness",
+					Text:    fmt.Sprintf("parameters: %s", formatParams(tr.Params)),
+				}
+				suite.Failures++
+			}
+			suite.Cases = append(suite.Cases, tc)
+		}
+	}
+	suite.Tests = len(suite.Cases)
+
+	doc := junitTestSuites{
+		Name:     "NIST SP 800-22",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Skipped:  suite.Skipped,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// failureMessage explains why a sub-test failed, with the values compared to their thresholds.
+func failureMessage(sub *SubTest, alpha float64) string {
+	a := sub.Assessment
+	if a == nil {
+		return fmt.Sprintf("p-value %.6f < %g", *sub.PValue, alpha)
+	}
+
+	var reasons []string
+	if a.Proportion < a.ProportionMin || a.Proportion > a.ProportionMax {
+		reasons = append(reasons, fmt.Sprintf("proportion %d/%d = %.4f outside [%.4f, %.4f]",
+			a.Passes, a.Sequences, a.Proportion, a.ProportionMin, a.ProportionMax))
+	}
+	if a.Sequences >= nist.MinUniformitySequences && a.Uniformity < nist.UniformityAlpha {
+		reasons = append(reasons, fmt.Sprintf("uniformity P-value_T %.6f < %g", a.Uniformity, nist.UniformityAlpha))
+	}
+	return strings.Join(reasons, "; ")
+}
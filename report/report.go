@@ -0,0 +1,201 @@
+package report
+
+import (
+	"errors"
+	"math"
+
+	nist "github.com/notJoon/drbg/nist"
+)
+
+// SchemaVersion is the version of the layout of Report. It is bumped
+// whenever a field is renamed or removed.
+const SchemaVersion = 1
+
+// Status is the outcome of a test or sub-test.
+type Status string
+
+const (
+	StatusPass    Status = "pass"
+	StatusFail    Status = "fail"
+	StatusSkipped Status = "skipped" // the input is too short for the test, or the test does not apply to it
+	StatusError   Status = "error"   // the test could not be run
+)
+
+// Report collects the outcome of every test run on a source.
+type Report struct {
+	Schema    int           `json:"schema"`
+	Source    string        `json:"source"`    // name of the tested input
+	Bits      int           `json:"bits"`      // number of bits of each tested sequence
+	Sequences int           `json:"sequences"` // number of sequences, 1 unless a generator is assessed
+	Alpha     float64       `json:"alpha"`     // significance level of the p-values
+	Tests     []*TestReport `json:"tests"`
+}
+
+// TestReport is the outcome of a single test.
+type TestReport struct {
+	ID        string         `json:"id"`
+	Name      string         `json:"name"`
+	Section   string         `json:"section"`
+	Params    map[string]any `json:"params"`
+	MinLength int            `json:"min_length"`
+	Status    Status         `json:"status"`
+	Message   string         `json:"message,omitempty"` // reason of a skip or an error
+	SubTests  []*SubTest     `json:"subtests"`
+}
+
+// SubTest is the outcome of one p-value of a test.
+//
+// When a single sequence is tested, PValue and Statistic are set. When many
+// sequences are assessed (SP 800-22 section 4.2), Assessment is set instead.
+type SubTest struct {
+	Label      string      `json:"label"`
+	Status     Status      `json:"status"`
+	PValue     *float64    `json:"p_value,omitempty"`
+	Statistic  *float64    `json:"statistic,omitempty"`
+	Assessment *Assessment `json:"assessment,omitempty"`
+}
+
+// Assessment holds the second-level statistics of a sub-test run over many sequences.
+type Assessment struct {
+	Sequences     int     `json:"sequences"`
+	Passes        int     `json:"passes"`
+	Proportion    float64 `json:"proportion"`
+	ProportionMin float64 `json:"proportion_min"`
+	ProportionMax float64 `json:"proportion_max"`
+	Uniformity    float64 `json:"uniformity"`
+	Histogram     [10]int `json:"histogram"`
+}
+
+// New creates an empty report for sequences of the given number of bits.
+func New(source string, bits, sequences int) *Report {
+	return &Report{
+		Schema:    SchemaVersion,
+		Source:    source,
+		Bits:      bits,
+		Sequences: sequences,
+		Alpha:     nist.Alpha,
+	}
+}
+
+// newTestReport describes the test without any outcome.
+func newTestReport(id string, test nist.Test) *TestReport {
+	params := test.Params()
+	if params == nil {
+		params = map[string]any{}
+	}
+	return &TestReport{
+		ID:        id,
+		Name:      test.Name(),
+		Section:   test.Section(),
+		Params:    params,
+		MinLength: test.MinLength(),
+		SubTests:  []*SubTest{},
+	}
+}
+
+// AddResult records the result of the test registered under id.
+func (r *Report) AddResult(id string, test nist.Test, res *nist.Result) *TestReport {
+	tr := newTestReport(id, test)
+	tr.Status = StatusPass
+
+	for i, p := range res.PValues {
+		if math.IsNaN(p) {
+			tr.Status = StatusError
+			tr.Message = "p-value is not a number"
+			tr.SubTests = []*SubTest{}
+			break
+		}
+
+		sub := &SubTest{Label: res.Label(i), Status: StatusPass, PValue: &res.PValues[i]}
+		if i < len(res.Statistics) && !math.IsNaN(res.Statistics[i]) && !math.IsInf(res.Statistics[i], 0) {
+			sub.Statistic = &res.Statistics[i]
+		}
+		if !res.Pass(i) {
+			sub.Status = StatusFail
+			tr.Status = StatusFail
+		}
+		tr.SubTests = append(tr.SubTests, sub)
+	}
+
+	r.Tests = append(r.Tests, tr)
+	return tr
+}
+
+// AddAssessments records the assessments of the test registered under id over many sequences.
+func (r *Report) AddAssessments(id string, test nist.Test, assessments []*nist.Assessment) *TestReport {
+	tr := newTestReport(id, test)
+	tr.Status = StatusPass
+
+	for _, a := range assessments {
+		label := a.Label
+		if label == "" {
+			label = a.Name
+		}
+		sub := &SubTest{
+			Label:  label,
+			Status: StatusPass,
+			Assessment: &Assessment{
+				Sequences:     a.Sequences(),
+				Passes:        a.Passes,
+				Proportion:    a.Proportion,
+				ProportionMin: a.ProportionMin,
+				ProportionMax: a.ProportionMax,
+				Uniformity:    a.Uniformity,
+				Histogram:     a.Histogram,
+			},
+		}
+		if !a.Passed() {
+			sub.Status = StatusFail
+			tr.Status = StatusFail
+		}
+		tr.SubTests = append(tr.SubTests, sub)
+	}
+
+	r.Tests = append(r.Tests, tr)
+	return tr
+}
+
+// AddSkipped records a test that was not run, with the reason why.
+func (r *Report) AddSkipped(id string, test nist.Test, reason string) *TestReport {
+	tr := newTestReport(id, test)
+	tr.Status = StatusSkipped
+	tr.Message = reason
+	r.Tests = append(r.Tests, tr)
+	return tr
+}
+
+// AddError records a test that failed to run. A random excursion test with too few cycles
+// is not applicable rather than erroneous (SP 800-22 section 2.14.4), and is recorded as skipped.
+func (r *Report) AddError(id string, test nist.Test, err error) *TestReport {
+	if errors.Is(err, nist.ErrNotEnoughCycles) {
+		return r.AddSkipped(id, test, err.Error())
+	}
+	tr := newTestReport(id, test)
+	tr.Status = StatusError
+	tr.Message = err.Error()
+	r.Tests = append(r.Tests, tr)
+	return tr
+}
+
+// Counts returns the number of passing and failing sub-tests, and the number
+// of skipped and erroneous tests.
+func (r *Report) Counts() (pass, fail, skipped, errored int) {
+	for _, tr := range r.Tests {
+		switch tr.Status {
+		case StatusSkipped:
+			skipped++
+			continue
+		case StatusError:
+			errored++
+			continue
+		}
+		for _, sub := range tr.SubTests {
+			if sub.Status == StatusPass {
+				pass++
+			} else {
+				fail++
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,165 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+
+	nist "github.com/notJoon/drbg/nist"
+)
+
+// sample builds a report with a passing, a failing, a skipped and an erroneous test.
+func sample() *Report {
+	r := New("data.bin", 1000, 1)
+	r.AddResult("frequency", nist.NewFrequencyTest(), &nist.Result{
+		Name:       "Frequency (Monobit) Test",
+		N:          1000,
+		PValues:    []float64{0.12345678901234566},
+		Statistics: []float64{1.5},
+	})
+	r.AddResult("cumulative-sums", nist.NewCumulativeSumsTest(nist.CusumBoth), &nist.Result{
+		Name:       "Cumulative Sums Test",
+		N:          1000,
+		PValues:    []float64{0.5, 0.001},
+		Labels:     []string{"forward", "reverse"},
+		Statistics: []float64{10, 40},
+	})
+	r.AddSkipped("random-excursions", nist.NewRandomExcursionsTest(), "needs 1000000 bits, got 1000")
+	r.AddError("rank", nist.NewRankTest(), errors.New("not enough bits"))
+	return r
+}
+
+func TestCounts(t *testing.T) {
+	pass, fail, skipped, errored := sample().Counts()
+	if pass != 2 || fail != 1 || skipped != 1 || errored != 1 {
+		t.Errorf("Counts() = %d, %d, %d, %d, want 2, 1, 1, 1", pass, fail, skipped, errored)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, sample()); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if decoded.Schema != SchemaVersion || decoded.Bits != 1000 || len(decoded.Tests) != 4 {
+		t.Fatalf("unexpected report: %+v", decoded)
+	}
+
+	frequency := decoded.Tests[0]
+	if frequency.Status != StatusPass || *frequency.SubTests[0].PValue != 0.12345678901234566 {
+		t.Errorf("frequency = %+v, want a pass with the p-value at full precision", frequency.SubTests[0])
+	}
+	cusum := decoded.Tests[1]
+	if cusum.Status != StatusFail || cusum.SubTests[1].Label != "reverse" || cusum.SubTests[1].Status != StatusFail {
+		t.Errorf("cumulative-sums = %+v, want the reverse sub-test to fail", cusum)
+	}
+	if cusum.Params["mode"] != float64(nist.CusumBoth) {
+		t.Errorf("cumulative-sums params = %v", cusum.Params)
+	}
+	if skipped := decoded.Tests[2]; skipped.Status != StatusSkipped || skipped.Message == "" || skipped.MinLength != 1000000 {
+		t.Errorf("random-excursions = %+v, want a skip with its reason", skipped)
+	}
+	if errored := decoded.Tests[3]; errored.Status != StatusError || errored.Message != "not enough bits" {
+		t.Errorf("rank = %+v, want an error with its message", errored)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, sample()); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	// header, frequency, 2 cumulative sums, skipped, error
+	if len(rows) != 6 {
+		t.Fatalf("got %d rows, want 6", len(rows))
+	}
+	column := func(name string) int {
+		for i, c := range rows[0] {
+			if c == name {
+				return i
+			}
+		}
+		t.Fatalf("missing column %q", name)
+		return -1
+	}
+
+	if got := rows[1][column("p_value")]; got != "0.12345678901234566" {
+		t.Errorf("p_value = %s, want full precision", got)
+	}
+	if got := rows[3][column("status")]; got != "fail" {
+		t.Errorf("reverse status = %s, want fail", got)
+	}
+	if got := rows[3][column("params")]; got != "mode=2" {
+		t.Errorf("params = %s, want mode=2", got)
+	}
+	if got := rows[4][column("message")]; !strings.Contains(got, "needs 1000000 bits") {
+		t.Errorf("skip message = %q", got)
+	}
+	if got := rows[5][column("status")]; got != "error" {
+		t.Errorf("error status = %s, want error", got)
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, sample()); err != nil {
+		t.Fatalf("WriteJUnit() error = %v", err)
+	}
+
+	var doc junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid XML: %v", err)
+	}
+	if doc.Tests != 5 || doc.Failures != 1 || doc.Errors != 1 || doc.Skipped != 1 {
+		t.Errorf("totals = %d tests, %d failures, %d errors, %d skipped, want 5, 1, 1, 1",
+			doc.Tests, doc.Failures, doc.Errors, doc.Skipped)
+	}
+
+	cases := doc.Suites[0].Cases
+	if cases[0].Name != "Frequency (Monobit) Test" || cases[0].ClassName != "frequency" || cases[0].Failure != nil {
+		t.Errorf("frequency testcase = %+v", cases[0])
+	}
+	if f := cases[2].Failure; cases[2].Name != "Cumulative Sums Test (reverse)" || f == nil || f.Message != "p-value 0.001000 < 0.01" {
+		t.Errorf("reverse testcase = %+v, want a failure with its p-value", cases[2])
+	}
+	if s := cases[3].Skipped; s == nil || !strings.Contains(s.Message, "needs 1000000 bits") {
+		t.Errorf("random-excursions testcase = %+v, want a skip with its reason", cases[3])
+	}
+	if e := cases[4].Error; e == nil || e.Message != "not enough bits" {
+		t.Errorf("rank testcase = %+v, want an error", cases[4])
+	}
+}
+
+func TestJUnitAssessmentFailure(t *testing.T) {
+	pValues := make([][]float64, 100)
+	for i := range pValues {
+		pValues[i] = []float64{0.005}
+	}
+	r := New("data.bin", 1000, len(pValues))
+	r.AddAssessments("frequency", nist.NewFrequencyTest(), nist.AssessResults("Frequency (Monobit) Test", results("Frequency (Monobit) Test", nil, pValues...)))
+
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, r); err != nil {
+		t.Fatalf("WriteJUnit() error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"proportion 0/100 = 0.0000 outside [0.9602, 1.0198]", "uniformity P-value_T 0.000000 &lt; 0.0001"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
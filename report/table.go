@@ -0,0 +1,101 @@
+package report
+
+import (
+	"fmt"
+	"io"
+
+	nist "github.com/notJoon/drbg/nist"
+
+	"github.com/jedib0t/go-pretty/table"
+)
+
+// WriteTable renders the report as a human readable table. P-values are rounded
+// to two decimals; use WriteJSON or WriteCSV for the values at full precision.
+func WriteTable(w io.Writer, r *Report) error {
+	t := table.NewWriter()
+	t.SetOutputMirror(w)
+
+	assessed := r.assessed()
+	if assessed {
+		t.AppendHeader(table.Row{"NIST Statistical Test Suite", "Sequences", "Proportion", "P-value_T", "Result"})
+	} else {
+		t.AppendHeader(table.Row{"NIST Statistical Test Suite", "p-value", "Result"})
+	}
+
+	for _, tr := range r.Tests {
+		switch tr.Status {
+		case StatusSkipped:
+			appendRow(t, assessed, r, tr.Name, "-", "-", fmt.Sprintf("Skipped (%s)", tr.Message))
+			continue
+		case StatusError:
+			appendRow(t, assessed, r, tr.Name, "-", "-", fmt.Sprintf("Error (%s)", tr.Message))
+			continue
+		}
+
+		for _, sub := range tr.SubTests {
+			testName := tr.Name
+			if sub.Label != tr.Name {
+				testName = fmt.Sprintf("%s (%s)", tr.Name, sub.Label)
+			}
+			result := "Pass"
+			if sub.Status != StatusPass {
+				result = "Fail"
+			}
+
+			if a := sub.Assessment; a != nil {
+				uniformity := "-"
+				if a.Sequences >= nist.MinUniformitySequences {
+					uniformity = fmt.Sprintf("%.6f", a.Uniformity)
+				}
+				proportion := fmt.Sprintf("%d/%d (min %.4f)", a.Passes, a.Sequences, a.ProportionMin)
+				t.AppendRow(table.Row{testName, a.Sequences, proportion, uniformity, result})
+			} else {
+				t.AppendRow(table.Row{testName, fmt.Sprintf("%.2f", *sub.PValue), result})
+			}
+		}
+	}
+
+	pass, fail, skipped, errored := r.Counts()
+	footer := func(label string, value int) {
+		if assessed {
+			t.AppendFooter(table.Row{"", "", "", label, value})
+		} else {
+			t.AppendFooter(table.Row{"", label, value})
+		}
+	}
+	footer("Total Tests", pass+fail)
+	footer("Pass", pass)
+	footer("Fail", fail)
+	if skipped > 0 {
+		footer("Skipped", skipped)
+	}
+	if errored > 0 {
+		footer("Errors", errored)
+	}
+	t.Render()
+	return nil
+}
+
+// appendRow appends a row without statistics, in the layout of the table.
+func appendRow(t table.Writer, assessed bool, r *Report, name, proportion, uniformity, result string) {
+	if assessed {
+		t.AppendRow(table.Row{name, r.Sequences, proportion, uniformity, result})
+	} else {
+		t.AppendRow(table.Row{name, "-", result})
+	}
+}
+
+// assessed reports whether the report holds the assessment of many sequences.
+func (r *Report) assessed() bool {
+	if r.Sequences > 1 {
+		return true
+	}
+	for _, tr := range r.Tests {
+		for _, sub := range tr.SubTests {
+			if sub.Assessment != nil {
+				return true
+			}
+		}
+	}
+	return false
+}